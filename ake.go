@@ -9,7 +9,7 @@ import (
 )
 
 type ake struct {
-	secretExponent   *big.Int
+	secretExponent   *secretInt
 	ourPublicValue   *big.Int
 	theirPublicValue *big.Int
 
@@ -44,16 +44,19 @@ func (c *Conversation) calcAKEKeys(s *big.Int) {
 }
 
 func (c *Conversation) setSecretExponent(val *big.Int) {
-	c.ake.secretExponent = new(big.Int).Set(val)
-	c.ake.ourPublicValue = modExp(g1, val)
+	c.ake.secretExponent.destroy()
+	c.ake.secretExponent = newSecretInt(val)
+	c.ake.ourPublicValue = secretModExp(g1, val)
 }
 
 func (c *Conversation) calcDHSharedSecret() *big.Int {
-	return modExp(c.ake.theirPublicValue, c.ake.secretExponent)
+	x := c.ake.secretExponent.bigInt()
+	defer wipeBigInt(x)
+	return secretModExp(c.ake.theirPublicValue, x)
 }
 
 func (c *Conversation) generateEncryptedSignature(key *akeKeys) ([]byte, error) {
-	verifyData := appendAll(c.ake.ourPublicValue, c.ake.theirPublicValue, &c.ourKey.PublicKey, c.ake.keys.ourKeyID)
+	verifyData := appendAll(c.ake.ourPublicValue, c.ake.theirPublicValue, c.ourPublicKey(), c.ake.keys.ourKeyID)
 
 	mb := sumHMAC(key.m1[:], verifyData)
 	xb, err := c.calcXb(key, mb)
@@ -69,10 +72,11 @@ func appendAll(one, two *big.Int, publicKey *PublicKey, keyID uint32) []byte {
 }
 
 func (c *Conversation) calcXb(key *akeKeys, mb []byte) ([]byte, error) {
-	xb := c.ourKey.PublicKey.serialize()
+	signer := c.ourSigner()
+	xb := signer.Public().serialize()
 	xb = appendWord(xb, c.ake.keys.ourKeyID)
 
-	sigb, err := c.ourKey.Sign(c.rand(), mb)
+	sigb, err := signer.Sign(c.signingRand(), mb)
 	if err == io.ErrUnexpectedEOF {
 		return nil, errShortRandomRead
 	}
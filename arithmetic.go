@@ -0,0 +1,54 @@
+package otr3
+
+import (
+	"math/big"
+	"sync"
+)
+
+// ArithmeticBackend computes the modular exponentiations with secret exponents in the 1536-bit group used for DH and SMP.
+// Exponentiations with public exponents, such as the ones that verify SMP proofs, always use math/big
+type ArithmeticBackend interface {
+	// ModExp returns base^exponent mod p, where p is the OTR group prime
+	ModExp(base, exponent *big.Int) *big.Int
+}
+
+// BigIntBackend uses math/big for all operations. It is fast, but its running time depends on the exponent
+type BigIntBackend struct{}
+
+// ModExp implements ArithmeticBackend
+func (BigIntBackend) ModExp(base, exponent *big.Int) *big.Int {
+	return new(big.Int).Exp(base, exponent, p)
+}
+
+// ConstantTimeBackend uses Montgomery multiplication with a fixed window, so the running time doesn't depend
+// on the values of the exponent or the base. It is slower than BigIntBackend
+type ConstantTimeBackend struct{}
+
+var otrGroupMontgomery *montgomeryModulus
+
+// ModExp implements ArithmeticBackend. Negative exponents and exponents larger than the group fall back to math/big
+func (ConstantTimeBackend) ModExp(base, exponent *big.Int) *big.Int {
+	if exponent.Sign() < 0 || exponent.BitLen() > otrGroupMontgomery.bits {
+		return new(big.Int).Exp(base, exponent, p)
+	}
+	return otrGroupMontgomery.exp(base, exponent)
+}
+
+var (
+	arithmetic     ArithmeticBackend = ConstantTimeBackend{}
+	arithmeticLock sync.RWMutex
+)
+
+// SetArithmeticBackend changes the backend used for exponentiations with secret exponents by all conversations.
+// It is safe to call while conversations are in use. The default is ConstantTimeBackend
+func SetArithmeticBackend(b ArithmeticBackend) {
+	arithmeticLock.Lock()
+	defer arithmeticLock.Unlock()
+	arithmetic = b
+}
+
+func currentArithmeticBackend() ArithmeticBackend {
+	arithmeticLock.RLock()
+	defer arithmeticLock.RUnlock()
+	return arithmetic
+}
@@ -0,0 +1,129 @@
+package otr3
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func Test_ConstantTimeBackend_givesTheSameResultsAsMathBig(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		base, _ := rand.Int(rand.Reader, p)
+		exponent, _ := rand.Int(rand.Reader, q)
+
+		assertEquals(t, ConstantTimeBackend{}.ModExp(base, exponent).Cmp(BigIntBackend{}.ModExp(base, exponent)), 0)
+	}
+}
+
+func Test_ConstantTimeBackend_handlesEdgeCases(t *testing.T) {
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		pMinusTwo,
+		sub(p, big.NewInt(1)),
+		p,
+		new(big.Int).Lsh(big.NewInt(1), 1535),
+	}
+
+	for _, base := range values {
+		for _, exponent := range values {
+			assertEquals(t, ConstantTimeBackend{}.ModExp(base, exponent).Cmp(BigIntBackend{}.ModExp(base, exponent)), 0)
+		}
+	}
+}
+
+func Test_ConstantTimeBackend_fallsBackForExponentsOutsideTheGroup(t *testing.T) {
+	large := new(big.Int).Lsh(big.NewInt(3), 1600)
+	assertEquals(t, ConstantTimeBackend{}.ModExp(g1, large).Cmp(BigIntBackend{}.ModExp(g1, large)), 0)
+
+	negative := big.NewInt(-3)
+	assertEquals(t, ConstantTimeBackend{}.ModExp(g1, negative).Cmp(BigIntBackend{}.ModExp(g1, negative)), 0)
+}
+
+func Test_SetArithmeticBackend_changesTheBackendUsedBySecretModExp(t *testing.T) {
+	defer SetArithmeticBackend(currentArithmeticBackend())
+
+	var called bool
+	SetArithmeticBackend(dynamicArithmeticBackend{func(base, exponent *big.Int) *big.Int {
+		called = true
+		return BigIntBackend{}.ModExp(base, exponent)
+	}})
+
+	secretModExp(g1, big.NewInt(3))
+	assertEquals(t, called, true)
+}
+
+func Test_verifySMP1_doesNotUseTheArithmeticBackendForPublicExponents(t *testing.T) {
+	defer SetArithmeticBackend(currentArithmeticBackend())
+
+	var called bool
+	SetArithmeticBackend(dynamicArithmeticBackend{func(base, exponent *big.Int) *big.Int {
+		called = true
+		return BigIntBackend{}.ModExp(base, exponent)
+	}})
+
+	c := newConversation(otrV3{}, fixtureRand())
+	assertNil(t, c.verifySMP1(fixtureMessage1()))
+	assertEquals(t, called, false)
+}
+
+func Test_SetArithmeticBackend_canBeCalledWhileExponentiating(t *testing.T) {
+	defer SetArithmeticBackend(currentArithmeticBackend())
+
+	exponent := big.NewInt(0xABCDEF)
+	expected := BigIntBackend{}.ModExp(g1, exponent)
+
+	results := make(chan *big.Int, 10)
+	go func() {
+		for i := 0; i < 10; i++ {
+			results <- secretModExp(g1, exponent)
+		}
+		close(results)
+	}()
+
+	for i := 0; i < 10; i++ {
+		if i%2 == 0 {
+			SetArithmeticBackend(BigIntBackend{})
+		} else {
+			SetArithmeticBackend(ConstantTimeBackend{})
+		}
+	}
+
+	for result := range results {
+		assertEquals(t, result.Cmp(expected), 0)
+	}
+}
+
+type dynamicArithmeticBackend struct {
+	f func(base, exponent *big.Int) *big.Int
+}
+
+func (d dynamicArithmeticBackend) ModExp(base, exponent *big.Int) *big.Int {
+	return d.f(base, exponent)
+}
+
+func benchmarkModExp(b *testing.B, backend ArithmeticBackend, exponentBits int) {
+	base, _ := rand.Int(rand.Reader, p)
+	exponent, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(exponentBits)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		backend.ModExp(base, exponent)
+	}
+}
+
+func Benchmark_BigIntBackend_DHExponent(b *testing.B) {
+	benchmarkModExp(b, BigIntBackend{}, 320)
+}
+
+func Benchmark_ConstantTimeBackend_DHExponent(b *testing.B) {
+	benchmarkModExp(b, ConstantTimeBackend{}, 320)
+}
+
+func Benchmark_BigIntBackend_SMPExponent(b *testing.B) {
+	benchmarkModExp(b, BigIntBackend{}, 1535)
+}
+
+func Benchmark_ConstantTimeBackend_SMPExponent(b *testing.B) {
+	benchmarkModExp(b, ConstantTimeBackend{}, 1535)
+}
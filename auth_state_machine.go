@@ -18,10 +18,14 @@ func (c *Conversation) akeHasFinished() error {
 
 	previousMsgState := c.msgState
 	c.msgState = encrypted
+	c.incrementMetric(MetricAKECompleted)
+	c.log(LogLevelInfo, "AKE finished", LogField{"refreshed", previousMsgState == encrypted}, LogField{"ourKeyID", c.keys.ourKeyID}, LogField{"theirKeyID", c.keys.theirKeyID})
+	defer c.signalTrustLevel()
+	defer c.signalSecurityEventIf(c.checkForKeyChange(previousMsgState == encrypted), KeyChangedMidSession)
 	defer c.signalSecurityEventIf(previousMsgState != encrypted, GoneSecure)
 	defer c.signalSecurityEventIf(previousMsgState == encrypted, StillSecure)
 
-	if c.ourKey.PublicKey == *c.theirKey {
+	if *c.ourPublicKey() == *c.theirKey {
 		c.messageEvent(MessageEventMessageReflected)
 	}
 
@@ -33,6 +37,7 @@ func (c *Conversation) processAKE(msgType byte, msg []byte) (toSend []messageWit
 
 	var toSendSingle messageWithHeader
 	var toSendExtra messageWithHeader
+	previousState := c.ake.state
 
 	switch msgType {
 	case msgTypeDHCommit:
@@ -49,9 +54,19 @@ func (c *Conversation) processAKE(msgType byte, msg []byte) (toSend []messageWit
 		err = newOtrErrorf("unknown message type 0x%X", msgType)
 	}
 	toSend = compactMessagesWithHeader(toSendSingle, toSendExtra)
+	c.logAKEMessage(msgType, previousState, err)
 	return
 }
 
+func (c *Conversation) logAKEMessage(msgType byte, previousState authState, err error) {
+	if err != nil {
+		c.log(LogLevelWarn, "failed to process AKE message", LogField{"type", messageTypeName(msgType)}, LogField{"state", previousState.identityString()}, LogField{"error", err})
+		return
+	}
+
+	c.log(LogLevelDebug, "processed AKE message", LogField{"type", messageTypeName(msgType)}, LogField{"from", previousState.identityString()}, LogField{"to", c.ake.state.identityString()})
+}
+
 type authStateBase struct{}
 type authStateNone struct{ authStateBase }
 type authStateAwaitingDHKey struct{ authStateBase }
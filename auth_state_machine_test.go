@@ -26,7 +26,7 @@ func Test_receiveDHCommit_AtAuthStateNoneStoresGyAndY(t *testing.T) {
 	authStateNone{}.receiveDHCommitMessage(c, fixtureDHCommitMsg())
 
 	assertDeepEquals(t, c.ake.ourPublicValue, fixedGY())
-	assertDeepEquals(t, c.ake.secretExponent, fixedY())
+	assertDeepEquals(t, c.ake.secretExponent.bigInt(), fixedY())
 }
 
 func Test_receiveDHCommit_AtAuthStateNoneStoresEncryptedGxAndHashedGx(t *testing.T) {
@@ -123,7 +123,7 @@ func Test_receiveDHCommit_AtAwaitingDHKeyForgetOurGxAndSendDHKeyMsgAndGoToAwaiti
 	assertEquals(t, state, authStateAwaitingRevealSig{})
 	assertEquals(t, dhMsgType(newMsg), msgTypeDHKey)
 	assertDeepEquals(t, c.ake.ourPublicValue, fixedGY())
-	assertDeepEquals(t, c.ake.secretExponent, fixedY())
+	assertDeepEquals(t, c.ake.secretExponent.bigInt(), fixedY())
 }
 
 func Test_receiveDHKey_AtAuthStateNoneOrAuthStateAwaitingRevealSigIgnoreIt(t *testing.T) {
@@ -187,7 +187,7 @@ func Test_receiveDHKey_AtAwaitingDHKey_storesOursAndTheirDHKeys(t *testing.T) {
 	assertEquals(t, err, nil)
 	assertDeepEquals(t, c.ake.keys.theirCurrentDHPubKey, fixedGY())
 	assertDeepEquals(t, c.ake.keys.ourCurrentDHKeys.pub, fixedGX())
-	assertDeepEquals(t, c.ake.keys.ourCurrentDHKeys.priv, fixedX())
+	assertDeepEquals(t, c.ake.keys.ourCurrentDHKeys.priv.bigInt(), fixedX())
 
 	assertEquals(t, c.ake.keys.ourKeyID, uint32(1))
 	assertEquals(t, c.ake.keys.theirKeyID, uint32(0))
@@ -201,7 +201,7 @@ func Test_receiveDHKey_AtAuthAwaitingSigIfReceivesSameDHKeyMsgRetransmitRevealSi
 
 	c := newConversation(otrV3{}, fixtureRand())
 	c.initAKE()
-	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent)
+	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent.bigInt())
 	c.ourKey = bobPrivateKey
 
 	assertDeepEquals(t, c.ake.theirPublicValue, nilB)
@@ -259,7 +259,7 @@ func Test_receiveRevealSig_AtAwaitingRevealSig_savesAKEKeysToConversationAndGene
 	assertDeepEquals(t, c.keys.theirCurrentDHPubKey, fixedGX())
 	assertNil(t, c.keys.theirPreviousDHPubKey)
 	assertDeepEquals(t, c.keys.ourPreviousDHKeys.pub, fixedGY())
-	assertDeepEquals(t, c.keys.ourPreviousDHKeys.priv, fixedY())
+	assertDeepEquals(t, c.keys.ourPreviousDHKeys.priv.bigInt(), fixedY())
 
 	//should wipe
 	assertDeepEquals(t, c.ake, &ake{state: c.ake.state})
@@ -449,7 +449,7 @@ func Test_authStateAwaitingDHKey_receiveDHKeyMessage_returnsErrorIfprocessDHKeyR
 
 	c := newConversation(otrV3{}, fixtureRand())
 	c.initAKE()
-	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent)
+	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent.bigInt())
 	c.ourKey = bobPrivateKey
 
 	_, _, err := authStateAwaitingDHKey{}.receiveDHKeyMessage(c, []byte{0x00, 0x02})
@@ -463,7 +463,7 @@ func Test_authStateAwaitingDHKey_receiveDHKeyMessage_returnsErrorIfrevealSigMess
 
 	c := newConversation(otrV3{}, fixedRand([]string{"ABCD"}))
 	c.initAKE()
-	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent)
+	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent.bigInt())
 	c.ourKey = bobPrivateKey
 
 	sameDHKeyMsg := fixtureDHKeyMsgBody(otrV3{})
@@ -478,7 +478,7 @@ func Test_authStateAwaitingSig_receiveDHKeyMessage_returnsErrorIfprocessDHKeyRet
 
 	c := newConversation(otrV3{}, fixtureRand())
 	c.initAKE()
-	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent)
+	c.setSecretExponent(ourDHCommitAKE.ake.secretExponent.bigInt())
 	c.ourKey = bobPrivateKey
 
 	_, _, err := authStateAwaitingSig{}.receiveDHKeyMessage(c, []byte{0x01, 0x02})
@@ -654,7 +654,7 @@ func Test_akeHasFinished_wipesAKEKeys(t *testing.T) {
 	}
 
 	c.ake = &ake{
-		secretExponent:   big.NewInt(1),
+		secretExponent:   newSecretInt(big.NewInt(1)),
 		ourPublicValue:   big.NewInt(2),
 		theirPublicValue: big.NewInt(2),
 		revealKey:        revKey,
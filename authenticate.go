@@ -9,9 +9,13 @@ func (c *Conversation) StartAuthenticate(question string, mutualSecret []byte) (
 	tlvs, err := c.smp.state.startAuthenticate(c, question, mutualSecret)
 
 	if err != nil {
+		c.log(LogLevelWarn, "failed to start SMP", LogField{"error", err})
 		return nil, err
 	}
 
+	c.log(LogLevelInfo, "starting SMP", LogField{"withQuestion", question != ""})
+	c.updateSMPActivity()
+
 	msgs, _, err := c.createSerializedDataMessage(nil, messageFlagIgnoreUnreadable, tlvs)
 	return msgs, err
 }
@@ -23,6 +27,7 @@ func (c *Conversation) ProvideAuthenticationSecret(mutualSecret []byte) ([]Valid
 	if err != nil {
 		return nil, err
 	}
+	c.updateSMPActivity()
 
 	msgs, _, err := c.createSerializedDataMessage(nil, messageFlagIgnoreUnreadable, []tlv{*t})
 	return msgs, err
@@ -31,7 +31,7 @@ func Test_StartAuthenticate_generatesAnSMPSecretFromTheSharedSecret(t *testing.T
 
 	_, e := c.StartAuthenticate("", []byte("hello world"))
 	assertEquals(t, e, nil)
-	assertDeepEquals(t, c.smp.secret, bnFromHex("3D7264BD983B8CA53CB365444844816F7D2453580B552EEE45CD09CA13614A5"))
+	assertDeepEquals(t, c.smp.secret.bigInt(), bnFromHex("3D7264BD983B8CA53CB365444844816F7D2453580B552EEE45CD09CA13614A5"))
 }
 
 func Test_StartAuthenticate_generatesAndReturnsTheFirstSMPMessageToSend(t *testing.T) {
@@ -119,7 +119,7 @@ func Test_ProvideAuthenticationSecret_generatesAnSMPSecretFromTheSharedSecret(t
 
 	_, e := c.ProvideAuthenticationSecret([]byte("hello world"))
 	assertEquals(t, e, nil)
-	assertDeepEquals(t, c.smp.secret, bnFromHex("3D7264BD983B8CA53CB365444844816F7D2453580B552EEE45CD09CA13614A5"))
+	assertDeepEquals(t, c.smp.secret.bigInt(), bnFromHex("3D7264BD983B8CA53CB365444844816F7D2453580B552EEE45CD09CA13614A5"))
 }
 
 func Test_ProvideAuthenticationSecret_failsAndAbortsIfWeAreNotWaitingForASecret(t *testing.T) {
@@ -2,12 +2,9 @@ package otr3
 
 import "math/big"
 
-func modExp(g, x *big.Int) *big.Int {
-	return new(big.Int).Exp(g, x, p)
-}
-
-func modInverse(g, x *big.Int) *big.Int {
-	return new(big.Int).ModInverse(g, x)
+// secretModExp returns g^x mod p using the arithmetic backend. Only use it when x is secret
+func secretModExp(g, x *big.Int) *big.Int {
+	return currentArithmeticBackend().ModExp(g, x)
 }
 
 func mul(l, r *big.Int) *big.Int {
@@ -18,23 +15,6 @@ func sub(l, r *big.Int) *big.Int {
 	return new(big.Int).Sub(l, r)
 }
 
-func mulMod(l, r, m *big.Int) *big.Int {
-	res := mul(l, r)
-	res.Mod(res, m)
-	return res
-}
-
-// Fast division over a modular field, without using division
-func divMod(l, r, m *big.Int) *big.Int {
-	return mulMod(l, modInverse(r, m), m)
-}
-
-func subMod(l, r, m *big.Int) *big.Int {
-	res := sub(l, r)
-	res.Mod(res, m)
-	return res
-}
-
 func mod(l, m *big.Int) *big.Int {
 	return new(big.Int).Mod(l, m)
 }
@@ -0,0 +1,10 @@
+package otr3
+
+import "time"
+
+func (c *Conversation) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
@@ -0,0 +1,259 @@
+// Command otrchat is a minimal two-party chat that runs an OTR conversation over a local socket.
+// It is meant to reproduce protocol issues by hand, so it prints every event of the conversation.
+//
+// Start one side listening and connect the other side to it:
+//
+//	otrchat -listen 127.0.0.1:5222
+//	otrchat -connect 127.0.0.1:5222
+//
+// or with unix sockets:
+//
+//	otrchat -network unix -listen /tmp/otrchat.sock
+//	otrchat -network unix -connect /tmp/otrchat.sock
+//
+// Without -keys a new key is generated for the session. Lines typed are sent to the peer, except for these commands:
+//
+//	/otr start               start an OTR conversation
+//	/otr end                 end the OTR conversation
+//	/smp [question] secret   start SMP, or answer it when the peer started it
+//	/fragsize n              fragment messages longer than n bytes, 0 disables fragmentation
+//	/status                  print the status of the conversation
+//	/quit                    exit
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/twstrike/otr3"
+)
+
+var errQuit = errors.New("quit")
+
+func main() {
+	network := flag.String("network", "tcp", "network to use: tcp or unix")
+	listen := flag.String("listen", "", "address to wait for the peer on")
+	connect := flag.String("connect", "", "address of the peer to connect to")
+	keys := flag.String("keys", "", "libotr private key file to use, instead of a new key")
+	account := flag.String("account", "", "account in the key file to use, if it has more than one")
+	flag.Parse()
+
+	if (*listen == "") == (*connect == "") {
+		fmt.Fprintln(os.Stderr, "otrchat: exactly one of -listen and -connect is needed")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	key, err := loadKey(*keys, *account)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otrchat: %s\n", err)
+		os.Exit(1)
+	}
+
+	conn, err := dial(*network, *listen, *connect)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otrchat: %s\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	if err := newChat(key, conn, os.Stdout).run(os.Stdin, conn); err != nil {
+		fmt.Fprintf(os.Stderr, "otrchat: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func loadKey(file, account string) (*otr3.PrivateKey, error) {
+	if file == "" {
+		key := &otr3.PrivateKey{}
+		return key, key.Generate(rand.Reader)
+	}
+
+	acs, err := otr3.ImportKeysFromFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range acs {
+		if account == "" || a.Name() == account {
+			return a.Key(), nil
+		}
+	}
+	return nil, fmt.Errorf("no account %q in %s", account, file)
+}
+
+func dial(network, listen, connect string) (net.Conn, error) {
+	if connect != "" {
+		return net.Dial(network, connect)
+	}
+
+	l, err := net.Listen(network, listen)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	fmt.Printf("waiting for the peer on %s\n", l.Addr())
+	return l.Accept()
+}
+
+type chat struct {
+	conv   *otr3.Conversation
+	events *otr3.Subscription
+	peer   io.Writer
+	out    io.Writer
+}
+
+func newChat(key *otr3.PrivateKey, peer, out io.Writer) *chat {
+	c := &otr3.Conversation{}
+	c.Policies.AllowV2()
+	c.Policies.AllowV3()
+	c.SetKeys(key, nil)
+
+	fmt.Fprintf(out, "our fingerprint: %s\n", key.PublicKey.HumanFingerprint())
+
+	return &chat{conv: c, events: c.Subscribe(), peer: peer, out: out}
+}
+
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			if line = strings.TrimRight(line, "\r\n"); line != "" {
+				lines <- line
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return lines
+}
+
+// run handles the lines typed by the user, the lines received from the peer and the events of the conversation,
+// all from the same goroutine, until the user quits or the peer disconnects
+func (c *chat) run(input, peer io.Reader) error {
+	inputLines, peerLines := readLines(input), readLines(peer)
+
+	for {
+		var err error
+		select {
+		case line, ok := <-inputLines:
+			if !ok {
+				return nil
+			}
+			err = c.handleInput(line)
+		case line, ok := <-peerLines:
+			if !ok {
+				fmt.Fprintln(c.out, "peer disconnected")
+				return nil
+			}
+			err = c.handlePeer(line)
+		case e := <-c.events.Events():
+			c.printEvent(e)
+		}
+
+		if err == errQuit {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(c.out, "error: %s\n", err)
+		}
+	}
+}
+
+func (c *chat) send(msgs []otr3.ValidMessage, err error) error {
+	for _, m := range msgs {
+		if _, werr := fmt.Fprintf(c.peer, "%s\n", m); werr != nil {
+			return werr
+		}
+	}
+	return err
+}
+
+func (c *chat) handleInput(line string) error {
+	if !strings.HasPrefix(line, "/") {
+		return c.send(c.conv.Send(otr3.ValidMessage(line)))
+	}
+
+	args := strings.Fields(line)
+	switch {
+	case line == "/quit":
+		return errQuit
+	case line == "/otr start":
+		return c.send([]otr3.ValidMessage{c.conv.QueryMessage()}, nil)
+	case line == "/otr end":
+		return c.send(c.conv.End())
+	case args[0] == "/smp" && len(args) >= 2:
+		secret := []byte(args[len(args)-1])
+		if c.conv.SMPProgress() == otr3.SMPProgressWaitingForSecret {
+			return c.send(c.conv.ProvideAuthenticationSecret(secret))
+		}
+		return c.send(c.conv.StartAuthenticate(strings.Join(args[1:len(args)-1], " "), secret))
+	case args[0] == "/fragsize" && len(args) == 2:
+		size, err := strconv.ParseUint(args[1], 10, 16)
+		if err != nil {
+			return err
+		}
+		c.conv.SetFragmentSize(uint16(size))
+		fmt.Fprintf(c.out, "fragment size set to %d\n", size)
+		return nil
+	case line == "/status":
+		status, _ := json.MarshalIndent(c.conv.Status(), "", "  ")
+		fmt.Fprintf(c.out, "%s\n", status)
+		return nil
+	}
+
+	fmt.Fprintln(c.out, "commands: /otr start, /otr end, /smp [question] secret, /fragsize n, /status, /quit")
+	return nil
+}
+
+func (c *chat) handlePeer(line string) error {
+	plain, toSend, err := c.conv.Receive(otr3.ValidMessage(line))
+	if len(plain) > 0 {
+		marker := " "
+		if c.conv.IsEncrypted() {
+			marker = "*"
+		}
+		fmt.Fprintf(c.out, "%s< %s\n", marker, plain)
+	}
+	return c.send(toSend, err)
+}
+
+func (c *chat) printEvent(e otr3.Event) {
+	switch ev := e.(type) {
+	case otr3.SecurityEventNotification:
+		fmt.Fprintf(c.out, "[security] %s\n", ev.Event)
+		if ev.Event == otr3.GoneSecure {
+			fmt.Fprintf(c.out, "[security] their fingerprint: %s\n", c.conv.GetTheirKey().HumanFingerprint())
+		}
+	case otr3.MessageEventNotification:
+		fmt.Fprintf(c.out, "[message] %s", ev.Event)
+		if ev.Error != nil {
+			fmt.Fprintf(c.out, ": %s", ev.Error)
+		}
+		fmt.Fprintln(c.out)
+	case otr3.SMPEventNotification:
+		fmt.Fprintf(c.out, "[smp] %s (%d%%)", ev.Event, ev.ProgressPercent)
+		if ev.Question != "" {
+			fmt.Fprintf(c.out, " question: %q", ev.Question)
+		}
+		fmt.Fprintln(c.out)
+	case otr3.ErrorMessageNotification:
+		fmt.Fprintf(c.out, "[error message] %s\n", ev.Code)
+	case otr3.KeyChangeNotification:
+		fmt.Fprintf(c.out, "[key change] %s -> %s\n", otr3.FormatFingerprint(ev.OldFingerprint), otr3.FormatFingerprint(ev.NewFingerprint))
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twstrike/otr3"
+)
+
+var testKeys = map[string]*otr3.PrivateKey{}
+
+func testKey(name string) *otr3.PrivateKey {
+	if k, ok := testKeys[name]; ok {
+		return k
+	}
+	k := &otr3.PrivateKey{}
+	k.Generate(rand.Reader)
+	testKeys[name] = k
+	return k
+}
+
+type testPeer struct {
+	*chat
+	wire *bytes.Buffer
+	out  *bytes.Buffer
+}
+
+func newTestPeer(name string) *testPeer {
+	wire, out := &bytes.Buffer{}, &bytes.Buffer{}
+	return &testPeer{newChat(testKey(name), wire, out), wire, out}
+}
+
+// deliver passes all the lines written by the peers to the other side until they are quiet
+func deliver(t *testing.T, a, b *testPeer) {
+	for a.wire.Len() > 0 || b.wire.Len() > 0 {
+		for _, p := range [][2]*testPeer{{a, b}, {b, a}} {
+			lines := strings.Split(strings.TrimRight(p[0].wire.String(), "\n"), "\n")
+			p[0].wire.Reset()
+			for _, l := range lines {
+				if l != "" {
+					p[1].handlePeer(l)
+				}
+			}
+		}
+	}
+}
+
+func waitForEvent(t *testing.T, p *testPeer, matches func(otr3.Event) bool) {
+	for {
+		select {
+		case e := <-p.events.Events():
+			p.printEvent(e)
+			if matches(e) {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected event didn't happen, output:\n%s", p.out)
+		}
+	}
+}
+
+func isSecurityEvent(ev otr3.SecurityEvent) func(otr3.Event) bool {
+	return func(e otr3.Event) bool {
+		n, ok := e.(otr3.SecurityEventNotification)
+		return ok && n.Event == ev
+	}
+}
+
+func isSMPEvent(ev otr3.SMPEvent) func(otr3.Event) bool {
+	return func(e otr3.Event) bool {
+		n, ok := e.(otr3.SMPEventNotification)
+		return ok && n.Event == ev
+	}
+}
+
+func startOTR(t *testing.T) (alice, bob *testPeer) {
+	alice, bob = newTestPeer("alice"), newTestPeer("bob")
+	alice.handleInput("/otr start")
+	deliver(t, alice, bob)
+	waitForEvent(t, alice, isSecurityEvent(otr3.GoneSecure))
+	waitForEvent(t, bob, isSecurityEvent(otr3.GoneSecure))
+	return
+}
+
+func Test_otrStart_establishesAnEncryptedConversation(t *testing.T) {
+	alice, bob := startOTR(t)
+
+	if !alice.conv.IsEncrypted() || !bob.conv.IsEncrypted() {
+		t.Fatal("conversation is not encrypted")
+	}
+	if !strings.Contains(alice.out.String(), "their fingerprint: "+testKey("bob").PublicKey.HumanFingerprint()) {
+		t.Errorf("fingerprint of bob wasn't printed:\n%s", alice.out)
+	}
+
+	alice.handleInput("hello bob")
+	deliver(t, alice, bob)
+
+	if !strings.Contains(bob.out.String(), "*< hello bob\n") {
+		t.Errorf("message wasn't received encrypted:\n%s", bob.out)
+	}
+}
+
+func Test_otrEnd_finishesTheConversation(t *testing.T) {
+	alice, bob := startOTR(t)
+
+	alice.handleInput("/otr end")
+	deliver(t, alice, bob)
+
+	waitForEvent(t, bob, isSecurityEvent(otr3.GoneInsecure))
+}
+
+func Test_smp_authenticatesThePeer(t *testing.T) {
+	alice, bob := startOTR(t)
+
+	alice.handleInput("/smp what is the answer 42")
+	deliver(t, alice, bob)
+	waitForEvent(t, bob, isSMPEvent(otr3.SMPEventAskForAnswer))
+
+	bob.handleInput("/smp 42")
+	deliver(t, alice, bob)
+
+	waitForEvent(t, alice, isSMPEvent(otr3.SMPEventSuccess))
+	waitForEvent(t, bob, isSMPEvent(otr3.SMPEventSuccess))
+	if !strings.Contains(bob.out.String(), `question: "what is the answer"`) {
+		t.Errorf("question wasn't printed:\n%s", bob.out)
+	}
+}
+
+func Test_fragsize_fragmentsTheMessages(t *testing.T) {
+	alice, bob := startOTR(t)
+
+	alice.handleInput("/fragsize 100")
+	alice.handleInput("hello bob")
+
+	if strings.Count(alice.wire.String(), "\n") < 2 {
+		t.Errorf("message wasn't fragmented: %s", alice.wire)
+	}
+
+	deliver(t, alice, bob)
+	if !strings.Contains(bob.out.String(), "*< hello bob\n") {
+		t.Errorf("fragmented message wasn't received:\n%s", bob.out)
+	}
+}
+
+func Test_status_printsTheStatusAsJSON(t *testing.T) {
+	alice := newTestPeer("alice")
+
+	alice.handleInput("/status")
+
+	if !strings.Contains(alice.out.String(), `"msgState": "PLAINTEXT"`) {
+		t.Errorf("unexpected status:\n%s", alice.out)
+	}
+}
+
+func Test_unknownCommands_printTheHelp(t *testing.T) {
+	alice := newTestPeer("alice")
+
+	alice.handleInput("/frobnicate")
+
+	if !strings.Contains(alice.out.String(), "commands: /otr start") {
+		t.Errorf("help wasn't printed:\n%s", alice.out)
+	}
+}
+
+func Test_run_talksToThePeerOverAConnectionUntilQuit(t *testing.T) {
+	aliceConn, bobConn := net.Pipe()
+	aliceIn, aliceInput := io.Pipe()
+	var bobOut bytes.Buffer
+	bob := newChat(testKey("bob"), bobConn, &bobOut)
+	alice := newChat(testKey("alice"), aliceConn, &bytes.Buffer{})
+
+	done := make(chan error)
+	go func() { done <- alice.run(aliceIn, aliceConn) }()
+
+	bobLines := readLines(bobConn)
+	io.WriteString(aliceInput, "hello\n/quit\n")
+
+	line := <-bobLines
+	bob.handlePeer(line)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bobOut.String(), " < hello\n") {
+		t.Errorf("message wasn't received:\n%s", bobOut.String())
+	}
+}
@@ -0,0 +1,305 @@
+// Command otrtool generates and converts OTR private keys, prints their fingerprints and inspects OTR messages.
+//
+// Usage:
+//
+//	otrtool generate -account alice@example.com -protocol prpl-jabber [-out keys.txt]
+//	otrtool accounts [-in keys.txt]
+//	otrtool fingerprint [-in keys.txt] [-binary]
+//	otrtool convert -to binary [-account alice@example.com] [-in keys.txt] [-out key.bin]
+//	otrtool convert -to sexp -account alice@example.com -protocol prpl-jabber [-in key.bin] [-out keys.txt]
+//	otrtool inspect < messages.txt
+//
+// Private key files are in the libotr S-expression format, unless stated otherwise. Binary keys are in the
+// format of PrivateKey.Serialize. Files default to stdin and stdout.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/twstrike/otr3"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"generate", "generate a new private key for an account", generate},
+		{"accounts", "list the accounts in a private key file", accounts},
+		{"fingerprint", "print the fingerprints of private keys", fingerprint},
+		{"convert", "convert private keys between the libotr and the binary format", convert},
+		{"inspect", "decode the OTR messages read from stdin, one per line", inspect},
+	}
+}
+
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			err := c.run(args[1:], stdin, stdout, stderr)
+			if err == errUsage || err == flag.ErrHelp {
+				return 2
+			}
+			if err != nil {
+				fmt.Fprintf(stderr, "otrtool %s: %s\n", c.name, err)
+				return 1
+			}
+			return 0
+		}
+	}
+
+	printUsage(stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: otrtool <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.usage)
+	}
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("otrtool "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func readInput(file string, stdin io.Reader) ([]byte, error) {
+	if file == "" || file == "-" {
+		return ioutil.ReadAll(stdin)
+	}
+	return ioutil.ReadFile(file)
+}
+
+func writeOutput(file string, stdout io.Writer, data []byte) error {
+	if file == "" || file == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+func readAccounts(file string, stdin io.Reader) ([]*otr3.Account, error) {
+	data, err := readInput(file, stdin)
+	if err != nil {
+		return nil, err
+	}
+	return otr3.ImportKeys(bytes.NewReader(data))
+}
+
+func exportAccounts(acs []*otr3.Account) []byte {
+	var b bytes.Buffer
+	otr3.ExportKeys(acs, &b)
+	return b.Bytes()
+}
+
+func generate(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("generate", stderr)
+	account := fs.String("account", "", "name of the account")
+	protocol := fs.String("protocol", "prpl-jabber", "protocol of the account")
+	out := fs.String("out", "", "file to write the key to")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *account == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	key := &otr3.PrivateKey{}
+	if err := key.Generate(rand.Reader); err != nil {
+		return err
+	}
+
+	return writeOutput(*out, stdout, exportAccounts([]*otr3.Account{otr3.NewAccount(*account, *protocol, key)}))
+}
+
+func accounts(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("accounts", stderr)
+	in := fs.String("in", "", "private key file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	acs, err := readAccounts(*in, stdin)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range acs {
+		fmt.Fprintf(stdout, "%s\t%s\n", a.Name(), a.Protocol())
+	}
+	return nil
+}
+
+func fingerprint(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("fingerprint", stderr)
+	in := fs.String("in", "", "private key file")
+	binary := fs.Bool("binary", false, "the key is in the binary format")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *binary {
+		key, err := readBinaryKey(*in, stdin)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, key.PublicKey.HumanFingerprint())
+		return nil
+	}
+
+	acs, err := readAccounts(*in, stdin)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range acs {
+		fmt.Fprintf(stdout, "%s\t%s\t%s\n", a.Name(), a.Protocol(), a.Key().PublicKey.HumanFingerprint())
+	}
+	return nil
+}
+
+func readBinaryKey(file string, stdin io.Reader) (*otr3.PrivateKey, error) {
+	data, err := readInput(file, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		data = decoded
+	}
+
+	key := &otr3.PrivateKey{}
+	if _, ok := key.Parse(data); !ok {
+		return nil, errors.New("couldn't parse binary private key")
+	}
+	return key, nil
+}
+
+func convert(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("convert", stderr)
+	to := fs.String("to", "", "format to convert to: binary or sexp")
+	in := fs.String("in", "", "file to read the key from")
+	out := fs.String("out", "", "file to write the key to")
+	account := fs.String("account", "", "account to convert to binary, or name of the account of the converted key")
+	protocol := fs.String("protocol", "prpl-jabber", "protocol of the account of the converted key")
+	encode := fs.Bool("base64", false, "read and write binary keys as base64")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	switch *to {
+	case "binary":
+		acs, err := readAccounts(*in, stdin)
+		if err != nil {
+			return err
+		}
+
+		a, err := findAccount(acs, *account)
+		if err != nil {
+			return err
+		}
+
+		data := a.Key().Serialize()
+		if *encode {
+			data = []byte(base64.StdEncoding.EncodeToString(data) + "\n")
+		}
+		return writeOutput(*out, stdout, data)
+	case "sexp":
+		if *account == "" {
+			fs.Usage()
+			return errUsage
+		}
+
+		key, err := readBinaryKey(*in, stdin)
+		if err != nil {
+			return err
+		}
+		return writeOutput(*out, stdout, exportAccounts([]*otr3.Account{otr3.NewAccount(*account, *protocol, key)}))
+	}
+
+	fs.Usage()
+	return errUsage
+}
+
+func findAccount(acs []*otr3.Account, name string) (*otr3.Account, error) {
+	if name == "" {
+		if len(acs) != 1 {
+			return nil, errors.New("the file has more than one account, use -account to choose one")
+		}
+		return acs[0], nil
+	}
+
+	for _, a := range acs {
+		if a.Name() == name {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("no account named %q", name)
+}
+
+func inspect(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("inspect", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(stdin)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if line = bytes.TrimRight(line, "\r\n"); len(line) > 0 {
+			info, ierr := otr3.Inspect(line)
+			fmt.Fprint(stdout, info)
+			if ierr != nil {
+				fmt.Fprintf(stdout, "  Error: %s\n", ierr)
+			}
+			fmt.Fprintln(stdout)
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
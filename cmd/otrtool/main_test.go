@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/twstrike/otr3"
+)
+
+const aliceKeys = `(privkeys
+  (account
+    (name "alice@example.com")
+    (protocol prpl-jabber)
+    (private-key
+      (dsa
+        (p #00FC07ABCF0DC916AFF6E9AE47BEF60C7AB9B4D6B2469E436630E36F8A489BE812486A09F30B71224508654940A835301ACC525A4FF133FC152CC53DCC59D65C30A54F1993FE13FE63E5823D4C746DB21B90F9B9C00B49EC7404AB1D929BA7FBA12F2E45C6E0A651689750E8528AB8C031D3561FECEE72EBB4A090D450A9B7A857#)
+        (q #00997BD266EF7B1F60A5C23F3A741F2AEFD07A2081#)
+        (g #535E360E8A95EBA46A4F7DE50AD6E9B2A6DB785A66B64EB9F20338D2A3E8FB0E94725848F1AA6CC567CB83A1CC517EC806F2E92EAE71457E80B2210A189B91250779434B41FC8A8873F6DB94BEA7D177F5D59E7E114EE10A49CFD9CEF88AE43387023B672927BA74B04EB6BBB5E57597766A2F9CE3857D7ACE3E1E3BC1FC6F26#)
+        (y #0AC8670AD767D7A8D9D14CC1AC6744CD7D76F993B77FFD9E39DF01E5A6536EF65E775FCEF2A983E2A19BD6415500F6979715D9FD1257E1FE2B6F5E1E74B333079E7C880D39868462A93454B41877BE62E5EF0A041C2EE9C9E76BD1E12AE25D9628DECB097025DD625EF49C3258A1A3C0FF501E3DC673B76D7BABF349009B6ECF#)
+        (x #14D0345A3562C480A039E3C72764F72D79043216#)
+        )
+      )
+    )
+  )
+`
+
+func runCommand(t *testing.T, stdin string, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func Test_run_printsUsageForUnknownCommands(t *testing.T) {
+	_, stderr, code := runCommand(t, "", "frobnicate")
+
+	if code != 2 || !strings.Contains(stderr, "usage: otrtool") {
+		t.Errorf("unexpected result %d: %s", code, stderr)
+	}
+}
+
+func Test_accounts_listsTheAccountsInTheFile(t *testing.T) {
+	stdout, _, code := runCommand(t, aliceKeys, "accounts")
+
+	if code != 0 || stdout != "alice@example.com\tprpl-jabber\n" {
+		t.Errorf("unexpected result %d: %q", code, stdout)
+	}
+}
+
+func Test_fingerprint_printsTheHumanFingerprintOfEachAccount(t *testing.T) {
+	stdout, _, code := runCommand(t, aliceKeys, "fingerprint")
+
+	fields := strings.Split(strings.TrimSpace(stdout), "\t")
+	if code != 0 || len(fields) != 3 || len(fields[2]) != 44 {
+		t.Errorf("unexpected result %d: %q", code, stdout)
+	}
+}
+
+func Test_convert_roundTripsBetweenTheFormats(t *testing.T) {
+	binary, _, code := runCommand(t, aliceKeys, "convert", "-to", "binary", "-base64")
+	if code != 0 {
+		t.Fatalf("converting to binary failed with %d", code)
+	}
+
+	sexp, _, code := runCommand(t, binary, "convert", "-to", "sexp", "-account", "alice@example.com", "-base64")
+	acs, _ := otr3.ImportKeys(strings.NewReader(aliceKeys))
+	if code != 0 || sexp != string(exportAccounts(acs)) {
+		t.Errorf("unexpected result %d:\n%s", code, sexp)
+	}
+
+	fp, _, _ := runCommand(t, binary, "fingerprint", "-binary")
+	all, _, _ := runCommand(t, aliceKeys, "fingerprint")
+	if !strings.HasSuffix(all, "\t"+fp) {
+		t.Errorf("fingerprints differ: %q and %q", fp, all)
+	}
+}
+
+func Test_convert_requiresAnAccountNameForSexp(t *testing.T) {
+	_, _, code := runCommand(t, "", "convert", "-to", "sexp")
+
+	if code != 2 {
+		t.Errorf("unexpected exit code %d", code)
+	}
+}
+
+func Test_generate_writesANewKeyToAFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "otrtool")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "keys.txt")
+
+	_, _, code := runCommand(t, "", "generate", "-account", "bob@example.com", "-out", file)
+	if code != 0 {
+		t.Fatalf("generate failed with %d", code)
+	}
+
+	stdout, _, code := runCommand(t, "", "accounts", "-in", file)
+	if code != 0 || stdout != "bob@example.com\tprpl-jabber\n" {
+		t.Errorf("unexpected result %d: %q", code, stdout)
+	}
+}
+
+func Test_inspect_describesEachMessage(t *testing.T) {
+	stdout, _, code := runCommand(t, "?OTRv23?\nhello\n\n?OTR:AAMC!!!.\n", "inspect")
+
+	if code != 0 ||
+		!strings.Contains(stdout, "Kind: MessageKindQuery\n") ||
+		!strings.Contains(stdout, "Kind: MessageKindPlaintext\n") ||
+		!strings.Contains(stdout, "  Error: otr: invalid OTR message\n") {
+		t.Errorf("unexpected result %d:\n%s", code, stdout)
+	}
+}
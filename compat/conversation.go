@@ -46,6 +46,18 @@ type Conversation struct {
 	SSID           [8]byte
 	FragmentSize   int
 
+	// The following fields are not part of x/crypto/otr. They are read the first time
+	// the conversation is used, and all default to the behaviour of x/crypto/otr
+
+	// AllowV3 enables OTR version 3, with instance tags, in addition to version 2
+	AllowV3 bool
+	// RequireEncryption refuses to send or accept unencrypted messages
+	RequireEncryption bool
+	// SendWhitespaceTag adds a whitespace tag to plaintext messages, advertising OTR support
+	SendWhitespaceTag bool
+	// WhitespaceStartAKE starts an AKE when a whitespace tagged message is received
+	WhitespaceStartAKE bool
+
 	eventHandler
 	initialized bool
 }
@@ -110,6 +122,18 @@ func (c *Conversation) compatInit() {
 	}
 
 	c.Conversation.Policies.AllowV2()
+	if c.AllowV3 {
+		c.Conversation.Policies.AllowV3()
+	}
+	if c.RequireEncryption {
+		c.Conversation.Policies.RequireEncryption()
+	}
+	if c.SendWhitespaceTag {
+		c.Conversation.Policies.SendWhitespaceTag()
+	}
+	if c.WhitespaceStartAKE {
+		c.Conversation.Policies.WhitespaceStartAKE()
+	}
 	c.SetSMPEventHandler(&c.eventHandler)
 	c.SetErrorMessageHandler(&c.eventHandler)
 	c.SetMessageEventHandler(&c.eventHandler)
@@ -123,6 +147,13 @@ func (c *Conversation) compatInit() {
 	c.initialized = true
 }
 
+// QueryMessage returns the message to send to a peer to start an OTR conversation, advertising
+// the versions allowed for this conversation. Use it instead of the QueryMessage variable when AllowV3 is set
+func (c *Conversation) QueryMessage() otr3.ValidMessage {
+	c.compatInit()
+	return c.Conversation.QueryMessage()
+}
+
 func (c *Conversation) updateValues() {
 	if c.Conversation.GetTheirKey() != nil {
 		c.TheirPublicKey.PublicKey = *c.Conversation.GetTheirKey()
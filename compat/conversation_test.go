@@ -0,0 +1,146 @@
+package compat
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func newTestConversation(keyHex string) *Conversation {
+	key, _ := hex.DecodeString(keyHex)
+	c := &Conversation{PrivateKey: new(PrivateKey)}
+	c.PrivateKey.Parse(key)
+	return c
+}
+
+// exchange delivers the messages back and forth until no more are generated, returning everything bob received in the clear
+func exchange(t *testing.T, alice, bob *Conversation, msgs [][]byte) (received [][]byte) {
+	from, to := alice, bob
+	for len(msgs) > 0 {
+		var replies [][]byte
+		for _, m := range msgs {
+			out, _, _, toSend, err := to.Receive(m)
+			if err != nil {
+				t.Fatalf("Receive returned an error: %s", err)
+			}
+			if len(out) > 0 && to == bob {
+				received = append(received, out)
+			}
+			replies = append(replies, toSend...)
+		}
+		msgs = replies
+		from, to = to, from
+	}
+	return
+}
+
+func TestQueryMessageDefaultsToV2(t *testing.T) {
+	c := newTestConversation(alicePrivateKeyHex)
+
+	if q := string(c.QueryMessage()); q != QueryMessage {
+		t.Errorf("QueryMessage() = %q, want %q", q, QueryMessage)
+	}
+}
+
+func TestQueryMessageWithV3(t *testing.T) {
+	c := newTestConversation(alicePrivateKeyHex)
+	c.AllowV3 = true
+
+	if q := string(c.QueryMessage()); q != "?OTRv23?" {
+		t.Errorf("QueryMessage() = %q, want ?OTRv23?", q)
+	}
+}
+
+func TestDefaultConversationUsesV2(t *testing.T) {
+	alice := newTestConversation(alicePrivateKeyHex)
+	bob := newTestConversation(bobPrivateKeyHex)
+	bob.AllowV3 = true
+
+	_, _, _, toSend, _ := newTestConversation(alicePrivateKeyHex).Receive([]byte("?OTRv23?"))
+	if len(toSend) != 1 || !bytes.HasPrefix(toSend[0], []byte("?OTR:AAIC")) {
+		t.Errorf("expected a v2 DH commit, got %q", toSend)
+	}
+
+	exchange(t, alice, bob, [][]byte{alice.QueryMessage()})
+	if !alice.IsEncrypted() || !bob.IsEncrypted() {
+		t.Fatalf("expected the conversation to be encrypted")
+	}
+
+	msgs, _ := alice.Send([]byte("hello"))
+	if len(msgs) != 1 || !bytes.HasPrefix(msgs[0], []byte("?OTR:AAID")) {
+		t.Errorf("expected a v2 data message, got %q", msgs)
+	}
+}
+
+func TestConversationWithV3(t *testing.T) {
+	alice := newTestConversation(alicePrivateKeyHex)
+	bob := newTestConversation(bobPrivateKeyHex)
+	alice.AllowV3 = true
+	bob.AllowV3 = true
+
+	exchange(t, alice, bob, [][]byte{alice.QueryMessage()})
+	if !alice.IsEncrypted() || !bob.IsEncrypted() {
+		t.Fatalf("expected the conversation to be encrypted")
+	}
+	if alice.SSID != bob.SSID {
+		t.Errorf("Session identifiers don't match. Alice has %x, Bob has %x", alice.SSID[:], bob.SSID[:])
+	}
+
+	msgs, _ := alice.Send([]byte("hello"))
+	if len(msgs) != 1 || !bytes.HasPrefix(msgs[0], []byte("?OTR:AAMD")) {
+		t.Errorf("expected a v3 data message, got %q", msgs)
+	}
+
+	received := exchange(t, alice, bob, msgs)
+	if len(received) != 1 || string(received[0]) != "hello" {
+		t.Errorf("unexpected messages received: %q", received)
+	}
+}
+
+func TestRequireEncryptionSendsQueryMessageInstead(t *testing.T) {
+	alice := newTestConversation(alicePrivateKeyHex)
+	bob := newTestConversation(bobPrivateKeyHex)
+	alice.RequireEncryption = true
+
+	msgs, err := alice.Send([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || string(msgs[0]) != QueryMessage {
+		t.Errorf("expected the query message, got %q", msgs)
+	}
+
+	received := exchange(t, alice, bob, msgs)
+	if len(received) != 1 || string(received[0]) != "secret" {
+		t.Errorf("expected the message to be resent encrypted, got %q", received)
+	}
+}
+
+func TestDefaultConversationSendsPlaintextUnchanged(t *testing.T) {
+	alice := newTestConversation(alicePrivateKeyHex)
+
+	msgs, _ := alice.Send([]byte("hi"))
+	if len(msgs) != 1 || string(msgs[0]) != "hi" {
+		t.Errorf("expected the plaintext unchanged, got %q", msgs)
+	}
+}
+
+func TestWhitespaceTagsStartTheAKE(t *testing.T) {
+	alice := newTestConversation(alicePrivateKeyHex)
+	bob := newTestConversation(bobPrivateKeyHex)
+	alice.SendWhitespaceTag = true
+	bob.WhitespaceStartAKE = true
+
+	msgs, _ := alice.Send([]byte("hi"))
+	if len(msgs) != 1 || len(msgs[0]) <= 2 || !bytes.HasPrefix(msgs[0], []byte("hi ")) {
+		t.Errorf("expected a whitespace tagged message, got %q", msgs)
+	}
+
+	received := exchange(t, alice, bob, msgs)
+	if len(received) != 1 || string(received[0]) != "hi" {
+		t.Errorf("expected the message without the tag, got %q", received)
+	}
+	if !alice.IsEncrypted() || !bob.IsEncrypted() {
+		t.Errorf("expected the whitespace tag to start the AKE")
+	}
+}
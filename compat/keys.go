@@ -2,10 +2,14 @@ package compat
 
 import (
 	"io"
+	"math/big"
 
 	"github.com/twstrike/otr3"
 )
 
+// dsaSubgroupBytes is the size of each half of a DSA signature
+const dsaSubgroupBytes = 20
+
 // PublicKey represents an OTR Public Key
 type PublicKey struct {
 	otr3.PublicKey
@@ -17,11 +21,41 @@ type PrivateKey struct {
 	PublicKey
 }
 
+// Parse will parse a Public Key from the given data. It returns nil and not ok if the data is malformed or not for a DSA key
+func (pub *PublicKey) Parse(in []byte) ([]byte, bool) {
+	rest, ok := pub.PublicKey.Parse(in)
+	if !ok {
+		return nil, false
+	}
+	return rest, true
+}
+
+// Serialize appends the serialization of the public key to in
+func (pub *PublicKey) Serialize(in []byte) []byte {
+	return append(in, pub.PublicKey.Serialize()...)
+}
+
+// Fingerprint will generate a new SHA-1 fingerprint of the serialization of the public key
+func (pub *PublicKey) Fingerprint() []byte {
+	return pub.PublicKey.DefaultFingerprint()
+}
+
+// Verify will verify a signature of a hashed data using dsa Verify. Like x/crypto/otr, and unlike
+// otr3.PublicKey.Verify, the signature must be exactly 40 bytes long, and trailing data is rejected
+func (pub *PublicKey) Verify(hashed, sig []byte) ([]byte, bool) {
+	if len(sig) != 2*dsaSubgroupBytes {
+		return nil, false
+	}
+	return pub.PublicKey.Verify(hashed, sig)
+}
+
 // Generate will generate a new Private Key using the provided randomness
 func (priv *PrivateKey) Generate(rand io.Reader) {
 	if err := priv.PrivateKey.Generate(rand); err != nil {
 		panic(err.Error())
 	}
+
+	priv.PublicKey = PublicKey{priv.PrivateKey.PublicKey}
 }
 
 // Serialize will serialize the private key
@@ -39,9 +73,9 @@ func (priv *PrivateKey) Sign(rand io.Reader, hashed []byte) []byte {
 	return ret
 }
 
-// Fingerprint will generate a new SHA-1 fingerprint of the serialization of the public key
-func (pub *PublicKey) Fingerprint() []byte {
-	return pub.PublicKey.DefaultFingerprint()
+// Verify will verify a signature of a hashed data with the public part of the key
+func (priv *PrivateKey) Verify(hashed, sig []byte) ([]byte, bool) {
+	return priv.PublicKey.Verify(hashed, sig)
 }
 
 // Parse will parse a Private Key from the given data, by first parsing the public key components and then the private key component. It returns not ok for the same reasons as PublicKey.Parse.
@@ -58,3 +92,20 @@ func (priv *PrivateKey) Parse(in []byte) (index []byte, ok bool) {
 
 	return rest, ok
 }
+
+// Import parses the contents of a libotr private key file. The key is left unchanged if
+// any of the parameters is missing or not positive
+func (priv *PrivateKey) Import(in []byte) bool {
+	var k otr3.PrivateKey
+	ok := k.Import(in)
+
+	for _, v := range []*big.Int{k.PrivateKey.P, k.PrivateKey.Q, k.PrivateKey.G, k.PrivateKey.Y, k.PrivateKey.X} {
+		if v == nil || v.Sign() <= 0 {
+			return false
+		}
+	}
+
+	priv.PrivateKey = k
+	priv.PublicKey = PublicKey{priv.PrivateKey.PublicKey}
+	return ok
+}
@@ -0,0 +1,195 @@
+package compat
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+// The vectors in this file were recorded by running the same operations with golang.org/x/crypto/otr
+
+var alicePublicKeyHex = "000000000080c81c2cb2eb729b7e6fd48e975a932c638b3a9055478583afa46755683e30102447f6da2d8bec9f386bbb5da6403b0040fee8650b6ab2d7f32c55ab017ae9b6aec8c324ab5844784e9a80e194830d548fb7f09a0410df2c4d5c8bc2b3e9ad484e65412be689cf0834694e0839fb2954021521ffdffb8f5c32c14dbf2020b3ce7500000014da4591d58def96de61aea7b04a8405fe1609308d000000808ddd5cb0b9d66956e3dea5a915d9aba9d8a6e7053b74dadb2fc52f9fe4e5bcc487d2305485ed95fed026ad93f06ebb8c9e8baf693b7887132c7ffdd3b0f72f4002ff4ed56583ca7c54458f8c068ca3e8a4dfa309d1dd5d34e2a4b68e6f4338835e5e0fb4317c9e4c7e4806dafda3ef459cd563775a586dd91b1319f72621bf3f00000080b8147e74d8c45e6318c37731b8b33b984a795b3653c2cd1d65cc99efe097cb7eb2fa49569bab5aab6e8a1c261a27d0f7840a5e80b317e6683042b59b6dceca2879c6ffc877a465be690c15e4a42f9a7588e79b10faac11b1ce3741fcef7aba8ce05327a2c16d279ee1b3d77eb783fb10e3356caa25635331e26dd42b8396c4d0"
+
+// sha256("hello") and a signature of it by alice's key, made by x/crypto/otr
+var helloHashHex = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+var aliceHelloSignatureHex = "abc0b47d25b02173d2d6d51b77d3f7c6d1c3712f2d4b2dd1e49a3c09df427fea5c7e830286a3027a"
+
+// The API of x/crypto/otr, as used by legacy callers
+var _ interface {
+	Parse([]byte) ([]byte, bool)
+	Serialize([]byte) []byte
+	Fingerprint() []byte
+	Verify(hashed, sig []byte) ([]byte, bool)
+} = &PublicKey{}
+
+var _ interface {
+	Parse([]byte) ([]byte, bool)
+	Serialize([]byte) []byte
+	Fingerprint() []byte
+	Verify(hashed, sig []byte) ([]byte, bool)
+	Sign(rand io.Reader, hashed []byte) []byte
+	Generate(rand io.Reader)
+	Import([]byte) bool
+} = &PrivateKey{}
+
+var _ interface {
+	Receive(in []byte) (out []byte, encrypted bool, change SecurityChange, toSend [][]byte, err error)
+	Send(msg []byte) ([][]byte, error)
+	SMPQuestion() string
+	Authenticate(question string, mutualSecret []byte) (toSend [][]byte, err error)
+	End() (toSend [][]byte)
+	IsEncrypted() bool
+} = &Conversation{}
+
+func hexBytes(s string) []byte {
+	b, _ := hex.DecodeString(s)
+	return b
+}
+
+func concat(bs ...[]byte) []byte {
+	var ret []byte
+	for _, b := range bs {
+		ret = append(ret, b...)
+	}
+	return ret
+}
+
+func TestPublicKeyParseParity(t *testing.T) {
+	pub := hexBytes(alicePublicKeyHex)
+
+	vectors := []struct {
+		name string
+		in   []byte
+		rest []byte
+		ok   bool
+	}{
+		{"exact key", pub, []byte{}, true},
+		{"trailing data", concat(pub, []byte{1, 2, 3}), []byte{1, 2, 3}, true},
+		{"truncated", pub[:10], nil, false},
+		{"wrong key type", concat([]byte{0, 1}, pub[2:]), nil, false},
+		{"empty", nil, nil, false},
+	}
+
+	for _, v := range vectors {
+		var pk PublicKey
+		rest, ok := pk.Parse(v.in)
+		if ok != v.ok || !bytes.Equal(rest, v.rest) || (rest == nil) != (v.rest == nil) {
+			t.Errorf("%s: Parse returned (%x, %v), want (%x, %v)", v.name, rest, ok, v.rest, v.ok)
+		}
+		if ok && !bytes.Equal(pk.Serialize(nil), pub) {
+			t.Errorf("%s: Serialize returned %x, want %x", v.name, pk.Serialize(nil), pub)
+		}
+	}
+}
+
+func TestPublicKeySerializeAppends(t *testing.T) {
+	var pk PublicKey
+	pk.Parse(hexBytes(alicePublicKeyHex))
+
+	out := pk.Serialize([]byte{0xAA})
+	if !bytes.Equal(out, concat([]byte{0xAA}, hexBytes(alicePublicKeyHex))) {
+		t.Errorf("Serialize didn't append to its argument: %x", out)
+	}
+}
+
+func TestPublicKeyVerifyParity(t *testing.T) {
+	sig := hexBytes(aliceHelloSignatureHex)
+	hashed := hexBytes(helloHashHex)
+	otherHash := hexBytes("00" + helloHashHex[2:])
+
+	// The results were recorded from PublicKey.Verify of golang.org/x/crypto/otr v0.5.0, which requires the
+	// signature to be exactly 40 bytes long. otr3.PublicKey.Verify instead accepts trailing data and returns it
+	vectors := []struct {
+		name   string
+		hashed []byte
+		sig    []byte
+		rest   []byte
+		ok     bool
+	}{
+		{"valid", hashed, sig, []byte{}, true},
+		{"trailing data", hashed, concat(sig, []byte{9, 9}), nil, false},
+		{"truncated", hashed, sig[:39], nil, false},
+		{"other hash", otherHash, sig, []byte{}, false},
+	}
+
+	var priv PrivateKey
+	priv.Parse(hexBytes(alicePrivateKeyHex))
+
+	for _, v := range vectors {
+		for _, verify := range []func(hashed, sig []byte) ([]byte, bool){priv.PublicKey.Verify, priv.Verify} {
+			rest, ok := verify(v.hashed, v.sig)
+			if ok != v.ok || !bytes.Equal(rest, v.rest) || (rest == nil) != (v.rest == nil) {
+				t.Errorf("%s: Verify returned (%x, %v), want (%x, %v)", v.name, rest, ok, v.rest, v.ok)
+			}
+		}
+	}
+}
+
+func TestPrivateKeySignIsVerifiable(t *testing.T) {
+	var priv PrivateKey
+	priv.Parse(hexBytes(alicePrivateKeyHex))
+
+	sig := priv.Sign(rand.Reader, hexBytes(helloHashHex))
+	if len(sig) != 40 {
+		t.Errorf("signature has length %d, want 40", len(sig))
+	}
+	if _, ok := priv.PublicKey.Verify(hexBytes(helloHashHex), sig); !ok {
+		t.Errorf("signature doesn't verify")
+	}
+}
+
+func TestPrivateKeyImportParity(t *testing.T) {
+	vectors := []struct {
+		name        string
+		in          string
+		ok          bool
+		fingerprint string
+	}{
+		{
+			"libotr file",
+			`(privkeys (account (name "a") (protocol prpl-jabber) (private-key (dsa (p #00FC#) (q #0B#) (g #02#) (y #02#) (x #01#)))))`,
+			true,
+			"a11a4eb517dcc01f556e720cbc5ed573ef0db4b8",
+		},
+		{
+			"zero parameter",
+			`(p #00#) (q #0B#) (g #02#) (y #02#) (x #01#)`,
+			false,
+			"a11a4eb517dcc01f556e720cbc5ed573ef0db4b8",
+		},
+		{
+			"odd number of digits",
+			`(p #0#)`,
+			false,
+			"a11a4eb517dcc01f556e720cbc5ed573ef0db4b8",
+		},
+	}
+
+	// As in x/crypto/otr, the same key is reused, and failed imports leave it unchanged
+	var priv PrivateKey
+	for _, v := range vectors {
+		ok := priv.Import([]byte(v.in))
+		if ok != v.ok {
+			t.Errorf("%s: Import returned %v, want %v", v.name, ok, v.ok)
+		}
+		if fp := hex.EncodeToString(priv.PublicKey.Fingerprint()); fp != v.fingerprint {
+			t.Errorf("%s: fingerprint is %s, want %s", v.name, fp, v.fingerprint)
+		}
+	}
+}
+
+func TestPrivateKeyGenerateSetsThePublicKey(t *testing.T) {
+	var priv PrivateKey
+	priv.Generate(rand.Reader)
+
+	if !bytes.Equal(priv.PublicKey.Fingerprint(), priv.PrivateKey.PublicKey.DefaultFingerprint()) {
+		t.Errorf("the public key wasn't set by Generate")
+	}
+
+	var pk PublicKey
+	if _, ok := pk.Parse(priv.PublicKey.Serialize(nil)); !ok {
+		t.Errorf("failed to parse the serialized public key")
+	}
+}
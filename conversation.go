@@ -1,6 +1,9 @@
 package otr3
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 type msgState int
 
@@ -31,9 +34,18 @@ type Conversation struct {
 	ssid     [8]byte
 	ourKey   *PrivateKey
 	theirKey *PublicKey
+	signer   Signer
+
+	knownKeySource KnownKeySource
+
+	// lastTheirFingerprint is the fingerprint of the peer's key from the last AKE, kept between sessions
+	lastTheirFingerprint []byte
+	previousKeyLookup    PreviousKeyLookup
 
 	ake        *ake
-	smp        smp
+	smp        smpContext
+	smpOptions SMPOptions
+	smpTimeout time.Duration
 	keys       keyManagementContext
 	Policies   policies
 	heartbeat  heartbeatContext
@@ -48,6 +60,14 @@ type Conversation struct {
 	messageEventHandler  MessageEventHandler
 	securityEventHandler SecurityEventHandler
 	receivedKeyHandler   ReceivedKeyHandler
+	keyChangeHandler     KeyChangeHandler
+
+	logger        Logger
+	metrics       Metrics
+	subscriptions eventSubscriptions
+	transcript    *TranscriptRecorder
+	markupHandler MarkupHandler
+	clock         func() time.Time
 
 	debug         bool
 	sentRevealSig bool
@@ -102,6 +122,7 @@ func (c *Conversation) End() (toSend []ValidMessage, err error) {
 
 	c.keys.ourCurrentDHKeys.wipe()
 	c.keys.ourPreviousDHKeys.wipe()
+	c.keys.sessionKeysCache.wipe()
 	wipeBigInt(c.keys.theirCurrentDHPubKey)
 	return
 }
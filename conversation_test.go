@@ -82,6 +82,36 @@ func Test_receiveDecoded_signalsAMessageEventForADataMessageWhenNoEncryptionIsAc
 	}, MessageEventReceivedMessageNotInPrivate, nil, nil)
 }
 
+func Test_receiveDecoded_sendsAnErrorMessageForADataMessageWhenNoEncryptionIsActive(t *testing.T) {
+	m := []byte{
+		0x00, 0x03, // protocol version
+		msgTypeData,
+		0x00, 0x00, 0x01, 0x01,
+		0x00, 0x00, 0x01, 0x01,
+		0x00, // flag
+	}
+	c := newConversation(otrV3{}, fixtureRand())
+	c.SetErrorMessageHandler(DefaultErrorMessageCatalog())
+
+	c.receiveDecoded(m)
+	assertDeepEquals(t, c.injections.messages, []ValidMessage{ValidMessage("?OTR Error: You sent encrypted data to a peer who wasn't expecting it.")})
+}
+
+func Test_receiveDecoded_doesNotSendAnErrorMessageForAnIgnoreUnreadableDataMessageWhenNoEncryptionIsActive(t *testing.T) {
+	m := []byte{
+		0x00, 0x03, // protocol version
+		msgTypeData,
+		0x00, 0x00, 0x01, 0x01,
+		0x00, 0x00, 0x01, 0x01,
+		messageFlagIgnoreUnreadable,
+	}
+	c := newConversation(otrV3{}, fixtureRand())
+	c.SetErrorMessageHandler(DefaultErrorMessageCatalog())
+
+	c.receiveDecoded(m)
+	assertEquals(t, len(c.injections.messages), 0)
+}
+
 func Test_receive_DHCommitMessageReturnsDHKeyForOTR3(t *testing.T) {
 	exp := messageWithHeader{
 		0x00, 0x03, // protocol version
@@ -303,7 +333,7 @@ func Test_End_wipesSMPStateWhenGoingFromEncrypted(t *testing.T) {
 	c := bobContextAfterAKE()
 	c.msgState = encrypted
 	c.smp.state = smpStateExpect2{}
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s1 = fixtureSmp1()
 	c.smp.s2 = fixtureSmp2()
 	c.smp.s3 = fixtureSmp3()
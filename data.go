@@ -44,10 +44,6 @@ func hashMPIs(h hash.Hash, magic byte, mpis ...*big.Int) []byte {
 	return h.Sum(nil)
 }
 
-func hashMPIsBN(h hash.Hash, magic byte, mpis ...*big.Int) *big.Int {
-	return new(big.Int).SetBytes(hashMPIs(h, magic, mpis...))
-}
-
 func extractWord(d []byte) ([]byte, uint32, bool) {
 	if len(d) < 4 {
 		return nil, 0, false
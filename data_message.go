@@ -19,6 +19,7 @@ func (c *Conversation) genDataMsgWithFlag(message []byte, flag byte, tlvs ...tlv
 	if err != nil {
 		return dataMsg{}, dataMessageExtra{}, err
 	}
+	defer keys.wipe()
 
 	topHalfCtr := [8]byte{}
 	counter := c.keys.counterHistory.findCounterFor(c.keys.ourKeyID-1, c.keys.theirKeyID)
@@ -27,14 +28,19 @@ func (c *Conversation) genDataMsgWithFlag(message []byte, flag byte, tlvs ...tlv
 	}
 
 	binary.BigEndian.PutUint64(topHalfCtr[:], counter.ourCounter)
+	c.log(LogLevelDebug, "sending data message", LogField{"senderKeyID", c.keys.ourKeyID - 1}, LogField{"recipientKeyID", c.keys.theirKeyID}, LogField{"counter", counter.ourCounter}, LogField{"flag", flag})
 	counter.ourCounter++
+	c.incrementMetric(MetricDataMessageSent)
 
 	plain := plainDataMsg{
 		message: message,
 		tlvs:    tlvs,
 	}
 
-	encrypted := plain.encrypt(keys.sendingAESKey, topHalfCtr)
+	encrypted, err := plain.encrypt(keys.sendingAESKey, topHalfCtr)
+	if err != nil {
+		return dataMsg{}, dataMessageExtra{}, EncryptionError{EncryptionErrorReasonCipher, err}
+	}
 
 	header, err := c.messageHeader(msgTypeData)
 	if err != nil {
@@ -55,7 +61,7 @@ func (c *Conversation) genDataMsgWithFlag(message []byte, flag byte, tlvs ...tlv
 	c.updateMayRetransmitTo(noRetransmit)
 	c.lastMessage(message)
 
-	x := dataMessageExtra{keys.extraKey[:]}
+	x := dataMessageExtra{makeCopy(keys.extraKey[:])}
 
 	return dataMessage, x, nil
 }
@@ -107,6 +113,9 @@ func (c *Conversation) processDataMessageWithRawErrors(header, msg []byte) (plai
 
 	if c.msgState != encrypted {
 		c.messageEvent(MessageEventReceivedMessageNotInPrivate)
+		if extractDataMessageFlag(msg)&messageFlagIgnoreUnreadable != messageFlagIgnoreUnreadable {
+			c.generatePotentialErrorMessage(ErrorCodeMessageNotInPrivate)
+		}
 		return
 	}
 
@@ -114,6 +123,8 @@ func (c *Conversation) processDataMessageWithRawErrors(header, msg []byte) (plai
 		return
 	}
 
+	c.log(LogLevelDebug, "received data message", LogField{"senderKeyID", dataMessage.senderKeyID}, LogField{"recipientKeyID", dataMessage.recipientKeyID}, LogField{"flag", dataMessage.flag})
+
 	if err = c.keys.checkMessageCounter(dataMessage); err != nil {
 		return
 	}
@@ -122,6 +133,7 @@ func (c *Conversation) processDataMessageWithRawErrors(header, msg []byte) (plai
 	if err != nil {
 		return
 	}
+	defer sessionKeys.wipe()
 
 	if err = dataMessage.checkSign(sessionKeys.receivingMACKey, header); err != nil {
 		return
@@ -130,6 +142,8 @@ func (c *Conversation) processDataMessageWithRawErrors(header, msg []byte) (plai
 	p := plainDataMsg{}
 	//this can't return an error since receivingAESKey is a AES-128 key
 	p.decrypt(sessionKeys.receivingAESKey, dataMessage.topHalfCtr, dataMessage.encryptedMsg)
+	c.updateLastReceived()
+	c.incrementMetric(MetricDataMessageReceived)
 
 	plain = makeCopy(p.message)
 	if len(plain) == 0 {
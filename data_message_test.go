@@ -154,7 +154,7 @@ func Test_processDataMessage_deserializeAndDecryptDataMsg(t *testing.T) {
 	bob.msgState = encrypted
 	bob.Policies.add(allowV3)
 	bob.ourKey = bobPrivateKey
-	bob.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	bob.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte("hello"),
@@ -174,7 +174,7 @@ func Test_processDataMessage_willGenerateAHeartBeatEventForAnEmptyMessage(t *tes
 	bob := newConversation(otrV3{}, rand.Reader)
 	bob.Policies.add(allowV3)
 	bob.ourKey = bobPrivateKey
-	bob.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	bob.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
@@ -198,7 +198,7 @@ func Test_processDataMessage_processSMPMessage(t *testing.T) {
 
 	bob.smp.state = smpStateExpect2{}
 	bob.smp.s1 = fixtureSmp1()
-	bob.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	bob.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		tlvs: []tlv{
@@ -362,7 +362,7 @@ func Test_processDataMessage_rotateOurKeysAfterDecryptingTheMessage(t *testing.T
 	assertNil(t, toSend)
 	assertDeepEquals(t, bobCurrentDHKeys, bob.keys.ourPreviousDHKeys)
 	assertEquals(t, eq(bobCurrentDHKeys.pub, bob.keys.ourCurrentDHKeys.pub), false)
-	assertEquals(t, eq(bobCurrentDHKeys.priv, bob.keys.ourCurrentDHKeys.priv), false)
+	assertEquals(t, eq(bobCurrentDHKeys.priv.bigInt(), bob.keys.ourCurrentDHKeys.priv.bigInt()), false)
 }
 
 func Test_processDataMessage_willReturnAHeartbeatMessageAfterAPlainTextMessage(t *testing.T) {
@@ -418,7 +418,7 @@ func Test_processDataMessage_ignoresTLVsWhenFailsToRotateKeys(t *testing.T) {
 	// setup state for receiving a SMP message 2
 	bob.smp.state = smpStateExpect2{}
 	bob.smp.s1 = fixtureSmp1()
-	bob.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	bob.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	var msg []byte
 	plain := plainDataMsg{}
@@ -447,7 +447,7 @@ func Test_processDataMessage_returnErrorWhenOurKeyIDUnexpected(t *testing.T) {
 	bob.theirKey = &alicePrivateKey.PublicKey
 	bob.keys.ourKeyID = 3
 	bob.keys.theirKeyID = 1
-	bob.keys.ourPreviousDHKeys.priv = bnFromHex("28cea443a1ddeae5c39fd9061a429243eeb52f9f963dcb483a77ec9ed201f8eb3e898fb645657f27")
+	bob.keys.ourPreviousDHKeys.priv = newSecretInt(bnFromHex("28cea443a1ddeae5c39fd9061a429243eeb52f9f963dcb483a77ec9ed201f8eb3e898fb645657f27"))
 	bob.keys.ourPreviousDHKeys.pub = bnFromHex("e291f2e06da00d59c9666d80d6c511a0bd9ae54d916b65db7e72f70904ae05d55259df42fb7b29d11babf11e78cd584d0f137ca1187b4f920e0fbef85c0e5f4b55bf907ea6e119dcfa7e339e72d6b52e874dc46afedd9290360659928ad30f504dad43160946dbd9de7748d18417c223790e528a6f13bf25285318416ccfed0bceafbca70dce832ca8216a654c49ac29dc6af098e7e2744a1dfaf7d2643eb1b3787c4c1db4f649096c3241f69165f965a290651304e23fd2422dae180796d52f")
 	bob.keys.theirCurrentDHPubKey = bnFromHex("da61b77be39426456fecfd6df16645bd2c967bc1a27b165dbf77fea4753ece7a8b938532395bbd1def2890a2792f1854c2d736ee27139356b3bb2583afa4c96a9083209d9f2bb1caeb6fe5ee608715ae6dc1c470e38b895e48e0532af5388c8e591d9ebe361f118ad54d8640f24fa54fdb1d07594d496150554094e5ec4bcfcc6b1b4b058b679824306ad7ae481a25d0758cc01c29c281ce33ac2f58d6eaa99985f855e9ce667ff287b4d27d7c73a7717277546d17e8dd5539861bc26fa04c1b")
 
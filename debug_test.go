@@ -11,7 +11,7 @@ func Test_dumpSMP_dumpsTheCurrentSMPState(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.state = smpStateExpect2{}
 	c.smp.s1 = fixtureSmp1()
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	q := "Blarg"
 	c.smp.question = &q
 
@@ -27,7 +27,7 @@ func Test_dumpSMP_dumpsTheCurrentSMPStateWithQuestion(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.state = smpStateExpect2{}
 	c.smp.s1 = fixtureSmp1()
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	bt := bytes.NewBuffer(make([]byte, 0, 200))
 	c.dumpSMP(bufio.NewWriter(bt))
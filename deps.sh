@@ -4,3 +4,5 @@ set -x
 
 go get github.com/golang/lint/golint
 go get golang.org/x/tools/cmd/cover
+go get golang.org/x/text/unicode/norm
+go get golang.org/x/text/cases
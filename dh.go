@@ -1,6 +1,10 @@
 package otr3
 
-import "math/big"
+import (
+	"math/big"
+
+	"github.com/twstrike/otr3/smp"
+)
 
 var (
 	p         *big.Int // prime field, defined in RFC3526 as Diffie-Hellman Group 5
@@ -32,6 +36,8 @@ func init() {
 
 	pMinusTwo = sub(p, big.NewInt(2))
 	g1 = big.NewInt(2)
+	otrGroupMontgomery = newMontgomeryModulus(p)
+	smpGroup = &smp.Group{P: p, Q: q, G: g1, SecretExp: secretModExp}
 
 	initTLVHandlers()
 }
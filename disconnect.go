@@ -7,6 +7,7 @@ func (c *Conversation) processDisconnectedTLV(t tlv, x dataMessageExtra) (toSend
 	c.msgState = finished
 	c.smp.wipe()
 
+	c.keys.wipe()
 	c.keys = keyManagementContext{}
 
 	return nil, nil
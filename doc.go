@@ -32,6 +32,9 @@
 //  // You can also setup a debug mode
 //  c.SetDebug(true)
 //
+//  // and a logger for structured information about the protocol processing
+//  c.SetLogger(otr3.NewWriterLogger(os.Stderr, otr3.LogLevelInfo))
+//
 //  // Use Send and Receive for messages exchange
 //  toSend, err := c.Send(otr3.ValidMessage("hello"))
 //  plain, toSend, err := c.Receive(toSend)
@@ -1,6 +1,9 @@
 package otr3
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+)
 
 // ErrorCode represents an error that can happen during OTR processing
 type ErrorCode int
@@ -14,8 +17,108 @@ const (
 
 	// ErrorCodeMessageMalformed means the message sent is malformed
 	ErrorCodeMessageMalformed
+
+	// ErrorCodeMessageNotInPrivate means we received an encrypted message while not in a private conversation
+	ErrorCodeMessageNotInPrivate
+)
+
+// EncryptionErrorReason tells why a message couldn't be encrypted
+type EncryptionErrorReason int
+
+const (
+	// EncryptionErrorReasonCipher means the AES cipher failed to encrypt the message with the session key
+	EncryptionErrorReasonCipher EncryptionErrorReason = iota
 )
 
+// EncryptionError is returned, and passed with MessageEventEncryptionError, when the cipher fails to encrypt a message.
+// Errors in the state of the conversation, like a missing secure channel, are returned as they are.
+// The reason is only reported locally - the peer receives the generic ErrorCodeEncryptionError message
+type EncryptionError struct {
+	Reason EncryptionErrorReason
+	Err    error
+}
+
+func (e EncryptionError) Error() string {
+	return "otr: error encrypting message (" + e.Reason.String() + "): " + e.Err.Error()
+}
+
+// ErrorMessageCatalog is an ErrorMessageHandler that returns the message configured for each error code.
+// It can be used to localize the error messages sent to the peer. Codes missing from the catalog fall back
+// to the default English messages
+type ErrorMessageCatalog map[ErrorCode]string
+
+var defaultErrorMessages = map[ErrorCode]string{
+	ErrorCodeEncryptionError:     "Error occurred encrypting message.",
+	ErrorCodeMessageUnreadable:   "You transmitted an unreadable encrypted message.",
+	ErrorCodeMessageMalformed:    "You transmitted a malformed data message.",
+	ErrorCodeMessageNotInPrivate: "You sent encrypted data to a peer who wasn't expecting it.",
+}
+
+// DefaultErrorMessageCatalog returns a catalog with the standard English messages used by libotr
+func DefaultErrorMessageCatalog() ErrorMessageCatalog {
+	ret := ErrorMessageCatalog{}
+	for k, v := range defaultErrorMessages {
+		ret[k] = v
+	}
+	return ret
+}
+
+// HandleErrorMessage returns the message for the given code
+func (c ErrorMessageCatalog) HandleErrorMessage(error ErrorCode) []byte {
+	if msg, ok := c[error]; ok {
+		return []byte(msg)
+	}
+	return []byte(defaultErrorMessages[error])
+}
+
+// ReceivedErrorCode is passed as the error of a MessageEventReceivedMessageGeneralError
+// when the error message sent by the peer uses the standard libotr wording
+type ReceivedErrorCode struct {
+	Code ErrorCode
+}
+
+func (e ReceivedErrorCode) Error() string {
+	return "otr: peer reported " + e.Code.String()
+}
+
+var libotrErrorMessages = []struct {
+	prefix, suffix string
+	code           ErrorCode
+}{
+	{"Error occurred encrypting message.", "", ErrorCodeEncryptionError},
+	{"You transmitted an unreadable encrypted message.", "", ErrorCodeMessageUnreadable},
+	{"You transmitted a malformed data message.", "", ErrorCodeMessageMalformed},
+	{"You sent encrypted data to ", ", who wasn't expecting it.", ErrorCodeMessageNotInPrivate},
+	{"You sent encrypted data to a peer who wasn't expecting it.", "", ErrorCodeMessageNotInPrivate},
+}
+
+// ParseErrorMessage tries to find the error code for an error message sent by the peer.
+// The message can be given with or without the ?OTR Error: prefix. It only recognizes the
+// standard wording used by libotr and this library, and returns false for anything else
+func ParseErrorMessage(msg []byte) (ErrorCode, bool) {
+	if bytes.HasPrefix(msg, errorMarker) {
+		msg = msg[len(errorMarker):]
+	}
+	msg = bytes.TrimSpace(msg)
+
+	for _, m := range libotrErrorMessages {
+		if m.suffix == "" {
+			if bytes.Equal(msg, []byte(m.prefix)) {
+				return m.code, true
+			}
+			continue
+		}
+
+		if len(msg) > len(m.prefix)+len(m.suffix) &&
+			bytes.HasPrefix(msg, []byte(m.prefix)) &&
+			bytes.HasSuffix(msg, []byte(m.suffix)) {
+			return m.code, true
+		}
+	}
+
+	return 0, false
+}
+
 // ErrorMessageHandler generates error messages for error codes
 type ErrorMessageHandler interface {
 	// HandleErrorMessage should return a string according to the error event. This string will be concatenated to an OTR header to produce an OTR protocol error message
@@ -31,6 +134,7 @@ func (d dynamicErrorMessageHandler) HandleErrorMessage(error ErrorCode) []byte {
 }
 
 func (c *Conversation) generatePotentialErrorMessage(ec ErrorCode) {
+	c.publishErrorMessage(ec)
 	if c.errorMessageHandler != nil {
 		msg := c.errorMessageHandler.HandleErrorMessage(ec)
 		c.injectMessage(append(append(errorMarker, ' '), msg...))
@@ -45,11 +149,22 @@ func (s ErrorCode) String() string {
 		return "ErrorCodeMessageUnreadable"
 	case ErrorCodeMessageMalformed:
 		return "ErrorCodeMessageMalformed"
+	case ErrorCodeMessageNotInPrivate:
+		return "ErrorCodeMessageNotInPrivate"
 	default:
 		return "ERROR CODE: (THIS SHOULD NEVER HAPPEN)"
 	}
 }
 
+func (s EncryptionErrorReason) String() string {
+	switch s {
+	case EncryptionErrorReasonCipher:
+		return "EncryptionErrorReasonCipher"
+	default:
+		return "ENCRYPTION ERROR REASON: (THIS SHOULD NEVER HAPPEN)"
+	}
+}
+
 type combinedErrorMessageHandler struct {
 	handlers []ErrorMessageHandler
 }
@@ -6,6 +6,7 @@ func Test_ErrorCode_hasValidStringImplementation(t *testing.T) {
 	assertEquals(t, ErrorCodeEncryptionError.String(), "ErrorCodeEncryptionError")
 	assertEquals(t, ErrorCodeMessageUnreadable.String(), "ErrorCodeMessageUnreadable")
 	assertEquals(t, ErrorCodeMessageMalformed.String(), "ErrorCodeMessageMalformed")
+	assertEquals(t, ErrorCodeMessageNotInPrivate.String(), "ErrorCodeMessageNotInPrivate")
 	assertEquals(t, ErrorCode(20000).String(), "ERROR CODE: (THIS SHOULD NEVER HAPPEN)")
 }
 
@@ -50,3 +51,49 @@ func Test_debugErrorMessageHandler_writesTheErrorCodeToStderr(t *testing.T) {
 	})
 	assertEquals(t, ss, "[DEBUG] HandleErrorMessage(ErrorCodeMessageMalformed)\n")
 }
+
+func Test_ErrorMessageCatalog_returnsTheConfiguredMessageOrTheDefault(t *testing.T) {
+	c := ErrorMessageCatalog{ErrorCodeMessageMalformed: "Du hast eine fehlerhafte Nachricht geschickt."}
+
+	assertEquals(t, string(c.HandleErrorMessage(ErrorCodeMessageMalformed)), "Du hast eine fehlerhafte Nachricht geschickt.")
+	assertEquals(t, string(c.HandleErrorMessage(ErrorCodeEncryptionError)), "Error occurred encrypting message.")
+}
+
+func Test_DefaultErrorMessageCatalog_returnsACopy(t *testing.T) {
+	c := DefaultErrorMessageCatalog()
+	c[ErrorCodeMessageUnreadable] = "changed"
+
+	assertEquals(t, string(DefaultErrorMessageCatalog().HandleErrorMessage(ErrorCodeMessageUnreadable)), "You transmitted an unreadable encrypted message.")
+}
+
+func Test_ParseErrorMessage_recognizesTheStandardLibotrMessages(t *testing.T) {
+	for _, ec := range []ErrorCode{ErrorCodeEncryptionError, ErrorCodeMessageUnreadable, ErrorCodeMessageMalformed, ErrorCodeMessageNotInPrivate} {
+		code, ok := ParseErrorMessage(DefaultErrorMessageCatalog().HandleErrorMessage(ec))
+		assertEquals(t, ok, true)
+		assertEquals(t, code, ec)
+	}
+
+	code, ok := ParseErrorMessage([]byte("?OTR Error: You sent encrypted data to alice@example.com, who wasn't expecting it."))
+	assertEquals(t, ok, true)
+	assertEquals(t, code, ErrorCodeMessageNotInPrivate)
+
+	_, ok = ParseErrorMessage([]byte("?OTR Error: something else went wrong"))
+	assertEquals(t, ok, false)
+}
+
+func Test_EncryptionErrorReason_String(t *testing.T) {
+	assertEquals(t, EncryptionErrorReasonCipher.String(), "EncryptionErrorReasonCipher")
+	assertEquals(t, EncryptionErrorReason(42).String(), "ENCRYPTION ERROR REASON: (THIS SHOULD NEVER HAPPEN)")
+}
+
+func Test_EncryptionError_includesTheReasonAndTheCause(t *testing.T) {
+	err := EncryptionError{EncryptionErrorReasonCipher, newOtrError("bad key")}
+	assertEquals(t, err.Error(), "otr: error encrypting message (EncryptionErrorReasonCipher): otr: bad key")
+}
+
+func Test_genDataMsg_returnsStateErrorsWithoutWrappingThem(t *testing.T) {
+	c := &Conversation{}
+	_, _, err := c.genDataMsg(nil)
+	_, isEncryptionError := err.(EncryptionError)
+	assertEquals(t, isEncryptionError, false)
+}
@@ -2,11 +2,17 @@ package otr3
 
 import "fmt"
 
+var errAgentFrameTooLarge = newOtrError("signing agent frame too large")
+var errAgentProtocol = newOtrError("invalid response from signing agent")
 var errCantAuthenticateWithoutEncryption = newOtrError("can't authenticate a peer without a secure conversation established")
 var errCorruptEncryptedSignature = newOtrError("corrupt encrypted signature")
 var errEncryptedMessageWithNoSecureChannel = newOtrError("encrypted message received without encrypted session established")
 var errUnexpectedPlainMessage = newOtrError("plain message received when encryption was required")
+var errInvalidFingerprint = newOtrError("invalid fingerprint")
+var errInvalidFingerprintEntry = newOtrError("invalid fingerprints file entry")
+var errInvalidFingerprintURI = newOtrError("invalid fingerprint URI")
 var errInvalidOTRMessage = newOtrError("invalid OTR message")
+var errInvalidSMPQuestion = newOtrError("SMP question is not valid UTF-8")
 var errInvalidVersion = newOtrError("no valid version agreement could be found") //libotr ignores this situation
 var errNotWaitingForSMPSecret = newOtrError("not expected SMP secret to be provided now")
 var errReceivedMessageForOtherInstance = newOtrError("received message for other OTR instance") //not exactly an error - we should ignore these messages by default
@@ -0,0 +1,230 @@
+package otr3
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event is implemented by all events a Conversation can publish to its subscribers.
+// The concrete type will be one of SMPEventNotification, MessageEventNotification,
+// SecurityEventNotification, ErrorMessageNotification or KeyChangeNotification
+type Event interface {
+	// Info returns the identity of the conversation that generated the event and the time it happened
+	Info() EventInfo
+	isEvent()
+}
+
+// EventInfo contains the information common to all events
+type EventInfo struct {
+	OurInstanceTag   uint32
+	TheirInstanceTag uint32
+	Time             time.Time
+}
+
+// Info returns the EventInfo itself
+func (e EventInfo) Info() EventInfo {
+	return e
+}
+
+func (EventInfo) isEvent() {}
+
+// KeyChangeNotification is published when the long-term key of the peer differs from the one seen before
+type KeyChangeNotification struct {
+	EventInfo
+	OldFingerprint []byte
+	NewFingerprint []byte
+}
+
+// SMPEventNotification is published for every SMPEvent
+type SMPEventNotification struct {
+	EventInfo
+	Event           SMPEvent
+	ProgressPercent int
+	Question        string
+}
+
+// MessageEventNotification is published for every MessageEvent
+type MessageEventNotification struct {
+	EventInfo
+	Event   MessageEvent
+	Message []byte
+	Error   error
+}
+
+// SecurityEventNotification is published for every SecurityEvent
+type SecurityEventNotification struct {
+	EventInfo
+	Event SecurityEvent
+}
+
+// ErrorMessageNotification is published every time we need to send an error message to the peer
+type ErrorMessageNotification struct {
+	EventInfo
+	Code ErrorCode
+}
+
+// Subscription delivers the events of a conversation asynchronously.
+// Events are queued without limit, so the conversation never blocks waiting for a slow subscriber
+type Subscription struct {
+	events chan Event
+	in     chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events returns the channel the events will be delivered on. The channel is closed after Unsubscribe
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func newSubscription() *Subscription {
+	s := &Subscription{
+		events: make(chan Event),
+		in:     make(chan Event),
+		done:   make(chan struct{}),
+	}
+	go s.deliver()
+	return s
+}
+
+func (s *Subscription) deliver() {
+	defer close(s.events)
+
+	var queue []Event
+	for {
+		var out chan Event
+		var next Event
+		if len(queue) > 0 {
+			out, next = s.events, queue[0]
+		}
+
+		select {
+		case e := <-s.in:
+			queue = append(queue, e)
+		case out <- next:
+			queue[0] = nil
+			queue = queue[1:]
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Subscription) publish(e Event) {
+	select {
+	case s.in <- e:
+	case <-s.done:
+	}
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+type eventSubscriptions struct {
+	sync.Mutex
+	subscriptions []*Subscription
+}
+
+// Subscribe returns a new Subscription that will receive all events generated by this conversation from now on.
+// Every Subscription has a goroutine delivering its events, which only stops when Unsubscribe is called -
+// use SubscribeContext to tie the Subscription to a context instead
+func (c *Conversation) Subscribe() *Subscription {
+	s := newSubscription()
+
+	c.subscriptions.Lock()
+	defer c.subscriptions.Unlock()
+	c.subscriptions.subscriptions = append(c.subscriptions.subscriptions, s)
+
+	return s
+}
+
+// SubscribeContext works like Subscribe, but the Subscription is also removed, and its channel closed, when the context is done
+func (c *Conversation) SubscribeContext(ctx context.Context) *Subscription {
+	s := c.Subscribe()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Unsubscribe(s)
+		case <-s.done:
+		}
+	}()
+
+	return s
+}
+
+// Unsubscribe stops the delivery of events to the given Subscription and closes its channel.
+// Events that haven't been received yet are discarded
+func (c *Conversation) Unsubscribe(s *Subscription) {
+	c.subscriptions.Lock()
+	subs := c.subscriptions.subscriptions
+	for i, ss := range subs {
+		if ss == s {
+			c.subscriptions.subscriptions = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	c.subscriptions.Unlock()
+
+	s.close()
+}
+
+func (c *Conversation) eventInfo() EventInfo {
+	return EventInfo{
+		OurInstanceTag:   c.ourInstanceTag,
+		TheirInstanceTag: c.theirInstanceTag,
+		Time:             c.now(),
+	}
+}
+
+func (c *Conversation) publishEvent(e Event) {
+	c.subscriptions.Lock()
+	defer c.subscriptions.Unlock()
+
+	for _, s := range c.subscriptions.subscriptions {
+		s.publish(e)
+	}
+}
+
+func (c *Conversation) hasSubscribers() bool {
+	c.subscriptions.Lock()
+	defer c.subscriptions.Unlock()
+	return len(c.subscriptions.subscriptions) > 0
+}
+
+func (c *Conversation) publishSMPEvent(e SMPEvent, percent int, question string) {
+	if c.hasSubscribers() {
+		c.publishEvent(SMPEventNotification{c.eventInfo(), e, percent, question})
+	}
+}
+
+func (c *Conversation) publishMessageEvent(e MessageEvent, msg []byte, err error) {
+	if c.hasSubscribers() {
+		if msg != nil {
+			msg = makeCopy(msg)
+		}
+		c.publishEvent(MessageEventNotification{c.eventInfo(), e, msg, err})
+	}
+}
+
+func (c *Conversation) publishSecurityEvent(e SecurityEvent) {
+	if c.hasSubscribers() {
+		c.publishEvent(SecurityEventNotification{c.eventInfo(), e})
+	}
+}
+
+func (c *Conversation) publishErrorMessage(ec ErrorCode) {
+	if c.hasSubscribers() {
+		c.publishEvent(ErrorMessageNotification{c.eventInfo(), ec})
+	}
+}
+
+func (c *Conversation) publishKeyChange(oldFingerprint, newFingerprint []byte) {
+	if c.hasSubscribers() {
+		c.publishEvent(KeyChangeNotification{c.eventInfo(), makeCopy(oldFingerprint), makeCopy(newFingerprint)})
+	}
+}
@@ -0,0 +1,161 @@
+package otr3
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func receiveEvent(t *testing.T, s *Subscription) Event {
+	select {
+	case e := <-s.Events():
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event to be delivered")
+		return nil
+	}
+}
+
+func Test_Subscribe_deliversAllKindsOfEventsWithTheConversationIdentity(t *testing.T) {
+	c := &Conversation{ourInstanceTag: 0x101, theirInstanceTag: 0x102}
+	s := c.Subscribe()
+	defer c.Unsubscribe(s)
+
+	c.smpEventWithQuestion(SMPEventAskForAnswer, 25, "what?")
+	c.messageEventWithMessage(MessageEventReceivedMessageUnencrypted, []byte("hello"))
+	c.securityEvent(GoneSecure)
+	c.generatePotentialErrorMessage(ErrorCodeMessageMalformed)
+
+	smp := receiveEvent(t, s).(SMPEventNotification)
+	assertEquals(t, smp.Event, SMPEventAskForAnswer)
+	assertEquals(t, smp.ProgressPercent, 25)
+	assertEquals(t, smp.Question, "what?")
+	assertEquals(t, smp.OurInstanceTag, uint32(0x101))
+	assertEquals(t, smp.TheirInstanceTag, uint32(0x102))
+	assertEquals(t, smp.Time.IsZero(), false)
+
+	msg := receiveEvent(t, s).(MessageEventNotification)
+	assertEquals(t, msg.Event, MessageEventReceivedMessageUnencrypted)
+	assertDeepEquals(t, msg.Message, []byte("hello"))
+
+	sec := receiveEvent(t, s).(SecurityEventNotification)
+	assertEquals(t, sec.Event, GoneSecure)
+	assertEquals(t, sec.Info().TheirInstanceTag, uint32(0x102))
+
+	em := receiveEvent(t, s).(ErrorMessageNotification)
+	assertEquals(t, em.Code, ErrorCodeMessageMalformed)
+}
+
+func Test_Subscribe_doesNotBlockTheConversationWhenNobodyIsReading(t *testing.T) {
+	c := &Conversation{}
+	s := c.Subscribe()
+
+	for i := 0; i < 1000; i++ {
+		c.securityEvent(StillSecure)
+	}
+
+	c.Unsubscribe(s)
+}
+
+func Test_Subscribe_canBeCalledConcurrentlyWithTheConversation(t *testing.T) {
+	c := &Conversation{}
+	subs := make(chan *Subscription, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subs <- c.Subscribe()
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		c.securityEvent(StillSecure)
+	}
+	wg.Wait()
+	close(subs)
+
+	count := 0
+	for s := range subs {
+		c.Unsubscribe(s)
+		count++
+	}
+	assertEquals(t, count, 10)
+	assertEquals(t, c.hasSubscribers(), false)
+}
+
+func Test_Unsubscribe_closesTheEventsChannel(t *testing.T) {
+	c := &Conversation{}
+	s := c.Subscribe()
+	c.Unsubscribe(s)
+
+	for _ = range s.Events() {
+	}
+
+	c.securityEvent(GoneSecure)
+	assertEquals(t, c.hasSubscribers(), false)
+}
+
+func Test_Subscribe_handlersCanCallBackIntoTheConversation(t *testing.T) {
+	alice := &Conversation{Rand: rand.Reader}
+	alice.ourKey = alicePrivateKey
+	alice.Policies = policies(allowV2 | allowV3)
+	s := alice.Subscribe()
+	defer alice.Unsubscribe(s)
+
+	bob := &Conversation{Rand: rand.Reader}
+	bob.ourKey = bobPrivateKey
+	bob.Policies = policies(allowV2 | allowV3)
+
+	_, toSend, _ := bob.Receive(alice.QueryMessage())
+	_, toSend, _ = alice.Receive(toSend[0])
+	_, toSend, _ = bob.Receive(toSend[0])
+	_, toSend, _ = alice.Receive(toSend[0])
+	bob.Receive(toSend[0])
+
+	// The handler runs outside of Receive, so it can use the conversation that generated the event
+	var greeting []ValidMessage
+	handle := func(e Event) {
+		if sec, ok := e.(SecurityEventNotification); ok && sec.Event == GoneSecure {
+			greeting, _ = alice.Send(ValidMessage("hello from the handler"))
+		}
+	}
+	handle(receiveEvent(t, s))
+
+	assertEquals(t, len(greeting), 1)
+	plain, _, err := bob.Receive(greeting[0])
+	assertNil(t, err)
+	assertDeepEquals(t, plain, MessagePlaintext("hello from the handler"))
+}
+
+func Test_SubscribeContext_closesTheEventsChannelWhenTheContextIsDone(t *testing.T) {
+	c := &Conversation{}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := c.SubscribeContext(ctx)
+
+	c.securityEvent(GoneSecure)
+	assertEquals(t, receiveEvent(t, s).(SecurityEventNotification).Event, GoneSecure)
+
+	cancel()
+	select {
+	case _, ok := <-s.Events():
+		for ok {
+			_, ok = <-s.Events()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the events channel to be closed")
+	}
+	assertEquals(t, c.hasSubscribers(), false)
+}
+
+func Test_Unsubscribe_worksForSubscriptionsFromAnotherConversation(t *testing.T) {
+	c := &Conversation{}
+	s := (&Conversation{}).Subscribe()
+	c.Unsubscribe(s)
+
+	for _ = range s.Events() {
+	}
+}
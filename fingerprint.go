@@ -0,0 +1,105 @@
+package otr3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// FingerprintSize is the size in bytes of the default (SHA-1) fingerprint
+const FingerprintSize = 20
+
+// FingerprintURIScheme is the scheme of URIs created by FingerprintURI
+const FingerprintURIScheme = "otr"
+
+// FormatFingerprint returns the fingerprint in the human readable format used by libotr:
+// groups of eight uppercase hex digits separated by spaces, for example
+// "0E3B8F71 5D2C2A2A 48E6F3E4 3F6C44A7 8C4A11E3"
+func FormatFingerprint(fp []byte) string {
+	h := strings.ToUpper(hex.EncodeToString(fp))
+
+	groups := make([]string, 0, (len(h)+7)/8)
+	for len(h) > 8 {
+		groups = append(groups, h[:8])
+		h = h[8:]
+	}
+	groups = append(groups, h)
+
+	return strings.Join(groups, " ")
+}
+
+// ParseFingerprint parses a fingerprint written as hex digits. Case doesn't matter, and spaces, tabs,
+// newlines and colons between the digits are ignored - so the output of FormatFingerprint can be parsed back
+func ParseFingerprint(s string) ([]byte, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n', ':':
+			return -1
+		}
+		return r
+	}, s)
+
+	if len(digits) != 2*FingerprintSize {
+		return nil, errInvalidFingerprint
+	}
+
+	fp, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, errInvalidFingerprint
+	}
+	return fp, nil
+}
+
+// FingerprintsEqual returns true if both fingerprints are the same
+func FingerprintsEqual(fp1, fp2 []byte) bool {
+	return len(fp1) > 0 && bytes.Equal(fp1, fp2)
+}
+
+// HumanFingerprint returns the default fingerprint of the key in the format of FormatFingerprint
+func (pub *PublicKey) HumanFingerprint() string {
+	return FormatFingerprint(pub.DefaultFingerprint())
+}
+
+// MatchesFingerprint returns true if the default fingerprint of the key is the one given in human readable form
+func (pub *PublicKey) MatchesFingerprint(s string) bool {
+	fp, err := ParseFingerprint(s)
+	return err == nil && FingerprintsEqual(pub.DefaultFingerprint(), fp)
+}
+
+// FingerprintURI returns a URI that identifies the account with the given fingerprint, suitable for QR codes and links,
+// for example "otr:alice@example.com?fingerprint=0E3B8F715D2C2A2A48E6F3E43F6C44A78C4A11E3".
+// The account is optional, and is escaped so that it can contain any characters, such as a resource
+func FingerprintURI(account string, fp []byte) string {
+	u := url.URL{
+		Scheme:   FingerprintURIScheme,
+		Opaque:   url.PathEscape(account),
+		RawQuery: "fingerprint=" + strings.ToUpper(hex.EncodeToString(fp)),
+	}
+	return u.String()
+}
+
+// ParseFingerprintURI parses a URI created by FingerprintURI, returning the account and the fingerprint
+func ParseFingerprintURI(uri string) (account string, fp []byte, err error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != FingerprintURIScheme || u.Host != "" || u.Path != "" {
+		return "", nil, errInvalidFingerprintURI
+	}
+
+	account, err = url.PathUnescape(u.Opaque)
+	if err != nil {
+		return "", nil, errInvalidFingerprintURI
+	}
+
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", nil, errInvalidFingerprintURI
+	}
+
+	fp, err = ParseFingerprint(values.Get("fingerprint"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return account, fp, nil
+}
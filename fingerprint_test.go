@@ -0,0 +1,101 @@
+package otr3
+
+import "testing"
+
+var fingerprintFixture = []byte{
+	0x0e, 0x3b, 0x8f, 0x71, 0x5d, 0x2c, 0x2a, 0x2a, 0x48, 0xe6,
+	0xf3, 0xe4, 0x3f, 0x6c, 0x44, 0xa7, 0x8c, 0x4a, 0x11, 0xe3,
+}
+
+func Test_FormatFingerprint_returnsFiveGroupsOfEightUppercaseHexDigits(t *testing.T) {
+	assertEquals(t, FormatFingerprint(fingerprintFixture), "0E3B8F71 5D2C2A2A 48E6F3E4 3F6C44A7 8C4A11E3")
+}
+
+func Test_FormatFingerprint_handlesFingerprintsOfOtherSizes(t *testing.T) {
+	assertEquals(t, FormatFingerprint([]byte{0x01, 0x02, 0x03, 0x04, 0x05}), "01020304 05")
+	assertEquals(t, FormatFingerprint(nil), "")
+}
+
+func Test_ParseFingerprint_parsesTheHumanFormat(t *testing.T) {
+	fp, err := ParseFingerprint("0E3B8F71 5D2C2A2A 48E6F3E4 3F6C44A7 8C4A11E3")
+
+	assertNil(t, err)
+	assertDeepEquals(t, fp, fingerprintFixture)
+}
+
+func Test_ParseFingerprint_ignoresCaseAndSeparators(t *testing.T) {
+	fp, err := ParseFingerprint(" 0e:3b:8f:71:5d:2c:2a:2a:48:e6\n\tf3e43f6c44a78c4a11e3 ")
+
+	assertNil(t, err)
+	assertDeepEquals(t, fp, fingerprintFixture)
+}
+
+func Test_ParseFingerprint_rejectsInvalidFingerprints(t *testing.T) {
+	for _, s := range []string{"", "0E3B8F71 5D2C2A2A", "0E3B8F71 5D2C2A2A 48E6F3E4 3F6C44A7 8C4A11EX", "0E3B8F71-5D2C2A2A-48E6F3E4-3F6C44A7-8C4A11E3"} {
+		_, err := ParseFingerprint(s)
+		assertEquals(t, err, errInvalidFingerprint)
+	}
+}
+
+func Test_FingerprintsEqual_comparesFingerprints(t *testing.T) {
+	assertEquals(t, FingerprintsEqual(fingerprintFixture, makeCopy(fingerprintFixture)), true)
+	assertEquals(t, FingerprintsEqual(fingerprintFixture, fingerprintFixture[1:]), false)
+	assertEquals(t, FingerprintsEqual(nil, []byte{}), false)
+}
+
+func Test_PublicKey_HumanFingerprint_formatsTheDefaultFingerprint(t *testing.T) {
+	assertEquals(t, alicePrivateKey.PublicKey.HumanFingerprint(), FormatFingerprint(alicePrivateKey.PublicKey.DefaultFingerprint()))
+}
+
+func Test_PublicKey_MatchesFingerprint(t *testing.T) {
+	human := alicePrivateKey.PublicKey.HumanFingerprint()
+
+	assertEquals(t, alicePrivateKey.PublicKey.MatchesFingerprint(human), true)
+	assertEquals(t, bobPrivateKey.PublicKey.MatchesFingerprint(human), false)
+	assertEquals(t, alicePrivateKey.PublicKey.MatchesFingerprint("not a fingerprint"), false)
+}
+
+func Test_FingerprintURI_includesTheAccountAndTheFingerprint(t *testing.T) {
+	assertEquals(t, FingerprintURI("alice@example.com", fingerprintFixture), "otr:alice@example.com?fingerprint=0E3B8F715D2C2A2A48E6F3E43F6C44A78C4A11E3")
+	assertEquals(t, FingerprintURI("alice@example.com/phone", fingerprintFixture), "otr:alice@example.com%2Fphone?fingerprint=0E3B8F715D2C2A2A48E6F3E43F6C44A78C4A11E3")
+	assertEquals(t, FingerprintURI("", fingerprintFixture), "otr:?fingerprint=0E3B8F715D2C2A2A48E6F3E43F6C44A78C4A11E3")
+}
+
+func Test_ParseFingerprintURI_returnsTheAccountAndTheFingerprint(t *testing.T) {
+	accounts := []string{
+		"alice@example.com",
+		"example.com",
+		"",
+		"al ice@example.com",
+		"alice@example.com/phone",
+		"alice@example.com/My Laptop",
+		"ålice@exämple.com/télé",
+		"alice?x=1#y@example.com/a/b",
+		"%41lice@example.com",
+	}
+
+	for _, account := range accounts {
+		a, fp, err := ParseFingerprintURI(FingerprintURI(account, fingerprintFixture))
+
+		assertNil(t, err)
+		assertEquals(t, a, account)
+		assertDeepEquals(t, fp, fingerprintFixture)
+	}
+}
+
+func Test_ParseFingerprintURI_rejectsInvalidURIs(t *testing.T) {
+	_, _, err := ParseFingerprintURI("http://alice@example.com?fingerprint=0E3B8F715D2C2A2A48E6F3E43F6C44A78C4A11E3")
+	assertEquals(t, err, errInvalidFingerprintURI)
+
+	_, _, err = ParseFingerprintURI("otr://alice@example.com?fingerprint=0E3B8F715D2C2A2A48E6F3E43F6C44A78C4A11E3")
+	assertEquals(t, err, errInvalidFingerprintURI)
+
+	_, _, err = ParseFingerprintURI("otr:alice@example.com%ZZ?fingerprint=0E3B8F715D2C2A2A48E6F3E43F6C44A78C4A11E3")
+	assertEquals(t, err, errInvalidFingerprintURI)
+
+	_, _, err = ParseFingerprintURI("otr:alice@example.com?fingerprint=0E3B")
+	assertEquals(t, err, errInvalidFingerprint)
+
+	_, _, err = ParseFingerprintURI("otr:alice@example.com")
+	assertEquals(t, err, errInvalidFingerprint)
+}
@@ -0,0 +1,178 @@
+package otr3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FingerprintEntry is one line of a libotr fingerprints file: the fingerprint of a peer's key
+// as seen from one of our accounts, together with the trust the user has put in it
+type FingerprintEntry struct {
+	// Peer is the name of the peer that used the key
+	Peer string
+	// Account is the name of our account that talked to the peer
+	Account     string
+	Protocol    string
+	Fingerprint []byte
+	// Trust is empty for keys that haven't been verified. libotr writes "verified" for keys
+	// verified manually and "smp" for keys verified with the Socialist Millionaires' Protocol
+	Trust string
+}
+
+// Trusted returns true if the user has verified the fingerprint
+func (e *FingerprintEntry) Trusted() bool {
+	return e.Trust != ""
+}
+
+// Fingerprints is the content of a libotr fingerprints file
+type Fingerprints []*FingerprintEntry
+
+// Lookup returns the entries for the given peer of our account
+func (f Fingerprints) Lookup(account, protocol, peer string) Fingerprints {
+	var res Fingerprints
+	for _, e := range f {
+		if e.Account == account && e.Protocol == protocol && e.Peer == peer {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// KnownKeySource returns a KnownKeySource for conversations with the given peer of our account
+func (f Fingerprints) KnownKeySource(account, protocol, peer string) KnownKeySource {
+	entries := f.Lookup(account, protocol, peer)
+	return dynamicKnownKeySource{
+		known: func(fingerprint []byte) (bool, bool) {
+			for _, e := range entries {
+				if bytes.Equal(e.Fingerprint, fingerprint) {
+					return true, e.Trusted()
+				}
+			}
+			return false, false
+		},
+		hasKnown: func() bool {
+			return len(entries) > 0
+		},
+	}
+}
+
+// ImportFingerprintsFromFile will read the libotr fingerprints file given and return all entries in it
+func ImportFingerprintsFromFile(fname string) (Fingerprints, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ImportFingerprints(f)
+}
+
+// ExportFingerprintsToFile will write all the entries to the named file in libotr format. The entries are
+// written to a temporary file that replaces the named file when complete, so the existing file is left
+// untouched if an entry is invalid or writing fails
+func ExportFingerprintsToFile(f Fingerprints, fname string) error {
+	for _, e := range f {
+		if !validFingerprintEntry(e) {
+			return errInvalidFingerprintEntry
+		}
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(fname), filepath.Base(fname)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if err = ExportFingerprints(f, file); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), fname)
+}
+
+// ImportFingerprints will read data in the format of the libotr fingerprints file and return all entries in it.
+// Every line has the peer, account, protocol, hex fingerprint and optional trust, separated by tabs
+func ImportFingerprints(r io.Reader) (Fingerprints, error) {
+	var res Fingerprints
+
+	br := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			e, ok := parseFingerprintEntry(line)
+			if !ok {
+				return nil, newOtrErrorf("invalid fingerprints file entry on line %d", lineNumber)
+			}
+			res = append(res, e)
+		}
+
+		if err == io.EOF {
+			return res, nil
+		}
+	}
+}
+
+func parseFingerprintEntry(line string) (*FingerprintEntry, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 4 && len(fields) != 5 {
+		return nil, false
+	}
+
+	if len(fields[3]) != hex.EncodedLen(FingerprintSize) {
+		return nil, false
+	}
+	fp, err := hex.DecodeString(fields[3])
+	if err != nil {
+		return nil, false
+	}
+
+	e := &FingerprintEntry{
+		Peer:        fields[0],
+		Account:     fields[1],
+		Protocol:    fields[2],
+		Fingerprint: fp,
+	}
+	if len(fields) == 5 {
+		e.Trust = fields[4]
+	}
+	return e, true
+}
+
+// ExportFingerprints will write all the entries to the given writer in the format of the libotr fingerprints file
+func ExportFingerprints(f Fingerprints, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range f {
+		if !validFingerprintEntry(e) {
+			return errInvalidFingerprintEntry
+		}
+
+		bw.WriteString(strings.Join([]string{e.Peer, e.Account, e.Protocol, hex.EncodeToString(e.Fingerprint), e.Trust}, "\t"))
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+func validFingerprintEntry(e *FingerprintEntry) bool {
+	for _, s := range []string{e.Peer, e.Account, e.Protocol, e.Trust} {
+		if strings.ContainsAny(s, "\t\r\n") {
+			return false
+		}
+	}
+	return len(e.Fingerprint) == FingerprintSize
+}
@@ -0,0 +1,142 @@
+package otr3
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ImportFingerprintsFromFile_readsAllEntries(t *testing.T) {
+	res, err := ImportFingerprintsFromFile("test_resources/valid.fingerprints")
+
+	assertNil(t, err)
+	assertEquals(t, len(res), 3)
+	assertDeepEquals(t, res[0], &FingerprintEntry{
+		Peer:        "bob@jabber.org",
+		Account:     "alice@jabber.org/",
+		Protocol:    "prpl-jabber",
+		Fingerprint: bytesFromHex("0e3b8f715d2c2a2a48e6f3e43f6c44a78c4a11e3"),
+		Trust:       "verified",
+	})
+	assertEquals(t, res[1].Trusted(), false)
+	assertEquals(t, res[2].Trust, "smp")
+	assertEquals(t, res[2].Trusted(), true)
+}
+
+func Test_ImportFingerprintsFromFile_returnsAnErrorIfTheFileDoesntExist(t *testing.T) {
+	_, err := ImportFingerprintsFromFile("this_file_doesnt_exist.fingerprints")
+	assertNotNil(t, err)
+}
+
+func Test_ImportFingerprintsFromFile_returnsAnErrorIfTheFileIsInvalid(t *testing.T) {
+	_, err := ImportFingerprintsFromFile("test_resources/invalid.fingerprints")
+	assertEquals(t, err, newOtrError("invalid fingerprints file entry on line 1"))
+}
+
+func Test_ImportFingerprints_acceptsEntriesWithoutTrustAndCRLF(t *testing.T) {
+	res, err := ImportFingerprints(bytes.NewBufferString("\nbob\talice\tprpl-irc\tF00DFACEF00DFACEF00DFACEF00DFACEF00DFACE\r\n"))
+
+	assertNil(t, err)
+	assertEquals(t, len(res), 1)
+	assertEquals(t, res[0].Trust, "")
+	assertDeepEquals(t, res[0].Fingerprint, bytesFromHex("f00dfacef00dfacef00dfacef00dfacef00dface"))
+}
+
+func Test_ImportFingerprints_reportsTheLineOfInvalidEntries(t *testing.T) {
+	data := "bob\talice\tprpl-irc\tf00dfacef00dfacef00dfacef00dfacef00dface\n" +
+		"bob\talice\tprpl-irc\tf00dface\n"
+	_, err := ImportFingerprints(bytes.NewBufferString(data))
+	assertEquals(t, err, newOtrError("invalid fingerprints file entry on line 2"))
+}
+
+func Test_ExportFingerprints_roundTripsTheSampleFile(t *testing.T) {
+	original, _ := ioutil.ReadFile("test_resources/valid.fingerprints")
+	res, _ := ImportFingerprints(bytes.NewBuffer(original))
+
+	var out bytes.Buffer
+	err := ExportFingerprints(res, &out)
+
+	assertNil(t, err)
+	assertDeepEquals(t, out.Bytes(), original)
+}
+
+func Test_ExportFingerprints_rejectsEntriesThatWouldCorruptTheFile(t *testing.T) {
+	fp := bytesFromHex("f00dfacef00dfacef00dfacef00dfacef00dface")
+	for _, e := range []*FingerprintEntry{
+		{Peer: "bob\tevil", Account: "alice", Protocol: "prpl-irc", Fingerprint: fp},
+		{Peer: "bob", Account: "alice\n", Protocol: "prpl-irc", Fingerprint: fp},
+		{Peer: "bob", Account: "alice", Protocol: "prpl-irc", Fingerprint: fp[:10]},
+	} {
+		err := ExportFingerprints(Fingerprints{e}, ioutil.Discard)
+		assertEquals(t, err, errInvalidFingerprintEntry)
+	}
+}
+
+func Test_ExportFingerprintsToFile_exportsFingerprintsToAFile(t *testing.T) {
+	res, _ := ImportFingerprintsFromFile("test_resources/valid.fingerprints")
+
+	err := ExportFingerprintsToFile(res, "test_resources/test_export_of_fingerprints.blah")
+	defer os.Remove("test_resources/test_export_of_fingerprints.blah")
+	assertNil(t, err)
+
+	res2, err2 := ImportFingerprintsFromFile("test_resources/test_export_of_fingerprints.blah")
+	assertNil(t, err2)
+	assertDeepEquals(t, res2, res)
+}
+
+func Test_ExportFingerprintsToFile_keepsTheExistingFileWhenAnEntryIsInvalid(t *testing.T) {
+	fname := "test_resources/test_export_of_invalid_fingerprints.blah"
+	ioutil.WriteFile(fname, []byte("existing"), 0600)
+	defer os.Remove(fname)
+
+	invalid := &FingerprintEntry{Peer: "bob\tevil", Account: "alice", Protocol: "prpl-irc", Fingerprint: bytesFromHex("f00dfacef00dfacef00dfacef00dfacef00dface")}
+	err := ExportFingerprintsToFile(Fingerprints{invalid}, fname)
+	assertEquals(t, err, errInvalidFingerprintEntry)
+
+	content, _ := ioutil.ReadFile(fname)
+	assertDeepEquals(t, content, []byte("existing"))
+
+	leftovers, _ := filepath.Glob(fname + ".tmp*")
+	assertEquals(t, len(leftovers), 0)
+}
+
+func Test_Fingerprints_Lookup_returnsTheEntriesOfThePeer(t *testing.T) {
+	res, _ := ImportFingerprintsFromFile("test_resources/valid.fingerprints")
+
+	assertEquals(t, len(res.Lookup("alice@jabber.org/", "prpl-jabber", "bob@jabber.org")), 2)
+	assertEquals(t, len(res.Lookup("alice@jabber.org/", "prpl-irc", "bob@jabber.org")), 0)
+}
+
+func Test_Fingerprints_KnownKeySource_reportsKnownAndVerifiedKeys(t *testing.T) {
+	res, _ := ImportFingerprintsFromFile("test_resources/valid.fingerprints")
+	s := res.KnownKeySource("alice@jabber.org/", "prpl-jabber", "bob@jabber.org")
+
+	known, verified := s.KnownFingerprint(bytesFromHex("0e3b8f715d2c2a2a48e6f3e43f6c44a78c4a11e3"))
+	assertTrue(t, known)
+	assertTrue(t, verified)
+
+	known, verified = s.KnownFingerprint(bytesFromHex("1d9e1c0a9b5c6e0b3f6a8d2e7c4b1a0f9e8d7c6b"))
+	assertTrue(t, known)
+	assertFalse(t, verified)
+
+	known, _ = s.KnownFingerprint(bytesFromHex("f00dfacef00dfacef00dfacef00dfacef00dface"))
+	assertFalse(t, known)
+
+	assertTrue(t, s.HasKnownFingerprints())
+	assertFalse(t, res.KnownKeySource("alice", "prpl-irc", "dave").HasKnownFingerprints())
+}
+
+func Test_Fingerprints_KnownKeySource_signalsVerifiedPeersDuringTheAKE(t *testing.T) {
+	alice := newAKEConversation(alicePrivateKey)
+	bob := newAKEConversation(bobPrivateKey)
+
+	fps := Fingerprints{{Peer: "bob", Account: "alice", Protocol: "prpl-irc", Fingerprint: bobPrivateKey.PublicKey.DefaultFingerprint(), Trust: "smp"}}
+	alice.SetKnownKeySource(fps.KnownKeySource("alice", "prpl-irc", "bob"))
+	events := alice.collectSecurityEvents()
+
+	performAKE(alice, bob)
+
+	assertDeepEquals(t, *events, []SecurityEvent{GoneSecure, SecureVerified})
+}
@@ -105,7 +105,7 @@ func bobContextAfterAKE() *Conversation {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.keys.ourKeyID = 2
 	c.keys.ourCurrentDHKeys.pub = fixedGX()
-	c.keys.ourPreviousDHKeys.priv = fixedX()
+	c.keys.ourPreviousDHKeys.priv = newSecretInt(fixedX())
 	c.keys.ourPreviousDHKeys.pub = fixedGX()
 
 	c.keys.theirKeyID = 1
@@ -117,9 +117,9 @@ func bobContextAfterAKE() *Conversation {
 func aliceContextAfterAKE() *Conversation {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.keys.ourKeyID = 1
-	c.keys.ourCurrentDHKeys.priv = fixedY()
+	c.keys.ourCurrentDHKeys.priv = newSecretInt(fixedY())
 	c.keys.ourCurrentDHKeys.pub = fixedGY()
-	c.keys.ourPreviousDHKeys.priv = fixedY()
+	c.keys.ourPreviousDHKeys.priv = newSecretInt(fixedY())
 	c.keys.ourPreviousDHKeys.pub = fixedGY()
 
 	c.keys.theirKeyID = 1
@@ -133,7 +133,7 @@ func aliceContextAfterAKE() *Conversation {
 func bobContextAtAwaitingSig() *Conversation {
 	c := bobContextAtReceiveDHKey()
 	c.ake.keys.ourKeyID = 1
-	c.ake.keys.ourCurrentDHKeys.priv = fixedX()
+	c.ake.keys.ourCurrentDHKeys.priv = newSecretInt(fixedX())
 	c.ake.keys.ourCurrentDHKeys.pub = fixedGX()
 	c.ake.keys.theirKeyID = 1
 	c.ake.keys.theirCurrentDHPubKey = fixedGY()
@@ -214,11 +214,11 @@ func fixtureDataMsg(plain plainDataMsg) ([]byte, keyManagementContext) {
 		ourKeyID:   senderKeyID + 1,
 		theirKeyID: recipientKeyID + 1,
 		ourCurrentDHKeys: dhKeyPair{
-			priv: fixedY(),
+			priv: newSecretInt(fixedY()),
 			pub:  fixedGY(),
 		},
 		ourPreviousDHKeys: dhKeyPair{
-			priv: fixedY(),
+			priv: newSecretInt(fixedY()),
 			pub:  fixedGY(),
 		},
 		theirCurrentDHPubKey:  fixedGX(),
@@ -238,7 +238,7 @@ func fixtureDataMsg(plain plainDataMsg) ([]byte, keyManagementContext) {
 		y:          fixedGY(), //this is alices current Pub
 		topHalfCtr: [8]byte{0, 0, 0, 0, 0, 0, 0, 2},
 	}
-	m.encryptedMsg = plain.encrypt(keys.sendingAESKey, m.topHalfCtr)
+	m.encryptedMsg, _ = plain.encrypt(keys.sendingAESKey, m.topHalfCtr)
 	m.sign(keys.sendingMACKey, h)
 	msg := append(h, m.serialize()...)
 
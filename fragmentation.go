@@ -55,6 +55,7 @@ func (c *Conversation) fragment(data encodedMessage, fraglen uint16) []ValidMess
 	}
 
 	numFragments := (l / int(realFraglen)) + 1
+	c.log(LogLevelDebug, "fragmenting message", LogField{"fragments", numFragments}, LogField{"fragmentSize", fraglen})
 	ret := make([]ValidMessage, numFragments)
 	for i := 0; i < numFragments; i++ {
 		prefix := c.version.fragmentPrefix(i, numFragments, c.ourInstanceTag, c.theirInstanceTag)
@@ -118,17 +119,24 @@ func (c *Conversation) receiveFragment(beforeCtx fragmentationContext, data Vali
 	}
 
 	if !ok1 || !ok2 {
+		c.log(LogLevelWarn, "received invalid fragment")
 		return beforeCtx, newOtrError("invalid OTR fragment")
 	}
 
 	switch {
 	case fragmentIsInvalid(ix, l):
+		c.log(LogLevelWarn, "discarding fragment", LogField{"index", ix}, LogField{"total", l})
+		c.incrementMetric(MetricFragmentDropped)
 		return beforeCtx.discardFragment(), nil
 	case fragmentIsFirstMessage(ix, l):
+		c.log(LogLevelDebug, "received fragment", LogField{"index", ix}, LogField{"total", l})
 		return restartFragment(resultData, ix, l), nil
 	case fragmentIsNextMessage(beforeCtx, ix, l):
+		c.log(LogLevelDebug, "received fragment", LogField{"index", ix}, LogField{"total", l})
 		return beforeCtx.appendFragment(resultData, ix, l), nil
 	default:
+		c.log(LogLevelWarn, "forgetting fragments received out of order", LogField{"index", ix}, LogField{"total", l})
+		c.incrementMetric(MetricFragmentDropped)
 		return forgetFragment(), nil
 	}
 }
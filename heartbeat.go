@@ -6,11 +6,16 @@ import "time"
 const heartbeatInterval = 60 * time.Second
 
 type heartbeatContext struct {
-	lastSent time.Time
+	lastSent     time.Time
+	lastReceived time.Time
 }
 
 func (c *Conversation) updateLastSent() {
-	c.heartbeat.lastSent = time.Now()
+	c.heartbeat.lastSent = c.now()
+}
+
+func (c *Conversation) updateLastReceived() {
+	c.heartbeat.lastReceived = c.now()
 }
 
 func (c *Conversation) maybeHeartbeat(plain MessagePlaintext, toSend messageWithHeader, err error) (MessagePlaintext, []messageWithHeader, error) {
@@ -26,7 +31,7 @@ func (c *Conversation) potentialHeartbeat(plain MessagePlaintext) (toSend messag
 		return
 	}
 
-	now := time.Now()
+	now := c.now()
 	if !c.heartbeat.lastSent.Before(now.Add(-heartbeatInterval)) {
 		return
 	}
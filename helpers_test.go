@@ -112,7 +112,7 @@ func newConversation(v otrVersion, rand io.Reader) *Conversation {
 	return &Conversation{
 		version: v,
 		Rand:    rand,
-		smp: smp{
+		smp: smpContext{
 			state: smpStateExpect1{},
 		},
 		ake:              akeNotStarted,
@@ -0,0 +1,361 @@
+package otr3
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+// MessageKind is the kind of an OTR wire message
+type MessageKind int
+
+const (
+	// MessageKindPlaintext is a message without any OTR content
+	MessageKindPlaintext MessageKind = iota
+	// MessageKindTaggedPlaintext is a plaintext message with a whitespace tag
+	MessageKindTaggedPlaintext
+	// MessageKindQuery is an OTR query message
+	MessageKindQuery
+	// MessageKindError is an OTR error message
+	MessageKindError
+	// MessageKindFragment is one fragment of a larger message
+	MessageKindFragment
+	// MessageKindDHCommit is the first message of the AKE
+	MessageKindDHCommit
+	// MessageKindDHKey is the second message of the AKE
+	MessageKindDHKey
+	// MessageKindRevealSignature is the third message of the AKE
+	MessageKindRevealSignature
+	// MessageKindSignature is the last message of the AKE
+	MessageKindSignature
+	// MessageKindData is an encrypted data message
+	MessageKindData
+	// MessageKindV1KeyExchange is a key exchange message of OTR version 1, which is not supported
+	MessageKindV1KeyExchange
+	// MessageKindUnknown is a message that starts like an OTR message but can't be recognized
+	MessageKindUnknown
+)
+
+// String returns the string representation of the MessageKind
+func (k MessageKind) String() string {
+	switch k {
+	case MessageKindPlaintext:
+		return "MessageKindPlaintext"
+	case MessageKindTaggedPlaintext:
+		return "MessageKindTaggedPlaintext"
+	case MessageKindQuery:
+		return "MessageKindQuery"
+	case MessageKindError:
+		return "MessageKindError"
+	case MessageKindFragment:
+		return "MessageKindFragment"
+	case MessageKindDHCommit:
+		return "MessageKindDHCommit"
+	case MessageKindDHKey:
+		return "MessageKindDHKey"
+	case MessageKindRevealSignature:
+		return "MessageKindRevealSignature"
+	case MessageKindSignature:
+		return "MessageKindSignature"
+	case MessageKindData:
+		return "MessageKindData"
+	case MessageKindV1KeyExchange:
+		return "MessageKindV1KeyExchange"
+	case MessageKindUnknown:
+		return "MessageKindUnknown"
+	default:
+		return "MESSAGE KIND: (THIS SHOULD NEVER HAPPEN)"
+	}
+}
+
+// MessageInfo describes the content of an OTR wire message. Only the part matching the Kind is set
+type MessageInfo struct {
+	Kind MessageKind
+
+	// Version is the protocol version of AKE messages, data messages and fragments
+	Version uint16
+	// SenderInstanceTag and ReceiverInstanceTag are only set for version 3 messages and fragments
+	SenderInstanceTag   uint32
+	ReceiverInstanceTag uint32
+
+	// Text is the human readable part of plaintext, tagged plaintext and error messages
+	Text []byte
+	// Versions are the protocol versions offered by query messages and whitespace tags
+	Versions []int
+	// ErrorCode is set for error messages that use one of the standard texts
+	ErrorCode      ErrorCode
+	KnownErrorCode bool
+
+	Fragment        *FragmentInfo
+	DHCommit        *DHCommitInfo
+	DHKey           *DHKeyInfo
+	RevealSignature *RevealSignatureInfo
+	Signature       *SignatureInfo
+	Data            *DataMessageInfo
+}
+
+// FragmentInfo is the content of a fragment
+type FragmentInfo struct {
+	Index, Total uint16
+	Data         []byte
+}
+
+// DHCommitInfo is the content of a DH commit message
+type DHCommitInfo struct {
+	EncryptedGx []byte
+	HashedGx    []byte
+}
+
+// DHKeyInfo is the content of a DH key message
+type DHKeyInfo struct {
+	Gy *big.Int
+}
+
+// RevealSignatureInfo is the content of a reveal signature message
+type RevealSignatureInfo struct {
+	RevealedKey        []byte
+	EncryptedSignature []byte
+	MAC                []byte
+}
+
+// SignatureInfo is the content of a signature message
+type SignatureInfo struct {
+	EncryptedSignature []byte
+	MAC                []byte
+}
+
+// DataMessageInfo is the content of a data message. The message itself is encrypted and can't be inspected
+type DataMessageInfo struct {
+	Flags           byte
+	SenderKeyID     uint32
+	RecipientKeyID  uint32
+	Y               *big.Int
+	TopHalfCounter  []byte
+	EncryptedData   []byte
+	Authenticator   []byte
+	RevealedMACKeys [][]byte
+}
+
+// Inspect parses an OTR wire message of any kind, without needing a Conversation. It never changes any state.
+// An error is returned together with the partially filled MessageInfo if the message is corrupt
+func Inspect(msg []byte) (*MessageInfo, error) {
+	info := &MessageInfo{}
+
+	switch guessMessageType(msg) {
+	case msgGuessNotOTR:
+		info.Kind = MessageKindPlaintext
+		info.Text = makeCopy(msg)
+	case msgGuessTaggedPlaintext:
+		info.Kind = MessageKindTaggedPlaintext
+		plain, versions := extractWhitespaceTag(msg)
+		info.Text = plain
+		info.Versions = versionsFromBitmask(versions)
+	case msgGuessQuery:
+		info.Kind = MessageKindQuery
+		info.Versions = parseOTRQueryMessage(msg)
+	case msgGuessError:
+		info.Kind = MessageKindError
+		info.Text = makeCopy(bytes.TrimSpace(msg[len(errorMarker):]))
+		info.ErrorCode, info.KnownErrorCode = ParseErrorMessage(msg)
+	case msgGuessFragment:
+		info.Kind = MessageKindFragment
+		return info, inspectFragment(info, msg)
+	case msgGuessV1KeyExch:
+		info.Kind = MessageKindV1KeyExchange
+		info.Version = 1
+	case msgGuessUnknown:
+		info.Kind = MessageKindUnknown
+	default:
+		return info, inspectEncoded(info, msg)
+	}
+
+	return info, nil
+}
+
+func versionsFromBitmask(versions int) []int {
+	var ret []int
+	for _, v := range []int{2, 3} {
+		if versions&(1<<uint(v)) != 0 {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func inspectFragment(info *MessageInfo, msg []byte) error {
+	rest := msg
+	if bytes.HasPrefix(msg, otrv3FragmentationPrefix) {
+		info.Version = 3
+		rest = msg[len(otrv3FragmentationPrefix):]
+
+		itagsEnd := bytes.Index(rest, fragmentSeparator)
+		if itagsEnd == -1 {
+			return errInvalidOTRMessage
+		}
+
+		itags := bytes.Split(rest[:itagsEnd], fragmentItagsSeparator)
+		if len(itags) != 2 {
+			return errInvalidOTRMessage
+		}
+
+		var err1, err2 error
+		info.SenderInstanceTag, err1 = parseItag(itags[0])
+		info.ReceiverInstanceTag, err2 = parseItag(itags[1])
+		if err1 != nil || err2 != nil {
+			return errInvalidOTRMessage
+		}
+
+		rest = rest[itagsEnd+1:]
+	} else {
+		info.Version = 2
+		rest = msg[len(otrv2FragmentationPrefix):]
+	}
+
+	data, ix, l, ok := parseFragment(rest)
+	if !ok {
+		return errInvalidOTRMessage
+	}
+
+	info.Fragment = &FragmentInfo{Index: ix, Total: l, Data: makeCopy(data)}
+	return nil
+}
+
+func inspectEncoded(info *MessageInfo, msg []byte) error {
+	if !bytes.HasSuffix(msg, []byte(".")) || len(msg) < len(msgMarker)+1 {
+		info.Kind = MessageKindUnknown
+		return errInvalidOTRMessage
+	}
+
+	decoded, err := b64decode(removeOTRMsgEnvelope(msg))
+	if err != nil {
+		info.Kind = MessageKindUnknown
+		return errInvalidOTRMessage
+	}
+
+	if len(decoded) < otrv2HeaderLen {
+		info.Kind = MessageKindUnknown
+		return errInvalidOTRMessage
+	}
+
+	_, info.Version, _ = extractShort(decoded)
+	msgType := decoded[2]
+	body := decoded[otrv2HeaderLen:]
+
+	if info.Version == 3 {
+		if len(decoded) < otrv3HeaderLen {
+			return errInvalidOTRMessage
+		}
+		_, info.SenderInstanceTag, _ = extractWord(decoded[3:])
+		_, info.ReceiverInstanceTag, _ = extractWord(decoded[7:])
+		body = decoded[otrv3HeaderLen:]
+	}
+
+	switch msgType {
+	case msgTypeDHCommit:
+		info.Kind = MessageKindDHCommit
+		m := dhCommit{}
+		if err := m.deserialize(body); err != nil {
+			return err
+		}
+		info.DHCommit = &DHCommitInfo{EncryptedGx: makeCopy(m.encryptedGx), HashedGx: makeCopy(m.hashedGx[:])}
+	case msgTypeDHKey:
+		info.Kind = MessageKindDHKey
+		m := dhKey{}
+		if err := m.deserialize(body); err != nil {
+			return err
+		}
+		info.DHKey = &DHKeyInfo{Gy: m.gy}
+	case msgTypeRevealSig:
+		info.Kind = MessageKindRevealSignature
+		m := revealSig{}
+		if err := m.deserialize(body); err != nil {
+			return err
+		}
+		info.RevealSignature = &RevealSignatureInfo{RevealedKey: makeCopy(m.r[:]), EncryptedSignature: makeCopy(m.encryptedSig), MAC: makeCopy(m.macSig)}
+	case msgTypeSig:
+		info.Kind = MessageKindSignature
+		m := sig{}
+		if err := m.deserialize(body); err != nil {
+			return err
+		}
+		info.Signature = &SignatureInfo{EncryptedSignature: makeCopy(m.encryptedSig), MAC: makeCopy(m.macSig)}
+	case msgTypeData:
+		info.Kind = MessageKindData
+		m := dataMsg{}
+		if err := m.deserialize(body); err != nil {
+			return err
+		}
+		info.Data = &DataMessageInfo{
+			Flags:          m.flag,
+			SenderKeyID:    m.senderKeyID,
+			RecipientKeyID: m.recipientKeyID,
+			Y:              m.y,
+			TopHalfCounter: makeCopy(m.topHalfCtr[:]),
+			EncryptedData:  makeCopy(m.encryptedMsg),
+			Authenticator:  makeCopy(m.authenticator[:]),
+		}
+		for _, k := range m.oldMACKeys {
+			info.Data.RevealedMACKeys = append(info.Data.RevealedMACKeys, makeCopy(k[:]))
+		}
+	default:
+		info.Kind = MessageKindUnknown
+		return newOtrErrorf("unknown message type 0x%X", msgType)
+	}
+
+	return nil
+}
+
+// String returns a human readable, multi-line description of the message
+func (m *MessageInfo) String() string {
+	var b bytes.Buffer
+
+	b.WriteString(fmt.Sprintf("Kind: %s\n", m.Kind))
+	if m.Version != 0 {
+		b.WriteString(fmt.Sprintf("  Protocol version: %d\n", m.Version))
+	}
+	if m.SenderInstanceTag != 0 || m.ReceiverInstanceTag != 0 {
+		b.WriteString(fmt.Sprintf("  Sender instance:   %08X\n", m.SenderInstanceTag))
+		b.WriteString(fmt.Sprintf("  Receiver instance: %08X\n", m.ReceiverInstanceTag))
+	}
+	if m.Text != nil {
+		b.WriteString(fmt.Sprintf("  Text: %q\n", m.Text))
+	}
+	if m.Versions != nil {
+		b.WriteString(fmt.Sprintf("  Versions: %v\n", m.Versions))
+	}
+	if m.KnownErrorCode {
+		b.WriteString(fmt.Sprintf("  Error code: %s\n", m.ErrorCode))
+	}
+
+	switch {
+	case m.Fragment != nil:
+		b.WriteString(fmt.Sprintf("  Fragment: %d of %d\n", m.Fragment.Index, m.Fragment.Total))
+		b.WriteString(fmt.Sprintf("  Data: %d bytes\n", len(m.Fragment.Data)))
+	case m.DHCommit != nil:
+		b.WriteString(fmt.Sprintf("  Encrypted g^x: %X\n", m.DHCommit.EncryptedGx))
+		b.WriteString(fmt.Sprintf("  Hashed g^x: %X\n", m.DHCommit.HashedGx))
+	case m.DHKey != nil:
+		b.WriteString(fmt.Sprintf("  g^y: %X\n", m.DHKey.Gy))
+	case m.RevealSignature != nil:
+		b.WriteString(fmt.Sprintf("  Revealed key: %X\n", m.RevealSignature.RevealedKey))
+		b.WriteString(fmt.Sprintf("  Encrypted signature: %X\n", m.RevealSignature.EncryptedSignature))
+		b.WriteString(fmt.Sprintf("  MAC: %X\n", m.RevealSignature.MAC))
+	case m.Signature != nil:
+		b.WriteString(fmt.Sprintf("  Encrypted signature: %X\n", m.Signature.EncryptedSignature))
+		b.WriteString(fmt.Sprintf("  MAC: %X\n", m.Signature.MAC))
+	case m.Data != nil:
+		b.WriteString(fmt.Sprintf("  Flags: %02X\n", m.Data.Flags))
+		b.WriteString(fmt.Sprintf("  Sender keyid:    %d\n", m.Data.SenderKeyID))
+		b.WriteString(fmt.Sprintf("  Recipient keyid: %d\n", m.Data.RecipientKeyID))
+		b.WriteString(fmt.Sprintf("  Next DH key: %X\n", m.Data.Y))
+		b.WriteString(fmt.Sprintf("  Counter: %X\n", m.Data.TopHalfCounter))
+		b.WriteString(fmt.Sprintf("  Encrypted data: %d bytes\n", len(m.Data.EncryptedData)))
+		b.WriteString(fmt.Sprintf("  Authenticator: %X\n", m.Data.Authenticator))
+		b.WriteString(fmt.Sprintf("  Revealed MAC keys: %d\n", len(m.Data.RevealedMACKeys)))
+		for _, k := range m.Data.RevealedMACKeys {
+			b.WriteString(fmt.Sprintf("    %X\n", k))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,186 @@
+package otr3
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func inspectAKE() (alice, bob *Conversation, msgs []ValidMessage) {
+	alice, bob = newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+
+	_, dhCommit, _ := bob.Receive(alice.QueryMessage())
+	_, dhKey, _ := alice.Receive(dhCommit[0])
+	_, revealSig, _ := bob.Receive(dhKey[0])
+	_, sig, _ := alice.Receive(revealSig[0])
+	bob.Receive(sig[0])
+
+	return alice, bob, []ValidMessage{dhCommit[0], dhKey[0], revealSig[0], sig[0]}
+}
+
+func Test_Inspect_plaintext(t *testing.T) {
+	info, err := Inspect([]byte("hello"))
+
+	assertNil(t, err)
+	assertEquals(t, info.Kind, MessageKindPlaintext)
+	assertDeepEquals(t, info.Text, []byte("hello"))
+}
+
+func Test_Inspect_taggedPlaintext(t *testing.T) {
+	msg := append([]byte("hello"), genWhitespaceTag(policies(allowV2|allowV3))...)
+
+	info, err := Inspect(msg)
+
+	assertNil(t, err)
+	assertEquals(t, info.Kind, MessageKindTaggedPlaintext)
+	assertDeepEquals(t, info.Text, []byte("hello"))
+	assertDeepEquals(t, info.Versions, []int{2, 3})
+}
+
+func Test_Inspect_query(t *testing.T) {
+	info, err := Inspect([]byte("?OTR?v23?"))
+
+	assertNil(t, err)
+	assertEquals(t, info.Kind, MessageKindQuery)
+	assertDeepEquals(t, info.Versions, []int{1, 2, 3})
+}
+
+func Test_Inspect_errorMessage(t *testing.T) {
+	info, err := Inspect([]byte("?OTR Error: You transmitted a malformed data message."))
+
+	assertNil(t, err)
+	assertEquals(t, info.Kind, MessageKindError)
+	assertDeepEquals(t, info.Text, []byte("You transmitted a malformed data message."))
+	assertEquals(t, info.KnownErrorCode, true)
+	assertEquals(t, info.ErrorCode, ErrorCodeMessageMalformed)
+}
+
+func Test_Inspect_AKEMessages(t *testing.T) {
+	alice, bob, msgs := inspectAKE()
+
+	kinds := []MessageKind{MessageKindDHCommit, MessageKindDHKey, MessageKindRevealSignature, MessageKindSignature}
+	senders := []*Conversation{bob, alice, bob, alice}
+	for i, m := range msgs {
+		info, err := Inspect(m)
+
+		assertNil(t, err)
+		assertEquals(t, info.Kind, kinds[i])
+		assertEquals(t, info.Version, uint16(3))
+		assertEquals(t, info.SenderInstanceTag, senders[i].ourInstanceTag)
+	}
+
+	info, _ := Inspect(msgs[0])
+	assertEquals(t, len(info.DHCommit.HashedGx), 32)
+	info, _ = Inspect(msgs[1])
+	assertEquals(t, isGroupElement(info.DHKey.Gy), true)
+	info, _ = Inspect(msgs[2])
+	assertEquals(t, len(info.RevealSignature.RevealedKey), 16)
+	assertEquals(t, len(info.RevealSignature.MAC), 20)
+	info, _ = Inspect(msgs[3])
+	assertEquals(t, len(info.Signature.MAC), 20)
+}
+
+func Test_Inspect_dataMessage(t *testing.T) {
+	alice, bob, _ := inspectAKE()
+	toSend, _ := alice.Send(ValidMessage("hello"))
+
+	info, err := Inspect(toSend[0])
+
+	assertNil(t, err)
+	assertEquals(t, info.Kind, MessageKindData)
+	assertEquals(t, info.SenderInstanceTag, alice.ourInstanceTag)
+	assertEquals(t, info.ReceiverInstanceTag, bob.ourInstanceTag)
+	assertEquals(t, info.Data.SenderKeyID, alice.keys.ourKeyID-1)
+	assertEquals(t, info.Data.RecipientKeyID, alice.keys.theirKeyID)
+	assertEquals(t, info.Data.Y.Cmp(alice.keys.ourCurrentDHKeys.pub), 0)
+	assertEquals(t, len(info.Data.Authenticator), 20)
+}
+
+func Test_Inspect_fragments(t *testing.T) {
+	alice, _, _ := inspectAKE()
+	alice.SetFragmentSize(100)
+	toSend, _ := alice.Send(ValidMessage("hello"))
+
+	info, err := Inspect(toSend[1])
+
+	assertNil(t, err)
+	assertEquals(t, info.Kind, MessageKindFragment)
+	assertEquals(t, info.Version, uint16(3))
+	assertEquals(t, info.SenderInstanceTag, alice.ourInstanceTag)
+	assertEquals(t, info.Fragment.Index, uint16(2))
+	assertEquals(t, info.Fragment.Total, uint16(len(toSend)))
+
+	info, err = Inspect([]byte("?OTR,00001,00002,?OTR:AAID,"))
+
+	assertNil(t, err)
+	assertEquals(t, info.Version, uint16(2))
+	assertDeepEquals(t, info.Fragment, &FragmentInfo{Index: 1, Total: 2, Data: []byte("?OTR:AAID")})
+}
+
+func Test_Inspect_returnsAnErrorForCorruptMessages(t *testing.T) {
+	_, err := Inspect([]byte("?OTR:AAMC!!!."))
+	assertEquals(t, err, errInvalidOTRMessage)
+
+	_, err = Inspect([]byte("?OTR:AAMC"))
+	assertEquals(t, err, errInvalidOTRMessage)
+
+	info, err := Inspect([]byte("?OTR:AAMCAAABAAAAAAA=."))
+	assertEquals(t, info.Kind, MessageKindDHCommit)
+	assertEquals(t, err, newOtrError("corrupt DH commit message"))
+
+	_, err = Inspect([]byte("?OTR|00000100,00001,00002,data,"))
+	assertEquals(t, err, errInvalidOTRMessage)
+}
+
+func Test_Inspect_returnsAnErrorForTruncatedMessages(t *testing.T) {
+	alice, _, msgs := inspectAKE()
+	data, _ := alice.Send(ValidMessage("hello"))
+	msgs = append(msgs, data[0])
+
+	for _, m := range msgs {
+		decoded, _ := b64decode(removeOTRMsgEnvelope(encodedMessage(m)))
+
+		for l := otrv3HeaderLen; l < len(decoded); l++ {
+			truncated := append(append(makeCopy(msgMarker), b64encode(decoded[:l])...), '.')
+
+			_, err := Inspect(truncated)
+
+			if err == nil {
+				t.Errorf("expected an error for %q truncated to %d bytes", m, l)
+			}
+		}
+	}
+}
+
+func Test_Inspect_returnsAnErrorForADataMessageWithATruncatedAuthenticator(t *testing.T) {
+	decoded, _ := hex.DecodeString("0002030a0083fbe10029660e00000005450000b0418d70288c9cca00ea0000002600cac34c006c00063d2143a7082d429483ffb74cafa642889a8ab617fcf64616a3f824ffcc6bfa")
+
+	info, err := Inspect(append(append(makeCopy(msgMarker), b64encode(decoded)...), '.'))
+
+	assertEquals(t, info.Kind, MessageKindData)
+	assertEquals(t, err, newOtrError("dataMsg.deserialize corrupted authenticator"))
+}
+
+func Test_Inspect_unsupportedMessages(t *testing.T) {
+	info, _ := Inspect([]byte("?OTR:AAEK"))
+	assertEquals(t, info.Kind, MessageKindV1KeyExchange)
+
+	info, _ = Inspect([]byte("?OTRx"))
+	assertEquals(t, info.Kind, MessageKindUnknown)
+}
+
+func Test_MessageInfo_String_describesTheMessage(t *testing.T) {
+	alice, _, _ := inspectAKE()
+	toSend, _ := alice.Send(ValidMessage("hello"))
+	info, _ := Inspect(toSend[0])
+
+	dump := info.String()
+
+	assertEquals(t, strings.HasPrefix(dump, "Kind: MessageKindData\n  Protocol version: 3\n"), true)
+	assertEquals(t, strings.Contains(dump, "  Sender keyid:    1\n"), true)
+}
+
+func Test_MessageKind_String(t *testing.T) {
+	assertEquals(t, MessageKindRevealSignature.String(), "MessageKindRevealSignature")
+	assertEquals(t, MessageKind(42).String(), "MESSAGE KIND: (THIS SHOULD NEVER HAPPEN)")
+}
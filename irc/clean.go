@@ -0,0 +1,94 @@
+package irc
+
+import "strings"
+
+const (
+	ctcpDelimiter   = '\x01'
+	formatBold      = '\x02'
+	formatColor     = '\x03'
+	formatHexColor  = '\x04'
+	formatReset     = '\x0F'
+	formatMonospace = '\x11'
+	formatReverse   = '\x16'
+	formatItalics   = '\x1D'
+	formatStrike    = '\x1E'
+	formatUnderline = '\x1F'
+)
+
+// ctcpAction is the only CTCP command whose text is shown to the user
+const ctcpAction = "ACTION "
+
+// Clean removes the formatting codes from a message and unwraps CTCP ACTION messages.
+// It returns false for all other CTCP messages, which should not reach the conversation
+func Clean(text string) (string, bool) {
+	if strings.HasPrefix(text, string(ctcpDelimiter)) {
+		inner := strings.TrimSuffix(text[1:], string(ctcpDelimiter))
+		if !strings.HasPrefix(inner, ctcpAction) {
+			return "", false
+		}
+		text = inner[len(ctcpAction):]
+	}
+
+	return stripFormatting(text), true
+}
+
+func stripFormatting(text string) string {
+	if strings.IndexFunc(text, isFormattingCode) == -1 {
+		return text
+	}
+
+	out := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case formatColor:
+			i = skipColor(text, i, isDigit, 2)
+		case formatHexColor:
+			i = skipColor(text, i, isHexDigit, 6)
+		case ctcpDelimiter, formatBold, formatReset, formatMonospace, formatReverse, formatItalics, formatStrike, formatUnderline:
+		default:
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
+
+func isFormattingCode(r rune) bool {
+	switch r {
+	case ctcpDelimiter, formatBold, formatColor, formatHexColor, formatReset, formatMonospace, formatReverse, formatItalics, formatStrike, formatUnderline:
+		return true
+	}
+	return false
+}
+
+// skipColor skips the optional "foreground[,background]" arguments of a color code at position i,
+// returning the position of the last byte consumed
+func skipColor(text string, i int, valid func(byte) bool, max int) int {
+	n := countValid(text, i+1, valid, max)
+	if n == 0 {
+		return i
+	}
+	i += n
+
+	if i+1 < len(text) && text[i+1] == ',' {
+		if m := countValid(text, i+2, valid, max); m > 0 {
+			i += 1 + m
+		}
+	}
+	return i
+}
+
+func countValid(text string, from int, valid func(byte) bool, max int) int {
+	n := 0
+	for from+n < len(text) && n < max && valid(text[from+n]) {
+		n++
+	}
+	return n
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,39 @@
+package irc
+
+import "testing"
+
+func Test_Clean_stripsFormattingCodes(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"?OTR:AAMD.", "?OTR:AAMD."},
+		{"\x02bold\x02 \x1Ditalic\x1D \x1Funder\x1F\x0F", "bold italic under"},
+		{"\x0304red\x03 \x0312,01blue\x03", "red blue"},
+		{"\x033,5x\x03,5", "x,5"},
+		{"\x04FF0000red\x04 \x04ff0000,00FF00mix", "red mix"},
+		{"\x16rev\x11mono\x1Estrike", "revmonostrike"},
+		{"?OTR|5a73a599|27e31597,00001,00002,?OTR:\x02AAMD,", "?OTR|5a73a599|27e31597,00001,00002,?OTR:AAMD,"},
+	}
+
+	for _, tt := range tests {
+		out, ok := Clean(tt.in)
+		if !ok || out != tt.out {
+			t.Errorf("Clean(%q) = %q, %v; want %q", tt.in, out, ok, tt.out)
+		}
+	}
+}
+
+func Test_Clean_unwrapsCTCPActions(t *testing.T) {
+	out, ok := Clean("\x01ACTION waves\x01")
+	if !ok || out != "waves" {
+		t.Errorf("unexpected result %q %v", out, ok)
+	}
+}
+
+func Test_Clean_rejectsOtherCTCPMessages(t *testing.T) {
+	for _, s := range []string{"\x01VERSION\x01", "\x01PING 12345\x01"} {
+		if _, ok := Clean(s); ok {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package irc carries OTR conversations over IRC. It computes fragment sizes that fit in the 512 byte line limit,
+// turns the messages generated by an otr3.Conversation into PRIVMSG or NOTICE lines, and feeds received lines
+// back to the conversation after removing the CTCP and formatting codes that would otherwise corrupt them.
+// Notices are never answered automatically, so OTR messages are only exchanged in PRIVMSG lines.
+package irc
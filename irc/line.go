@@ -0,0 +1,89 @@
+package irc
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// MaxLineLength is the maximum length of an IRC line, including the trailing CR LF
+	MaxLineLength = 512
+	// MaxUserHostLength is the space reserved for the "!user@host" part of the prefix the server adds
+	// to our messages before relaying them. We never know it for sure, so we assume the longest common values
+	MaxUserHostLength = 1 + 10 + 1 + 63
+)
+
+var errEmptyLine = errors.New("irc: empty line")
+
+// Line is a parsed IRC line
+type Line struct {
+	// Prefix is the source of the line, without the leading colon
+	Prefix  string
+	Command string
+	Params  []string
+}
+
+// Nick returns the nickname part of the prefix
+func (l *Line) Nick() string {
+	if i := strings.IndexAny(l.Prefix, "!@"); i != -1 {
+		return l.Prefix[:i]
+	}
+	return l.Prefix
+}
+
+// ParseLine parses a line received from an IRC server. The trailing CR LF is optional
+func ParseLine(s string) (*Line, error) {
+	s = strings.TrimRight(s, "\r\n")
+	l := &Line{}
+
+	if strings.HasPrefix(s, ":") {
+		i := strings.IndexByte(s, ' ')
+		if i == -1 {
+			return nil, errEmptyLine
+		}
+		l.Prefix, s = s[1:i], strings.TrimLeft(s[i:], " ")
+	}
+
+	for s != "" {
+		if strings.HasPrefix(s, ":") {
+			l.Params = append(l.Params, s[1:])
+			break
+		}
+
+		var param string
+		if i := strings.IndexByte(s, ' '); i != -1 {
+			param, s = s[:i], strings.TrimLeft(s[i:], " ")
+		} else {
+			param, s = s, ""
+		}
+
+		if l.Command == "" {
+			l.Command = strings.ToUpper(param)
+		} else {
+			l.Params = append(l.Params, param)
+		}
+	}
+
+	if l.Command == "" {
+		return nil, errEmptyLine
+	}
+
+	return l, nil
+}
+
+// FormatMessage returns the line, without CR LF, that sends the text to the target with the given command
+func FormatMessage(command, target, text string) string {
+	return command + " " + target + " :" + text
+}
+
+// FragmentSize returns the largest OTR fragment that can be sent to the target with the given command
+// without the line being truncated when the server relays it with our nickname as prefix
+func FragmentSize(nick, command, target string) int {
+	// ":nick!user@host COMMAND target :fragment\r\n"
+	overhead := 1 + len(nick) + MaxUserHostLength + 1 + len(command) + 1 + len(target) + 2 + 2
+	size := MaxLineLength - overhead
+	if size < 0 {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,56 @@
+package irc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ParseLine_parsesAPrivmsg(t *testing.T) {
+	l, err := ParseLine(":bob!~bob@example.com PRIVMSG alice :?OTR:AAMD hello\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Prefix != "bob!~bob@example.com" || l.Nick() != "bob" || l.Command != "PRIVMSG" {
+		t.Errorf("unexpected line: %#v", l)
+	}
+	if !reflect.DeepEqual(l.Params, []string{"alice", "?OTR:AAMD hello"}) {
+		t.Errorf("unexpected params: %q", l.Params)
+	}
+}
+
+func Test_ParseLine_parsesLinesWithoutPrefixOrTrailing(t *testing.T) {
+	l, err := ParseLine("ping server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Prefix != "" || l.Command != "PING" || !reflect.DeepEqual(l.Params, []string{"server1"}) {
+		t.Errorf("unexpected line: %#v", l)
+	}
+}
+
+func Test_ParseLine_returnsErrorForEmptyLines(t *testing.T) {
+	for _, s := range []string{"", "\r\n", ":prefix", ":prefix "} {
+		if _, err := ParseLine(s); err != errEmptyLine {
+			t.Errorf("expected errEmptyLine for %q, got %v", s, err)
+		}
+	}
+}
+
+func Test_FragmentSize_leavesRoomForTheRelayedPrefix(t *testing.T) {
+	nick, target := "alice", "bob"
+	size := FragmentSize(nick, CommandPrivmsg, target)
+
+	fragment := make([]byte, size)
+	relayed := ":" + nick + "!" + string(make([]byte, MaxUserHostLength-2)) + "@" + " " + FormatMessage(CommandPrivmsg, target, string(fragment)) + "\r\n"
+	if len(relayed) != MaxLineLength {
+		t.Errorf("expected the relayed line to be exactly %d bytes, got %d", MaxLineLength, len(relayed))
+	}
+}
+
+func Test_FragmentSize_returnsZeroWhenNothingFits(t *testing.T) {
+	if s := FragmentSize(string(make([]byte, 500)), CommandPrivmsg, "bob"); s != 0 {
+		t.Errorf("expected 0, got %d", s)
+	}
+}
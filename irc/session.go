@@ -0,0 +1,145 @@
+package irc
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/twstrike/otr3"
+)
+
+const (
+	// CommandPrivmsg is the command used for normal messages
+	CommandPrivmsg = "PRIVMSG"
+	// CommandNotice is the command used for notices, which clients must never reply to automatically.
+	// Notices can only carry plain text: the OTR messages in received notices are ignored
+	CommandNotice = "NOTICE"
+)
+
+// Session sends and receives the lines of an OTR conversation with one IRC user
+type Session struct {
+	conv    *otr3.Conversation
+	nick    string
+	target  string
+	command string
+}
+
+// NewSession returns a session for the conversation between our nick and the target, sending PRIVMSG lines.
+// It sets the fragment size of the conversation so that every line fits in an IRC line
+func NewSession(c *otr3.Conversation, nick, target string) *Session {
+	s := &Session{conv: c, nick: nick, target: target}
+	s.SetCommand(CommandPrivmsg)
+	return s
+}
+
+// Conversation returns the conversation of the session
+func (s *Session) Conversation() *otr3.Conversation {
+	return s.conv
+}
+
+// SetNick updates our nick, for example after a NICK change, and the fragment size that depends on it
+func (s *Session) SetNick(nick string) {
+	s.nick = nick
+	s.updateFragmentSize()
+}
+
+// SetCommand sets the command, PRIVMSG or NOTICE, used for the lines sent. Since the OTR messages
+// in notices are ignored when they are received, a session sending notices can't start or continue an
+// encrypted conversation, and should only be used to send plain text
+func (s *Session) SetCommand(command string) {
+	s.command = command
+	s.updateFragmentSize()
+}
+
+func (s *Session) updateFragmentSize() {
+	s.conv.SetFragmentSize(uint16(FragmentSize(s.nick, s.command, s.target)))
+}
+
+// Send returns the lines, without CR LF, to send for the message typed by the user
+func (s *Session) Send(text string) ([]string, error) {
+	toSend, err := s.conv.Send(otr3.ValidMessage(text))
+	return s.Lines(toSend), err
+}
+
+// Lines returns the lines to send for messages created outside of Send, for example by
+// QueryMessage, StartAuthenticate or End. Messages containing newlines are split in several lines,
+// and lines that don't fit in an IRC line, like long plaintext or query messages, are split as well
+func (s *Session) Lines(msgs []otr3.ValidMessage) []string {
+	size := FragmentSize(s.nick, s.command, s.target)
+
+	var ret []string
+	for _, m := range msgs {
+		for _, l := range strings.Split(strings.Replace(string(m), "\r\n", "\n", -1), "\n") {
+			for _, part := range splitLine(l, size) {
+				ret = append(ret, FormatMessage(s.command, s.target, part))
+			}
+		}
+	}
+	return ret
+}
+
+// splitLine splits the text in parts of at most size bytes, without splitting UTF-8 characters
+func splitLine(text string, size int) []string {
+	var ret []string
+	for len(text) > size && size > 0 {
+		n := size
+		for n > 0 && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		if n == 0 {
+			n = size
+		}
+		ret = append(ret, text[:n])
+		text = text[n:]
+	}
+
+	if text != "" {
+		ret = append(ret, text)
+	}
+	return ret
+}
+
+// Receive processes a line received from the server. Lines that aren't PRIVMSG or NOTICE messages
+// sent by the target to our nick, like channel messages, and CTCP requests other than ACTION, are ignored.
+// It returns the text to show to the user, if any, and the lines to send back. Since clients must never
+// reply to a NOTICE automatically, only the plain text of notices is used, and their OTR messages are
+// ignored instead of changing the state of the conversation without an answer
+func (s *Session) Receive(line string) (text string, replies []string, err error) {
+	l, err := ParseLine(line)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if (l.Command != CommandPrivmsg && l.Command != CommandNotice) || len(l.Params) < 2 ||
+		!strings.EqualFold(l.Params[0], s.nick) || !strings.EqualFold(l.Nick(), s.target) {
+		return "", nil, nil
+	}
+
+	msg, ok := Clean(l.Params[1])
+	if !ok {
+		return "", nil, nil
+	}
+
+	if l.Command == CommandNotice {
+		return s.receiveNotice(msg)
+	}
+
+	plain, toSend, err := s.conv.Receive(otr3.ValidMessage(msg))
+	return string(plain), s.Lines(toSend), err
+}
+
+func (s *Session) receiveNotice(msg string) (text string, replies []string, err error) {
+	info, err := otr3.Inspect([]byte(msg))
+	if err != nil {
+		return "", nil, nil
+	}
+
+	switch info.Kind {
+	case otr3.MessageKindPlaintext:
+		plain, _, err := s.conv.Receive(otr3.ValidMessage(msg))
+		return string(plain), nil, err
+	case otr3.MessageKindTaggedPlaintext:
+		return string(info.Text), nil, nil
+	}
+
+	return "", nil, nil
+}
@@ -0,0 +1,198 @@
+package irc
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/twstrike/otr3"
+)
+
+func newTestSession(nick, target string) *Session {
+	k := &otr3.PrivateKey{}
+	k.Generate(rand.Reader)
+
+	c := &otr3.Conversation{}
+	c.Policies.AllowV2()
+	c.Policies.AllowV3()
+	c.SetKeys(k, nil)
+	return NewSession(c, nick, target)
+}
+
+// relay turns lines sent by the given nick into the lines the server delivers to the peer
+func relay(nick string, lines []string) []string {
+	var ret []string
+	for _, l := range lines {
+		ret = append(ret, ":"+nick+"!~"+nick+"@host.example.com "+l+"\r\n")
+	}
+	return ret
+}
+
+func exchange(t *testing.T, from, to *Session, lines []string) []string {
+	var texts []string
+	for len(lines) > 0 {
+		var replies []string
+		for _, l := range relay(from.nick, lines) {
+			if len(l) > MaxLineLength {
+				t.Fatalf("line too long: %d bytes", len(l))
+			}
+
+			text, r, err := to.Receive(l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != "" {
+				texts = append(texts, text)
+			}
+			replies = append(replies, r...)
+		}
+		lines = replies
+		from, to = to, from
+	}
+	return texts
+}
+
+func Test_Session_establishesAnEncryptedConversationWithFragmentedLines(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+	bob := newTestSession("bob", "alice")
+
+	exchange(t, alice, bob, alice.Lines([]otr3.ValidMessage{alice.Conversation().QueryMessage()}))
+
+	if !alice.Conversation().IsEncrypted() || !bob.Conversation().IsEncrypted() {
+		t.Fatalf("expected both sides to be encrypted")
+	}
+
+	long := strings.Repeat("a long message ", 60)
+	lines, err := alice.Send(long)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) < 2 {
+		t.Errorf("expected the message to be fragmented, got %d lines", len(lines))
+	}
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "PRIVMSG bob :?OTR") {
+			t.Errorf("unexpected line: %q", l)
+		}
+	}
+
+	texts := exchange(t, alice, bob, lines)
+	if len(texts) != 1 || texts[0] != long {
+		t.Errorf("unexpected received texts: %q", texts)
+	}
+}
+
+func Test_Session_SetCommand_sendsNotices(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+	alice.SetCommand(CommandNotice)
+
+	lines, _ := alice.Send("hello")
+	if len(lines) != 1 || lines[0] != "NOTICE bob :hello" {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+}
+
+func Test_Session_Lines_splitsMessagesWithNewlines(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+
+	lines := alice.Lines([]otr3.ValidMessage{otr3.ValidMessage("one\r\ntwo\n\nthree")})
+	expected := []string{"PRIVMSG bob :one", "PRIVMSG bob :two", "PRIVMSG bob :three"}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+}
+
+func Test_Session_Receive_ignoresOtherLines(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+
+	for _, l := range []string{
+		"PING :server",
+		":carol!c@host PRIVMSG alice :hello",
+		":bob!b@host JOIN #otr",
+		":bob!b@host PRIVMSG alice :\x01VERSION\x01",
+		":bob!b@host PRIVMSG #chan :?OTRv23?",
+		":bob!b@host NOTICE #chan :hello",
+	} {
+		text, replies, err := alice.Receive(l)
+		if text != "" || replies != nil || err != nil {
+			t.Errorf("expected %q to be ignored, got %q %q %v", l, text, replies, err)
+		}
+	}
+}
+
+func Test_Session_Receive_matchesOurNickIgnoringCase(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+
+	text, _, err := alice.Receive(":bob!b@host PRIVMSG Alice :hello")
+	if err != nil || text != "hello" {
+		t.Errorf("unexpected result %q %v", text, err)
+	}
+}
+
+func Test_Session_Receive_ignoresOTRMessagesInNotices(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+	bob := newTestSession("bob", "alice")
+
+	query := bob.Conversation().QueryMessage()
+	text, replies, err := alice.Receive(":bob!b@host NOTICE alice :" + string(query))
+	if text != "" || replies != nil || err != nil {
+		t.Errorf("expected the query in a notice to be ignored, got %q %q %v", text, replies, err)
+	}
+
+	_, replies, _ = alice.Receive(":bob!b@host PRIVMSG alice :" + string(query))
+	if len(replies) == 0 {
+		t.Errorf("expected replies to a PRIVMSG")
+	}
+}
+
+func Test_Session_Receive_returnsThePlainTextOfNotices(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+
+	text, replies, err := alice.Receive(":bob!b@host NOTICE alice :hello \t  \t\t\t\t \t \t \t    \t\t  \t\t")
+	if text != "hello" || replies != nil || err != nil {
+		t.Errorf("unexpected result %q %q %v", text, replies, err)
+	}
+}
+
+func Test_Session_withNoticesOnBothSidesOnlyExchangesPlainText(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+	bob := newTestSession("bob", "alice")
+	alice.SetCommand(CommandNotice)
+	bob.SetCommand(CommandNotice)
+
+	texts := exchange(t, alice, bob, alice.Lines([]otr3.ValidMessage{alice.Conversation().QueryMessage()}))
+	if len(texts) != 0 || alice.Conversation().IsEncrypted() || bob.Conversation().IsEncrypted() {
+		t.Errorf("expected the query to be ignored, got %q", texts)
+	}
+
+	lines, _ := bob.Send("hello")
+	texts = exchange(t, bob, alice, lines)
+	if len(texts) != 1 || texts[0] != "hello" {
+		t.Errorf("unexpected received texts: %q", texts)
+	}
+}
+
+func Test_Session_Lines_splitsLinesThatDontFitInAnIRCLine(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+	bob := newTestSession("bob", "alice")
+	long := strings.Repeat("ä long message ", 60)
+
+	lines := alice.Lines([]otr3.ValidMessage{otr3.ValidMessage(long)})
+	if len(lines) < 2 {
+		t.Errorf("expected the message to be split, got %d lines", len(lines))
+	}
+
+	texts := exchange(t, alice, bob, lines)
+	if strings.Join(texts, "") != long {
+		t.Errorf("unexpected received texts: %q", texts)
+	}
+}
+
+func Test_Session_Receive_stripsFormatting(t *testing.T) {
+	alice := newTestSession("alice", "bob")
+
+	text, _, err := alice.Receive(":bob!b@host PRIVMSG alice :\x02hi\x02 there")
+	if err != nil || text != "hi there" {
+		t.Errorf("unexpected result %q %v", text, err)
+	}
+}
@@ -0,0 +1,135 @@
+package otr3
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// KeyChangeHandler is notified when the long-term key of the peer differs from the one seen before.
+// Changes during a secure session are also signalled with the KeyChangedMidSession security event
+type KeyChangeHandler interface {
+	// HandleKeyChange is called after an AKE that starts or refreshes a secure session with a key that differs from the previously seen one
+	HandleKeyChange(oldFingerprint, newFingerprint []byte)
+}
+
+type dynamicKeyChangeHandler struct {
+	eh func(oldFingerprint, newFingerprint []byte)
+}
+
+func (d dynamicKeyChangeHandler) HandleKeyChange(oldFingerprint, newFingerprint []byte) {
+	d.eh(oldFingerprint, newFingerprint)
+}
+
+// DebugKeyChangeHandler is a KeyChangeHandler that dumps all key changes to standard error
+type DebugKeyChangeHandler struct{}
+
+// HandleKeyChange dumps the key change
+func (DebugKeyChangeHandler) HandleKeyChange(oldFingerprint, newFingerprint []byte) {
+	fmt.Fprintf(standardErrorOutput, "%sHandleKeyChange(%X, %X)\n", debugPrefix, oldFingerprint, newFingerprint)
+}
+
+// PreviousKeyLookup remembers the key used by the peer of a conversation, so key changes can be detected across conversations
+type PreviousKeyLookup interface {
+	// PreviousFingerprint returns the fingerprint of the last key seen for the peer, or nil if none has been seen
+	PreviousFingerprint() []byte
+	// RememberFingerprint is called with the fingerprint of the peer's key after every successful AKE
+	RememberFingerprint(fingerprint []byte)
+}
+
+// MemoryKeyHistory keeps the last fingerprint seen for each peer in memory. It is safe for concurrent use,
+// and can be shared between all conversations of an application
+type MemoryKeyHistory struct {
+	sync.Mutex
+	fingerprints map[string][]byte
+}
+
+// NewMemoryKeyHistory creates an empty MemoryKeyHistory
+func NewMemoryKeyHistory() *MemoryKeyHistory {
+	return &MemoryKeyHistory{fingerprints: make(map[string][]byte)}
+}
+
+// ForPeer returns a PreviousKeyLookup for the peer with the given identifier
+func (h *MemoryKeyHistory) ForPeer(peer string) PreviousKeyLookup {
+	return peerKeyHistory{h, peer}
+}
+
+type peerKeyHistory struct {
+	h    *MemoryKeyHistory
+	peer string
+}
+
+func (p peerKeyHistory) PreviousFingerprint() []byte {
+	p.h.Lock()
+	defer p.h.Unlock()
+	fp, ok := p.h.fingerprints[p.peer]
+	if !ok {
+		return nil
+	}
+	return makeCopy(fp)
+}
+
+func (p peerKeyHistory) RememberFingerprint(fingerprint []byte) {
+	p.h.Lock()
+	defer p.h.Unlock()
+	p.h.fingerprints[p.peer] = makeCopy(fingerprint)
+}
+
+// SetKeyChangeHandler assigns handler for key changes
+func (c *Conversation) SetKeyChangeHandler(handler KeyChangeHandler) {
+	c.keyChangeHandler = handler
+}
+
+// SetPreviousKeyLookup assigns the lookup used to find the key the peer used in earlier conversations
+func (c *Conversation) SetPreviousKeyLookup(l PreviousKeyLookup) {
+	c.previousKeyLookup = l
+}
+
+func (c *Conversation) previousFingerprint() []byte {
+	if c.lastTheirFingerprint != nil {
+		return c.lastTheirFingerprint
+	}
+
+	if c.previousKeyLookup != nil {
+		return c.previousKeyLookup.PreviousFingerprint()
+	}
+
+	return nil
+}
+
+// checkForKeyChange compares the key of the peer established by the last AKE with the one seen before, and remembers it.
+// Every change is reported to the KeyChangeHandler and subscribers. It returns true if the key changed during a secure session,
+// which is also signalled as KeyChangedMidSession
+func (c *Conversation) checkForKeyChange(refreshed bool) (changedMidSession bool) {
+	if c.theirKey == nil {
+		return false
+	}
+
+	newFingerprint := c.theirKey.DefaultFingerprint()
+	oldFingerprint := c.previousFingerprint()
+
+	c.lastTheirFingerprint = newFingerprint
+	if c.previousKeyLookup != nil {
+		c.previousKeyLookup.RememberFingerprint(newFingerprint)
+	}
+
+	if oldFingerprint == nil || bytes.Equal(oldFingerprint, newFingerprint) {
+		return false
+	}
+
+	if refreshed {
+		c.log(LogLevelWarn, "peer key changed during secure session")
+	} else {
+		c.log(LogLevelWarn, "peer key changed")
+	}
+
+	c.keyChanged(oldFingerprint, newFingerprint)
+	return refreshed
+}
+
+func (c *Conversation) keyChanged(oldFingerprint, newFingerprint []byte) {
+	c.publishKeyChange(oldFingerprint, newFingerprint)
+	if c.keyChangeHandler != nil {
+		c.keyChangeHandler.HandleKeyChange(oldFingerprint, newFingerprint)
+	}
+}
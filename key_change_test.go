@@ -0,0 +1,126 @@
+package otr3
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+type keyChange struct {
+	old, new []byte
+}
+
+func (c *Conversation) collectKeyChanges() *[]keyChange {
+	changes := []keyChange{}
+	c.SetKeyChangeHandler(dynamicKeyChangeHandler{func(old, new []byte) {
+		changes = append(changes, keyChange{old, new})
+	}})
+	return &changes
+}
+
+func Test_checkForKeyChange_doesNotSignalForTheSameKey(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	changes := alice.collectKeyChanges()
+
+	performAKE(alice, bob)
+	alice.End()
+	bob.End()
+	performAKE(alice, bob)
+
+	assertEquals(t, len(*changes), 0)
+}
+
+func Test_checkForKeyChange_signalsWhenThePeerUsesAnotherKeyInANewSession(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	changes := alice.collectKeyChanges()
+	performAKE(alice, bob)
+	alice.End()
+	bob.End()
+
+	otherKey := &PrivateKey{}
+	otherKey.Generate(rand.Reader)
+	bob.ourKey = otherKey
+	performAKE(alice, bob)
+
+	assertEquals(t, len(*changes), 1)
+	assertDeepEquals(t, (*changes)[0].old, bobPrivateKey.PublicKey.DefaultFingerprint())
+	assertDeepEquals(t, (*changes)[0].new, otherKey.PublicKey.DefaultFingerprint())
+}
+
+func Test_checkForKeyChange_usesThePreviousKeyLookupAcrossConversations(t *testing.T) {
+	history := NewMemoryKeyHistory()
+	history.ForPeer("bob@example.com").RememberFingerprint(alicePrivateKey.PublicKey.DefaultFingerprint())
+
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	alice.SetPreviousKeyLookup(history.ForPeer("bob@example.com"))
+	changes := alice.collectKeyChanges()
+	s := alice.Subscribe()
+	defer alice.Unsubscribe(s)
+
+	performAKE(alice, bob)
+
+	assertEquals(t, len(*changes), 1)
+	assertDeepEquals(t, (*changes)[0].old, alicePrivateKey.PublicKey.DefaultFingerprint())
+	assertDeepEquals(t, history.ForPeer("bob@example.com").PreviousFingerprint(), bobPrivateKey.PublicKey.DefaultFingerprint())
+	assertNil(t, history.ForPeer("charlie@example.com").PreviousFingerprint())
+
+	for {
+		if e, ok := receiveEvent(t, s).(KeyChangeNotification); ok {
+			assertDeepEquals(t, e.NewFingerprint, bobPrivateKey.PublicKey.DefaultFingerprint())
+			break
+		}
+	}
+}
+
+func Test_checkForKeyChange_reportsAChangeDuringASecureSessionOnlyOnce(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+
+	changes := alice.collectKeyChanges()
+	events := alice.collectSecurityEvents()
+	logs := alice.collectLogs()
+	s := alice.Subscribe()
+	defer alice.Unsubscribe(s)
+
+	otherKey := &PrivateKey{}
+	otherKey.Generate(rand.Reader)
+	bob.ourKey = otherKey
+	performAKE(alice, bob)
+
+	assertDeepEquals(t, *changes, []keyChange{keyChange{bobPrivateKey.PublicKey.DefaultFingerprint(), otherKey.PublicKey.DefaultFingerprint()}})
+	assertDeepEquals(t, *events, []SecurityEvent{StillSecure, KeyChangedMidSession})
+
+	for {
+		if kc, ok := receiveEvent(t, s).(KeyChangeNotification); ok {
+			assertDeepEquals(t, kc.NewFingerprint, otherKey.PublicKey.DefaultFingerprint())
+			break
+		}
+	}
+
+	warnings := 0
+	for _, l := range *logs {
+		if l.level == LogLevelWarn {
+			warnings++
+			assertEquals(t, l.message, "peer key changed during secure session")
+		}
+	}
+	assertEquals(t, warnings, 1)
+	assertDeepEquals(t, alice.lastTheirFingerprint, otherKey.PublicKey.DefaultFingerprint())
+}
+
+func Test_MemoryKeyHistory_PreviousFingerprint_returnsACopy(t *testing.T) {
+	history := NewMemoryKeyHistory()
+	peer := history.ForPeer("bob@example.com")
+	peer.RememberFingerprint([]byte{0x01, 0x02})
+
+	fp := peer.PreviousFingerprint()
+	fp[0] = 0xFF
+
+	assertDeepEquals(t, peer.PreviousFingerprint(), []byte{0x01, 0x02})
+}
+
+func Test_DebugKeyChangeHandler_writesTheFingerprintsToStderr(t *testing.T) {
+	ss := captureStderr(func() {
+		DebugKeyChangeHandler{}.HandleKeyChange([]byte{0x01, 0xAB}, []byte{0x02, 0xCD})
+	})
+	assertEquals(t, ss, "[DEBUG] HandleKeyChange(01AB, 02CD)\n")
+}
@@ -12,7 +12,7 @@ import (
 
 type dhKeyPair struct {
 	pub  *big.Int
-	priv *big.Int
+	priv *secretInt
 }
 
 type akeKeys struct {
@@ -85,6 +85,86 @@ func (h *macKeyHistory) forgetMACKeysForTheirKey(theirKeyID uint32) []macKey {
 	return ret
 }
 
+const sessionKeysSize = 2*aes.BlockSize + 2*sha1.Size + sha256.Size
+
+type sessionKeysUsage struct {
+	ourKeyID, theirKeyID   uint32
+	ourPubKey, theirPubKey *big.Int
+	keys                   *SecureBuffer
+}
+
+// sessionKeysCache keeps the session keys of the key pairs that can still be used, so the
+// DH shared secret is computed once per key pair instead of once per data message
+type sessionKeysCache struct {
+	items []sessionKeysUsage
+}
+
+func (h *sessionKeysCache) find(ourKeyID, theirKeyID uint32, ourPubKey, theirPubKey *big.Int) (sessionKeys, bool) {
+	for _, u := range h.items {
+		if u.ourKeyID == ourKeyID && u.theirKeyID == theirKeyID && eq(u.ourPubKey, ourPubKey) && eq(u.theirPubKey, theirPubKey) {
+			return loadSessionKeys(u.keys.Bytes()), true
+		}
+	}
+
+	return sessionKeys{}, false
+}
+
+func (h *sessionKeysCache) add(ourKeyID, theirKeyID uint32, ourPubKey, theirPubKey *big.Int, keys sessionKeys) {
+	h.forget(func(u sessionKeysUsage) bool {
+		return u.ourKeyID == ourKeyID && u.theirKeyID == theirKeyID
+	})
+
+	h.items = append(h.items, sessionKeysUsage{
+		ourKeyID:    ourKeyID,
+		theirKeyID:  theirKeyID,
+		ourPubKey:   new(big.Int).Set(ourPubKey),
+		theirPubKey: new(big.Int).Set(theirPubKey),
+		keys:        keys.storeIn(newSecretBuffer(sessionKeysSize)),
+	})
+}
+
+// forgetUnusableKeys wipes the session keys of the key pairs that can no longer be picked
+func (h *sessionKeysCache) forgetUnusableKeys(ourKeyID, theirKeyID uint32) {
+	h.forget(func(u sessionKeysUsage) bool {
+		return u.ourKeyID+1 < ourKeyID || u.theirKeyID+1 < theirKeyID
+	})
+}
+
+func (h *sessionKeysCache) forget(matches func(sessionKeysUsage) bool) {
+	kept := h.items[:0]
+	for i := range h.items {
+		if matches(h.items[i]) {
+			h.items[i].wipe()
+		} else {
+			kept = append(kept, h.items[i])
+		}
+	}
+
+	for i := len(kept); i < len(h.items); i++ {
+		h.items[i] = sessionKeysUsage{}
+	}
+	h.items = kept
+}
+
+func (k *sessionKeys) storeIn(b *SecureBuffer) *SecureBuffer {
+	data := b.Bytes()
+	data = data[copy(data, k.sendingAESKey[:]):]
+	data = data[copy(data, k.receivingAESKey[:]):]
+	data = data[copy(data, k.sendingMACKey[:]):]
+	data = data[copy(data, k.receivingMACKey[:]):]
+	copy(data, k.extraKey[:])
+	return b
+}
+
+func loadSessionKeys(data []byte) (ret sessionKeys) {
+	data = data[copy(ret.sendingAESKey[:], data):]
+	data = data[copy(ret.receivingAESKey[:], data):]
+	data = data[copy(ret.sendingMACKey[:], data):]
+	data = data[copy(ret.receivingMACKey[:], data):]
+	copy(ret.extraKey[:], data)
+	return ret
+}
+
 type keyPairCounter struct {
 	ourKeyID, theirKeyID     uint32
 	ourCounter, theirCounter uint64
@@ -110,14 +190,26 @@ func (h *counterHistory) findCounterFor(ourKeyID, theirKeyID uint32) *keyPairCou
 	return c
 }
 
+// peekCounterFor returns the counters for the given key pair, without creating an entry if there is none
+func (h *counterHistory) peekCounterFor(ourKeyID, theirKeyID uint32) keyPairCounter {
+	for _, c := range h.counters {
+		if c.ourKeyID == ourKeyID && c.theirKeyID == theirKeyID {
+			return *c
+		}
+	}
+
+	return keyPairCounter{ourKeyID: ourKeyID, theirKeyID: theirKeyID}
+}
+
 type keyManagementContext struct {
 	ourKeyID, theirKeyID                        uint32
 	ourCurrentDHKeys, ourPreviousDHKeys         dhKeyPair
 	theirCurrentDHPubKey, theirPreviousDHPubKey *big.Int
 
-	counterHistory counterHistory
-	macKeyHistory  macKeyHistory
-	oldMACKeys     []macKey
+	counterHistory   counterHistory
+	macKeyHistory    macKeyHistory
+	sessionKeysCache sessionKeysCache
+	oldMACKeys       []macKey
 }
 
 func (k *keyManagementContext) incrementOurCounter(ourKeyID, theirKeyID uint32) {
@@ -129,8 +221,11 @@ func (k *keyManagementContext) setTheirCurrentDHPubKey(key *big.Int) {
 	k.theirCurrentDHPubKey = setBigInt(k.theirCurrentDHPubKey, key)
 }
 
-func (k *keyManagementContext) setOurCurrentDHKeys(priv *big.Int, pub *big.Int) {
-	k.ourCurrentDHKeys.priv = setBigInt(k.ourCurrentDHKeys.priv, priv)
+func (k *keyManagementContext) setOurCurrentDHKeys(priv *secretInt, pub *big.Int) {
+	x := priv.bigInt()
+	k.ourCurrentDHKeys.priv.destroy()
+	k.ourCurrentDHKeys.priv = newSecretInt(x)
+	wipeBigInt(x)
 	k.ourCurrentDHKeys.pub = setBigInt(k.ourCurrentDHKeys.pub, pub)
 }
 
@@ -162,9 +257,10 @@ func (k *keyManagementContext) generateNewDHKeyPair(randomness io.Reader) error
 	k.ourPreviousDHKeys = k.ourCurrentDHKeys
 
 	k.ourCurrentDHKeys = dhKeyPair{
-		priv: newPrivKey,
-		pub:  modExp(g1, newPrivKey),
+		pub:  secretModExp(g1, newPrivKey),
+		priv: newSecretInt(newPrivKey),
 	}
+	wipeBigInt(newPrivKey)
 	k.ourKeyID++
 	return nil
 }
@@ -175,18 +271,28 @@ func (k *keyManagementContext) revealMACKeysForOurPreviousKeyID() {
 }
 
 func (c *Conversation) rotateKeys(dataMessage dataMsg) error {
+	previousOurKeyID, previousTheirKeyID := c.keys.ourKeyID, c.keys.theirKeyID
+
 	if err := c.keys.rotateOurKeys(dataMessage.recipientKeyID, c.rand()); err != nil {
+		c.log(LogLevelError, "failed to rotate our keys", LogField{"error", err})
 		return err
 	}
 	c.keys.rotateTheirKey(dataMessage.senderKeyID, dataMessage.y)
 
+	if previousOurKeyID != c.keys.ourKeyID || previousTheirKeyID != c.keys.theirKeyID {
+		c.log(LogLevelDebug, "rotated keys", LogField{"ourKeyID", c.keys.ourKeyID}, LogField{"theirKeyID", c.keys.theirKeyID})
+	}
+
 	return nil
 }
 
 func (k *keyManagementContext) rotateOurKeys(recipientKeyID uint32, randomness io.Reader) error {
 	if recipientKeyID == k.ourKeyID {
 		k.revealMACKeysForOurPreviousKeyID()
-		return k.generateNewDHKeyPair(randomness)
+		if err := k.generateNewDHKeyPair(randomness); err != nil {
+			return err
+		}
+		k.sessionKeysCache.forgetUnusableKeys(k.ourKeyID, k.theirKeyID)
 	}
 	return nil
 }
@@ -203,6 +309,7 @@ func (k *keyManagementContext) rotateTheirKey(senderKeyID uint32, pubDHKey *big.
 		k.theirPreviousDHPubKey = k.theirCurrentDHPubKey
 		k.theirCurrentDHPubKey = pubDHKey
 		k.theirKeyID++
+		k.sessionKeysCache.forgetUnusableKeys(k.ourKeyID, k.theirKeyID)
 	}
 }
 
@@ -219,7 +326,13 @@ func (k *keyManagementContext) calculateDHSessionKeys(ourKeyID, theirKeyID uint3
 		return ret, err
 	}
 
-	ret = calculateDHSessionKeys(ourPrivKey, ourPubKey, theirPubKey)
+	ret, ok := k.sessionKeysCache.find(ourKeyID, theirKeyID, ourPubKey, theirPubKey)
+	if !ok {
+		x := ourPrivKey.bigInt()
+		ret = calculateDHSessionKeys(x, ourPubKey, theirPubKey)
+		wipeBigInt(x)
+		k.sessionKeysCache.add(ourKeyID, theirKeyID, ourPubKey, theirPubKey, ret)
+	}
 	k.macKeyHistory.addKeys(ourKeyID, theirKeyID, ret.receivingMACKey)
 
 	return ret, nil
@@ -237,7 +350,7 @@ func calculateDHSessionKeys(ourPrivKey, ourPubKey, theirPubKey *big.Int) session
 		sendbyte, recvbyte = 0x02, 0x01
 	}
 
-	s := new(big.Int).Exp(theirPubKey, ourPrivKey, p)
+	s := secretModExp(theirPubKey, ourPrivKey)
 	secbytes := appendMPI(nil, s)
 
 	sha := sha1.New()
@@ -249,10 +362,13 @@ func calculateDHSessionKeys(ourPrivKey, ourPubKey, theirPubKey *big.Int) session
 
 	copy(ret.extraKey[:], h(0xFF, secbytes, sha256.New()))
 
+	wipeBigInt(s)
+	wipeBytes(secbytes)
+
 	return ret
 }
 
-func (k *keyManagementContext) pickOurKeys(ourKeyID uint32) (privKey, pubKey *big.Int, err error) {
+func (k *keyManagementContext) pickOurKeys(ourKeyID uint32) (privKey *secretInt, pubKey *big.Int, err error) {
 	if ourKeyID == 0 || k.ourKeyID == 0 {
 		return nil, nil, newOtrConflictError("invalid key id for local peer")
 	}
@@ -11,7 +11,7 @@ func Test_calculateDHSessionKeys(t *testing.T) {
 		theirKeyID: 2,
 		ourCurrentDHKeys: dhKeyPair{
 			pub:  fixedGX(),
-			priv: fixedX(),
+			priv: newSecretInt(fixedX()),
 		},
 		theirPreviousDHPubKey: fixedGY(),
 	}
@@ -41,7 +41,7 @@ func Test_calculateDHSessionKeys_storesGeneratedMACKeys(t *testing.T) {
 		theirKeyID:           theirKeyID,
 		theirCurrentDHPubKey: big.NewInt(1),
 		ourCurrentDHKeys: dhKeyPair{
-			priv: big.NewInt(1),
+			priv: newSecretInt(big.NewInt(1)),
 			pub:  big.NewInt(1),
 		},
 	}
@@ -164,16 +164,16 @@ func Test_rotateOurKeys_rotateOurCurrentDHKeys(t *testing.T) {
 		ourKeyID: recipientKeyID,
 		ourCurrentDHKeys: dhKeyPair{
 			pub:  fixedGX(),
-			priv: fixedX(),
+			priv: newSecretInt(fixedX()),
 		},
 	}
 
 	c.rotateOurKeys(recipientKeyID, fixedRand([]string{"abcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcd"}))
 
 	assertEquals(t, c.ourKeyID, recipientKeyID+1)
-	assertDeepEquals(t, c.ourPreviousDHKeys.priv, fixedX())
+	assertDeepEquals(t, c.ourPreviousDHKeys.priv.bigInt(), fixedX())
 	assertDeepEquals(t, c.ourPreviousDHKeys.pub, fixedGX())
-	assertDeepEquals(t, c.ourCurrentDHKeys.priv, fixedY())
+	assertDeepEquals(t, c.ourCurrentDHKeys.priv.bigInt(), fixedY())
 	assertDeepEquals(t, c.ourCurrentDHKeys.pub, fixedGY())
 }
 
@@ -185,16 +185,16 @@ func Test_rotateOurKeys_doesNotRotateIfWeDontReceiveOurCurrentKeyID(t *testing.T
 		ourKeyID: recipientKeyID,
 		ourCurrentDHKeys: dhKeyPair{
 			pub:  fixedGX(),
-			priv: fixedX(),
+			priv: newSecretInt(fixedX()),
 		},
 	}
 
 	c.rotateOurKeys(recipientKeyID+1, fixedRand([]string{"abcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcd"}))
 
 	assertEquals(t, c.ourKeyID, recipientKeyID)
-	assertEquals(t, c.ourPreviousDHKeys.priv, nilB)
+	assertNil(t, c.ourPreviousDHKeys.priv)
 	assertEquals(t, c.ourPreviousDHKeys.pub, nilB)
-	assertDeepEquals(t, c.ourCurrentDHKeys.priv, fixedX())
+	assertDeepEquals(t, c.ourCurrentDHKeys.priv.bigInt(), fixedX())
 	assertDeepEquals(t, c.ourCurrentDHKeys.pub, fixedGX())
 }
 
@@ -251,7 +251,7 @@ func Test_rotateOurKey_revealAllMACKeysAssociatedWithOurPreviousPubKey(t *testin
 	c := keyManagementContext{
 		ourKeyID: 2,
 		ourPreviousDHKeys: dhKeyPair{
-			priv: big.NewInt(1),
+			priv: newSecretInt(big.NewInt(1)),
 			pub:  big.NewInt(2),
 		},
 	}
@@ -321,7 +321,7 @@ func Test_checkMessageCounter_messageIsValidWhenCounterIsLargerThanTheLastReceiv
 }
 
 func Test_generateNewDHKeypair_wipesPreviousDHKeysBeforePointingToCurrentDHKeys(t *testing.T) {
-	prevPrivKey := big.NewInt(1)
+	prevPrivKey := newSecretInt(big.NewInt(1))
 	prevPubKey := big.NewInt(2)
 
 	c := keyManagementContext{
@@ -333,6 +333,75 @@ func Test_generateNewDHKeypair_wipesPreviousDHKeysBeforePointingToCurrentDHKeys(
 
 	c.generateNewDHKeyPair(fixedRand([]string{"abcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcd"}))
 
-	assertEquals(t, prevPrivKey.Int64(), int64(0))
+	assertNil(t, prevPrivKey.buf.Bytes())
 	assertEquals(t, prevPubKey.Int64(), int64(0))
 }
+
+func Test_calculateDHSessionKeys_computesTheSharedSecretOncePerKeyPair(t *testing.T) {
+	defer SetArithmeticBackend(currentArithmeticBackend())
+
+	var calls int
+	SetArithmeticBackend(dynamicArithmeticBackend{func(base, exponent *big.Int) *big.Int {
+		calls++
+		return BigIntBackend{}.ModExp(base, exponent)
+	}})
+
+	c := keyManagementContext{
+		ourKeyID:   1,
+		theirKeyID: 2,
+		ourCurrentDHKeys: dhKeyPair{
+			pub:  fixedGX(),
+			priv: newSecretInt(fixedX()),
+		},
+		theirPreviousDHPubKey: fixedGY(),
+	}
+
+	first, _ := c.calculateDHSessionKeys(1, 1)
+	second, _ := c.calculateDHSessionKeys(1, 1)
+
+	assertEquals(t, calls, 1)
+	assertDeepEquals(t, second, first)
+	assertEquals(t, len(c.macKeyHistory.items), 2)
+}
+
+func Test_calculateDHSessionKeys_recomputesTheSharedSecretWhenTheKeysChange(t *testing.T) {
+	c := keyManagementContext{
+		ourKeyID:   1,
+		theirKeyID: 2,
+		ourCurrentDHKeys: dhKeyPair{
+			pub:  fixedGX(),
+			priv: newSecretInt(fixedX()),
+		},
+		theirPreviousDHPubKey: fixedGY(),
+	}
+
+	first, _ := c.calculateDHSessionKeys(1, 1)
+	c.theirPreviousDHPubKey = g1
+	second, _ := c.calculateDHSessionKeys(1, 1)
+
+	assertDeepEquals(t, second, calculateDHSessionKeys(fixedX(), fixedGX(), g1))
+	assertEquals(t, second == first, false)
+}
+
+func Test_rotateKeys_forgetsTheSessionKeysOfKeyPairsThatCanNoLongerBeUsed(t *testing.T) {
+	c := keyManagementContext{
+		ourKeyID:   1,
+		theirKeyID: 2,
+		ourCurrentDHKeys: dhKeyPair{
+			pub:  fixedGX(),
+			priv: newSecretInt(fixedX()),
+		},
+		theirCurrentDHPubKey:  fixedGY(),
+		theirPreviousDHPubKey: fixedGY(),
+	}
+
+	c.calculateDHSessionKeys(1, 1)
+	c.calculateDHSessionKeys(1, 2)
+	forgottenPubKey := c.sessionKeysCache.items[0].theirPubKey
+
+	c.rotateTheirKey(2, fixedGY())
+
+	assertEquals(t, len(c.sessionKeysCache.items), 1)
+	assertEquals(t, c.sessionKeysCache.items[0].theirKeyID, uint32(2))
+	assertEquals(t, forgottenPubKey.Sign(), 0)
+}
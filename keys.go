@@ -36,6 +36,26 @@ type Account struct {
 	key      *PrivateKey
 }
 
+// NewAccount creates an account with the given name, protocol and private key
+func NewAccount(name, protocol string, key *PrivateKey) *Account {
+	return &Account{name: name, protocol: protocol, key: key}
+}
+
+// Name returns the name of the account
+func (a *Account) Name() string {
+	return a.name
+}
+
+// Protocol returns the protocol of the account
+func (a *Account) Protocol() string {
+	return a.protocol
+}
+
+// Key returns the private key of the account
+func (a *Account) Key() *PrivateKey {
+	return a.key
+}
+
 func readSymbolAndExpect(r *bufio.Reader, s string) bool {
 	res, ok := readPotentialSymbol(r)
 	return ok && res == s
@@ -88,8 +108,12 @@ func ExportKeysToFile(acs []*Account, fname string) error {
 		return err
 	}
 	defer f.Close()
-	exportAccounts(acs, f)
-	return nil
+	return ExportKeys(acs, f)
+}
+
+// ExportKeys will write all the accounts in libotr format to the given writer
+func ExportKeys(acs []*Account, w io.Writer) error {
+	return exportAccounts(acs, w)
 }
 
 // ImportKeys will read the libotr formatted data given and return all accounts defined in it
@@ -259,6 +283,11 @@ func (priv *PrivateKey) Serialize() []byte {
 	return priv.serialize()
 }
 
+// Serialize will return the serialization of the public key to a byte array
+func (pub *PublicKey) Serialize() []byte {
+	return pub.serialize()
+}
+
 func (pub *PublicKey) serialize() []byte {
 	if pub.P == nil || pub.Q == nil || pub.G == nil || pub.Y == nil {
 		return nil
@@ -391,6 +420,12 @@ func (priv *PrivateKey) Generate(rand io.Reader) error {
 	return nil
 }
 
+// Wipe zeroes the private component of the key. The key can't be used to sign afterwards
+func (priv *PrivateKey) Wipe() {
+	wipeBigInt(priv.PrivateKey.X)
+	priv.PrivateKey.X = nil
+}
+
 func notHex(r rune) bool {
 	if r >= '0' && r <= '9' ||
 		r >= 'a' && r <= 'f' ||
@@ -456,12 +491,12 @@ func exportAccount(a *Account, w *bufio.Writer) {
 	w.WriteString(")\n")
 }
 
-func exportAccounts(as []*Account, w io.Writer) {
+func exportAccounts(as []*Account, w io.Writer) error {
 	bw := bufio.NewWriter(w)
 	bw.WriteString("(privkeys\n")
 	for _, a := range as {
 		exportAccount(a, bw)
 	}
 	bw.WriteString(")\n")
-	bw.Flush()
+	return bw.Flush()
 }
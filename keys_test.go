@@ -807,6 +807,12 @@ func Test_PublicKey_serialize_returnsEmptyForNil(t *testing.T) {
 	assertNil(t, result)
 }
 
+func Test_PublicKey_Serialize_willSerializeAPublicKeyCorrectly(t *testing.T) {
+	var pk PublicKey
+	pk.Parse(serializedPublicKey)
+	assertDeepEquals(t, pk.Serialize(), serializedPublicKey)
+}
+
 func Test_PrivateKey_roundTripGeneratesCorrectValue(t *testing.T) {
 	var pk PrivateKey
 	pk.Parse(serializedPrivateKey)
@@ -1075,3 +1081,26 @@ func Test_ExportKeysToFile_returnsAnErrorIfSomethingGoesWrong(t *testing.T) {
 	err := ExportKeysToFile([]*Account{acc}, "non_existing_directory/test_export_of_keys.blah")
 	assertDeepEquals(t, err.Error(), "open non_existing_directory/test_export_of_keys.blah: no such file or directory")
 }
+
+func Test_NewAccount_createsAnAccountWithTheGivenValues(t *testing.T) {
+	var priv PrivateKey
+	priv.Parse(serializedPrivateKey)
+	acc := NewAccount("hello", "go-xmpp", &priv)
+
+	assertEquals(t, acc.Name(), "hello")
+	assertEquals(t, acc.Protocol(), "go-xmpp")
+	assertEquals(t, acc.Key(), &priv)
+}
+
+func Test_ExportKeys_writesAccountsThatCanBeImportedAgain(t *testing.T) {
+	var priv PrivateKey
+	priv.Parse(serializedPrivateKey)
+	bt := bytes.NewBuffer(nil)
+
+	err := ExportKeys([]*Account{NewAccount("hello", "go-xmpp", &priv)}, bt)
+	acs, _ := ImportKeys(bt)
+
+	assertNil(t, err)
+	assertEquals(t, len(acs), 1)
+	assertDeepEquals(t, acs[0].Key().Serialize(), priv.Serialize())
+}
@@ -0,0 +1,164 @@
+package otr3
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// LogLevel indicates the severity of a log entry
+type LogLevel int
+
+const (
+	// LogLevelDebug is used for detailed information about the processing of protocol messages
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo is used for important changes in the state of the conversation, such as a finished AKE
+	LogLevelInfo
+	// LogLevelWarn is used for problems we can recover from, such as malformed messages received from the peer
+	LogLevelWarn
+	// LogLevelError is used for errors that stop the processing of a message
+	LogLevelError
+)
+
+// String returns the string representation of the LogLevel
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return "LOG LEVEL: (THIS SHOULD NEVER HAPPEN)"
+	}
+}
+
+// LogField is a key/value pair attached to a log entry.
+// The library never logs key material: before a field is given to a Logger,
+// any value that is not a number, a boolean, a string, an error, a time or one of the event types
+// of this package is replaced with the redacted marker.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+const redactedLogValue = "[REDACTED]"
+
+// Logger receives structured log entries from a Conversation.
+// Every entry contains the instance tags of the conversation as fields, so entries from different conversations can be told apart.
+type Logger interface {
+	// Log is called for every log entry generated by the conversation
+	Log(level LogLevel, message string, fields []LogField)
+}
+
+type dynamicLogger struct {
+	l func(level LogLevel, message string, fields []LogField)
+}
+
+func (d dynamicLogger) Log(level LogLevel, message string, fields []LogField) {
+	d.l(level, message, fields)
+}
+
+// SetLogger assigns the logger used for this conversation. A nil logger disables logging.
+func (c *Conversation) SetLogger(l Logger) {
+	c.logger = l
+}
+
+func redactLogValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case string, bool, int, int64, uint8, uint16, uint32, uint64,
+		time.Time, time.Duration,
+		LogLevel, SMPEvent, MessageEvent, SecurityEvent, ErrorCode:
+		return v
+	case error:
+		return vv.Error()
+	default:
+		return redactedLogValue
+	}
+}
+
+func (c *Conversation) log(level LogLevel, message string, fields ...LogField) {
+	if c.logger == nil {
+		return
+	}
+
+	all := []LogField{
+		LogField{"ourInstanceTag", c.ourInstanceTag},
+		LogField{"theirInstanceTag", c.theirInstanceTag},
+	}
+
+	for _, f := range fields {
+		all = append(all, LogField{f.Key, redactLogValue(f.Value)})
+	}
+
+	c.logger.Log(level, message, all)
+}
+
+type writerLogger struct {
+	w       io.Writer
+	minimum LogLevel
+}
+
+func (l writerLogger) Log(level LogLevel, message string, fields []LogField) {
+	if level < l.minimum {
+		return
+	}
+
+	line := fmt.Sprintf("[%s] %s", level, message)
+	for _, f := range fields {
+		line += fmt.Sprintf(" %s=%v", f.Key, f.Value)
+	}
+	fmt.Fprintln(l.w, line)
+}
+
+// NewWriterLogger creates a Logger that writes one line of text per log entry to the given writer.
+// Entries with a level lower than minimum are ignored.
+func NewWriterLogger(w io.Writer, minimum LogLevel) Logger {
+	return writerLogger{w, minimum}
+}
+
+// DebugLogger is a Logger that dumps all log entries to standard error
+type DebugLogger struct{}
+
+// Log dumps the log entry
+func (DebugLogger) Log(level LogLevel, message string, fields []LogField) {
+	writerLogger{standardErrorOutput, LogLevelDebug}.Log(level, message, fields)
+}
+
+type combinedLogger struct {
+	loggers []Logger
+}
+
+func (c combinedLogger) Log(level LogLevel, message string, fields []LogField) {
+	for _, l := range c.loggers {
+		if l != nil {
+			l.Log(level, message, fields)
+		}
+	}
+}
+
+// CombineLoggers creates a Logger that will call all loggers
+// given to this function. It ignores nil entries.
+func CombineLoggers(loggers ...Logger) Logger {
+	return combinedLogger{loggers}
+}
+
+func messageTypeName(msgType byte) string {
+	switch msgType {
+	case msgTypeDHCommit:
+		return "DH-Commit"
+	case msgTypeDHKey:
+		return "DH-Key"
+	case msgTypeRevealSig:
+		return "Reveal Signature"
+	case msgTypeSig:
+		return "Signature"
+	case msgTypeData:
+		return "Data"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,159 @@
+package otr3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+type logEntry struct {
+	level   LogLevel
+	message string
+	fields  []LogField
+}
+
+func (c *Conversation) collectLogs() *[]logEntry {
+	entries := []logEntry{}
+	c.SetLogger(dynamicLogger{func(level LogLevel, message string, fields []LogField) {
+		entries = append(entries, logEntry{level, message, fields})
+	}})
+	return &entries
+}
+
+func Test_LogLevel_hasValidStringImplementation(t *testing.T) {
+	assertEquals(t, LogLevelDebug.String(), "DEBUG")
+	assertEquals(t, LogLevelInfo.String(), "INFO")
+	assertEquals(t, LogLevelWarn.String(), "WARN")
+	assertEquals(t, LogLevelError.String(), "ERROR")
+	assertEquals(t, LogLevel(20000).String(), "LOG LEVEL: (THIS SHOULD NEVER HAPPEN)")
+}
+
+func Test_log_doesNothingWithoutALogger(t *testing.T) {
+	c := &Conversation{}
+	c.log(LogLevelInfo, "hello")
+}
+
+func Test_log_addsTheConversationIdentifiersToAllEntries(t *testing.T) {
+	c := &Conversation{ourInstanceTag: 0x101, theirInstanceTag: 0x102}
+	entries := c.collectLogs()
+
+	c.log(LogLevelWarn, "hello", LogField{"one", 1})
+
+	assertEquals(t, len(*entries), 1)
+	assertEquals(t, (*entries)[0].level, LogLevelWarn)
+	assertEquals(t, (*entries)[0].message, "hello")
+	assertDeepEquals(t, (*entries)[0].fields, []LogField{
+		LogField{"ourInstanceTag", uint32(0x101)},
+		LogField{"theirInstanceTag", uint32(0x102)},
+		LogField{"one", 1},
+	})
+}
+
+func Test_log_redactsValuesThatCouldContainKeyMaterial(t *testing.T) {
+	c := &Conversation{}
+	entries := c.collectLogs()
+
+	c.log(LogLevelDebug, "secrets",
+		LogField{"bn", big.NewInt(42)},
+		LogField{"bytes", []byte{0x01, 0x02}},
+		LogField{"macKey", macKey{}},
+		LogField{"keys", akeKeys{}},
+		LogField{"error", newOtrError("bla")},
+		LogField{"event", SMPEventAbort},
+	)
+
+	fields := (*entries)[0].fields
+	assertEquals(t, fields[2].Value, redactedLogValue)
+	assertEquals(t, fields[3].Value, redactedLogValue)
+	assertEquals(t, fields[4].Value, redactedLogValue)
+	assertEquals(t, fields[5].Value, redactedLogValue)
+	assertEquals(t, fields[6].Value, "otr: bla")
+	assertEquals(t, fields[7].Value, SMPEventAbort)
+}
+
+func Test_NewWriterLogger_writesOneLinePerEntryAboveTheMinimumLevel(t *testing.T) {
+	bt := bytes.NewBuffer(make([]byte, 0, 200))
+	l := NewWriterLogger(bt, LogLevelInfo)
+
+	l.Log(LogLevelDebug, "ignored", nil)
+	l.Log(LogLevelWarn, "something happened", []LogField{LogField{"a", 1}, LogField{"b", "two"}})
+
+	assertEquals(t, bt.String(), "[WARN] something happened a=1 b=two\n")
+}
+
+func Test_DebugLogger_writesTheEntryToStderr(t *testing.T) {
+	ss := captureStderr(func() {
+		DebugLogger{}.Log(LogLevelInfo, "AKE finished", []LogField{LogField{"ourKeyID", uint32(1)}})
+	})
+	assertEquals(t, ss, "[INFO] AKE finished ourKeyID=1\n")
+}
+
+func Test_CombineLoggers_callsAllLoggersGiven(t *testing.T) {
+	var called1, called2 bool
+	l1 := dynamicLogger{func(LogLevel, string, []LogField) { called1 = true }}
+	l2 := dynamicLogger{func(LogLevel, string, []LogField) { called2 = true }}
+
+	CombineLoggers(l1, nil, l2).Log(LogLevelInfo, "hello", nil)
+
+	assertEquals(t, called1, true)
+	assertEquals(t, called2, true)
+}
+
+func Test_log_isCalledDuringTheAKEWithoutLeakingKeyMaterial(t *testing.T) {
+	alice := &Conversation{Rand: rand.Reader}
+	alice.ourKey = alicePrivateKey
+	alice.Policies = policies(allowV2 | allowV3)
+	aliceLogs := alice.collectLogs()
+
+	bob := &Conversation{Rand: rand.Reader}
+	bob.ourKey = bobPrivateKey
+	bob.Policies = policies(allowV2 | allowV3)
+	bobLogs := bob.collectLogs()
+
+	_, toSend, _ := bob.Receive(alice.QueryMessage())
+	_, toSend, _ = alice.Receive(toSend[0])
+	_, toSend, _ = bob.Receive(toSend[0])
+	_, toSend, _ = alice.Receive(toSend[0])
+	bob.Receive(toSend[0])
+
+	for _, entries := range []*[]logEntry{aliceLogs, bobLogs} {
+		finished := false
+		for _, e := range *entries {
+			if e.message == "AKE finished" {
+				finished = true
+			}
+			for _, f := range e.fields {
+				switch f.Value.(type) {
+				case *big.Int, []byte:
+					t.Errorf("Didn't expect a possibly secret value in field %s", f.Key)
+				}
+			}
+		}
+		assertEquals(t, finished, true)
+	}
+}
+
+func Test_log_isCalledForEveryEvent(t *testing.T) {
+	c := &Conversation{ourInstanceTag: 0x101, theirInstanceTag: 0x102}
+	entries := c.collectLogs()
+
+	c.smpEventWithQuestion(SMPEventAskForAnswer, 25, "what?")
+	c.securityEvent(GoneSecure)
+	c.messageEventWithMessageAndError(MessageEventReceivedMessageGeneralError, []byte("secret plaintext"), newOtrError("hello"))
+
+	assertEquals(t, len(*entries), 3)
+	assertDeepEquals(t, (*entries)[0], logEntry{LogLevelDebug, "SMP event", []LogField{
+		LogField{"ourInstanceTag", uint32(0x101)},
+		LogField{"theirInstanceTag", uint32(0x102)},
+		LogField{"event", SMPEventAskForAnswer},
+		LogField{"progressPercent", 25},
+		LogField{"question", "what?"},
+	}})
+	assertDeepEquals(t, (*entries)[1].fields[2], LogField{"event", GoneSecure})
+	assertEquals(t, (*entries)[2].message, "message event")
+	assertDeepEquals(t, (*entries)[2].fields[2:], []LogField{
+		LogField{"event", MessageEventReceivedMessageGeneralError},
+		LogField{"error", "otr: hello"},
+	})
+}
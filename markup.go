@@ -0,0 +1,83 @@
+package otr3
+
+import (
+	"bytes"
+	"html"
+)
+
+// MarkupHandler converts between the markup an IM network uses for message bodies and plain text.
+// Received messages are stripped before deciding whether they are OTR messages, and plaintext from the user is escaped before sending
+type MarkupHandler interface {
+	// Strip removes the markup from a received message and unescapes its entities
+	Strip(msg []byte) []byte
+	// Escape turns a message from the user into markup that displays the same text
+	Escape(msg []byte) []byte
+}
+
+// HTMLMarkupHandler is a MarkupHandler for networks that deliver HTML bodies
+type HTMLMarkupHandler struct{}
+
+var nonBreakingSpace = []byte("\u00a0")
+
+// Strip removes all HTML tags and unescapes the entities. Non-breaking spaces become normal spaces,
+// since some networks use them to keep whitespace tags from being collapsed
+func (HTMLMarkupHandler) Strip(msg []byte) []byte {
+	text := make([]byte, 0, len(msg))
+	inTag := false
+	for _, b := range msg {
+		switch {
+		case inTag:
+			inTag = b != '>'
+		case b == '<':
+			inTag = true
+		default:
+			text = append(text, b)
+		}
+	}
+
+	unescaped := []byte(html.UnescapeString(string(text)))
+	wipeBytes(text)
+	return bytes.Replace(unescaped, nonBreakingSpace, []byte(" "), -1)
+}
+
+// Escape escapes the characters that have special meaning in HTML
+func (HTMLMarkupHandler) Escape(msg []byte) []byte {
+	return []byte(html.EscapeString(string(msg)))
+}
+
+// SetMarkupHandler assigns the handler for the markup of the IM network.
+// Without a MarkupHandler messages are sent and received unchanged
+func (c *Conversation) SetMarkupHandler(h MarkupHandler) {
+	c.markupHandler = h
+}
+
+func (c *Conversation) escapeMarkup(msg []byte) []byte {
+	if c.markupHandler == nil {
+		return makeCopy(msg)
+	}
+	return c.markupHandler.Escape(msg)
+}
+
+// stripMarkup returns the stripped message if it is an OTR message, and the message unchanged otherwise,
+// so that the formatting of normal plaintext messages reaches the user
+func (c *Conversation) stripMarkup(msg []byte) []byte {
+	if c.markupHandler == nil {
+		return msg
+	}
+
+	stripped := c.markupHandler.Strip(msg)
+	if guessMessageType(stripped) == msgGuessNotOTR {
+		wipeUnshared(stripped, msg)
+		return msg
+	}
+
+	wipeUnshared(msg, stripped)
+	return stripped
+}
+
+func wipeUnshared(discard, keep []byte) {
+	if len(discard) > 0 && len(keep) > 0 && &discard[0] == &keep[0] {
+		return
+	}
+	wipeBytes(discard)
+}
@@ -0,0 +1,109 @@
+package otr3
+
+import (
+	"html"
+	"testing"
+)
+
+func Test_HTMLMarkupHandler_Strip_removesTagsAndUnescapesEntities(t *testing.T) {
+	stripped := HTMLMarkupHandler{}.Strip([]byte(`<font color="#000">?OTR:AAMD&amp;x&lt;y&#43;.</font>`))
+	assertDeepEquals(t, stripped, []byte("?OTR:AAMD&x<y+."))
+}
+
+func Test_HTMLMarkupHandler_Strip_turnsNonBreakingSpacesIntoSpaces(t *testing.T) {
+	stripped := HTMLMarkupHandler{}.Strip([]byte("hi&nbsp;\t&nbsp;there"))
+	assertDeepEquals(t, stripped, []byte("hi \t there"))
+}
+
+func Test_HTMLMarkupHandler_Escape_escapesSpecialCharacters(t *testing.T) {
+	escaped := HTMLMarkupHandler{}.Escape([]byte(`<3 & "quotes"`))
+	assertDeepEquals(t, escaped, []byte("&lt;3 &amp; &#34;quotes&#34;"))
+}
+
+func wrapInHTML(msgs []ValidMessage) []ValidMessage {
+	var ret []ValidMessage
+	for _, m := range msgs {
+		ret = append(ret, ValidMessage("<html><font face=\"Sans\">"+html.EscapeString(string(m))+"</font></html>"))
+	}
+	return ret
+}
+
+func Test_Receive_recognizesOTRMessagesWrappedInHTML(t *testing.T) {
+	alice := newAKEConversation(alicePrivateKey)
+	bob := newAKEConversation(bobPrivateKey)
+	alice.SetMarkupHandler(HTMLMarkupHandler{})
+	bob.SetMarkupHandler(HTMLMarkupHandler{})
+
+	toSend := wrapInHTML([]ValidMessage{alice.QueryMessage()})
+	for i := 0; len(toSend) > 0; i++ {
+		receiver := bob
+		if i%2 == 1 {
+			receiver = alice
+		}
+		_, msgs, err := receiver.Receive(toSend[0])
+		assertNil(t, err)
+		toSend = wrapInHTML(msgs)
+	}
+
+	assertTrue(t, alice.IsEncrypted())
+	assertTrue(t, bob.IsEncrypted())
+
+	msgs, err := alice.Send(ValidMessage("a <b>"))
+	assertNil(t, err)
+	plain, _, err := bob.Receive(wrapInHTML(msgs)[0])
+	assertNil(t, err)
+	assertDeepEquals(t, plain, MessagePlaintext("a &lt;b&gt;"))
+}
+
+func Test_Receive_keepsTheMarkupOfPlaintextMessages(t *testing.T) {
+	c := newAKEConversation(alicePrivateKey)
+	c.SetMarkupHandler(HTMLMarkupHandler{})
+
+	plain, toSend, err := c.Receive(ValidMessage("<b>hello</b> &amp; bye"))
+	assertNil(t, err)
+	assertNil(t, toSend)
+	assertDeepEquals(t, plain, MessagePlaintext("<b>hello</b> &amp; bye"))
+}
+
+func Test_Receive_recognizesWhitespaceTagsWithNonBreakingSpaces(t *testing.T) {
+	c := newAKEConversation(alicePrivateKey)
+	c.Policies.add(whitespaceStartAKE)
+	c.SetMarkupHandler(HTMLMarkupHandler{})
+
+	tag := html.EscapeString(string(genWhitespaceTag(policies(allowV3))))
+	tagged := "hi<br>" + string(replaceSpaces([]byte(tag)))
+	_, toSend, err := c.Receive(ValidMessage(tagged))
+
+	assertNil(t, err)
+	assertEquals(t, len(toSend), 1)
+	assertEquals(t, guessMessageType(toSend[0]), msgGuessDHCommit)
+}
+
+func replaceSpaces(s []byte) []byte {
+	var ret []byte
+	for _, b := range s {
+		if b == ' ' {
+			ret = append(ret, []byte("&nbsp;")...)
+		} else {
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
+func Test_Send_escapesPlaintextMessages(t *testing.T) {
+	c := newAKEConversation(alicePrivateKey)
+	c.SetMarkupHandler(HTMLMarkupHandler{})
+
+	toSend, err := c.Send(ValidMessage("1 < 2"))
+	assertNil(t, err)
+	assertDeepEquals(t, toSend, []ValidMessage{ValidMessage("1 &lt; 2")})
+}
+
+func Test_Send_withoutMarkupHandlerDoesntChangeTheMessage(t *testing.T) {
+	c := newAKEConversation(alicePrivateKey)
+
+	toSend, err := c.Send(ValidMessage("1 < 2"))
+	assertNil(t, err)
+	assertDeepEquals(t, toSend, []ValidMessage{ValidMessage("1 < 2")})
+}
@@ -1,7 +1,5 @@
 package otr3
 
-import "fmt"
-
 // MessageEvent define the events used to indicate the messages that need to be sent
 type MessageEvent int
 
@@ -70,23 +68,50 @@ func (d dynamicMessageEventHandler) HandleMessageEvent(event MessageEvent, messa
 }
 
 func (c *Conversation) messageEvent(e MessageEvent) {
+	c.countMessageEvent(e)
+	c.logMessageEvent(e, nil)
+	c.publishMessageEvent(e, nil, nil)
 	if c.messageEventHandler != nil {
 		c.messageEventHandler.HandleMessageEvent(e, nil, nil)
 	}
 }
 
 func (c *Conversation) messageEventWithError(e MessageEvent, err error) {
+	c.countMessageEvent(e)
+	c.logMessageEvent(e, err)
+	c.publishMessageEvent(e, nil, err)
 	if c.messageEventHandler != nil {
 		c.messageEventHandler.HandleMessageEvent(e, nil, err)
 	}
 }
 
 func (c *Conversation) messageEventWithMessage(e MessageEvent, msg []byte) {
+	c.countMessageEvent(e)
+	c.logMessageEvent(e, nil)
+	c.publishMessageEvent(e, msg, nil)
 	if c.messageEventHandler != nil {
 		c.messageEventHandler.HandleMessageEvent(e, msg, nil)
 	}
 }
 
+func (c *Conversation) messageEventWithMessageAndError(e MessageEvent, msg []byte, err error) {
+	c.countMessageEvent(e)
+	c.logMessageEvent(e, err)
+	c.publishMessageEvent(e, msg, err)
+	if c.messageEventHandler != nil {
+		c.messageEventHandler.HandleMessageEvent(e, msg, err)
+	}
+}
+
+// logMessageEvent never logs the message of the event, since it can be a plaintext the user sent or received
+func (c *Conversation) logMessageEvent(e MessageEvent, err error) {
+	if err != nil {
+		c.log(LogLevelDebug, "message event", LogField{"event", e}, LogField{"error", err})
+		return
+	}
+	c.log(LogLevelDebug, "message event", LogField{"event", e})
+}
+
 // String returns the string representation of the MessageEvent
 func (s MessageEvent) String() string {
 	switch s {
@@ -143,10 +168,12 @@ func CombineMessageEventHandlers(handlers ...MessageEventHandler) MessageEventHa
 	return combinedMessageEventHandler{handlers}
 }
 
-// DebugMessageEventHandler is a MessageEventHandler that dumps all MessageEvents to standard error
+// DebugMessageEventHandler is a MessageEventHandler that dumps all MessageEvents to standard error.
+// Every message event is also logged at LogLevelDebug by the Logger of the conversation, so SetLogger(DebugLogger{})
+// gives the same information together with the instance tags of the conversation
 type DebugMessageEventHandler struct{}
 
 // HandleMessageEvent dumps all message events
 func (DebugMessageEventHandler) HandleMessageEvent(event MessageEvent, message []byte, err error) {
-	fmt.Fprintf(standardErrorOutput, "%sHandleMessageEvent(%s, message: %#v, error: %v)\n", debugPrefix, event, string(message), err)
+	DebugLogger{}.Log(LogLevelDebug, "message event", []LogField{LogField{"event", event}, LogField{"message", string(message)}, LogField{"error", err}})
 }
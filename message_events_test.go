@@ -44,5 +44,5 @@ func Test_debugMessageEventHandler_writesTheEventToStderr(t *testing.T) {
 	ss := captureStderr(func() {
 		DebugMessageEventHandler{}.HandleMessageEvent(MessageEventLogHeartbeatSent, []byte("A message"), newOtrError("hello world"))
 	})
-	assertEquals(t, ss, "[DEBUG] HandleMessageEvent(MessageEventLogHeartbeatSent, message: \"A message\", error: otr: hello world)\n")
+	assertEquals(t, ss, "[DEBUG] message event event=MessageEventLogHeartbeatSent message=A message error=otr: hello world\n")
 }
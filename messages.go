@@ -232,6 +232,10 @@ func (c *dataMsg) deserialize(msg []byte) error {
 	}
 
 	msg = msg[len(c.serializeUnsignedCache):]
+	if len(msg) < len(c.authenticator) {
+		return newOtrError("dataMsg.deserialize corrupted authenticator")
+	}
+
 	copy(c.authenticator[:], msg)
 	msg = msg[len(c.authenticator):]
 
@@ -317,14 +321,16 @@ func (c plainDataMsg) pad() plainDataMsg {
 	return c
 }
 
-func (c plainDataMsg) encrypt(key [aes.BlockSize]byte, topHalfCtr [8]byte) []byte {
+func (c plainDataMsg) encrypt(key [aes.BlockSize]byte, topHalfCtr [8]byte) ([]byte, error) {
 	var iv [aes.BlockSize]byte
 	copy(iv[:], topHalfCtr[:])
 
 	data := c.pad().serialize()
 	dst := make([]byte, len(data))
-	counterEncipher(key[:], iv[:], data, dst)
-	return dst
+	if err := counterEncipher(key[:], iv[:], data, dst); err != nil {
+		return nil, err
+	}
+	return dst, nil
 }
 
 func (c *plainDataMsg) decrypt(key [aes.BlockSize]byte, topHalfCtr [8]byte, src []byte) error {
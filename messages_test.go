@@ -91,6 +91,34 @@ func Test_dataMsg_deserializeUnsigned_failsWhenTopHalfCtrIsZero(t *testing.T) {
 	assertEquals(t, err.Error(), "otr: dataMsg.deserialize invalid topHalfCtr")
 }
 
+func Test_dataMsg_deserialize_failsForTruncatedMessages(t *testing.T) {
+	msg := dataMsg{
+		senderKeyID:    uint32(0x00000001),
+		recipientKeyID: uint32(0x00000001),
+		y:              big.NewInt(1),
+		topHalfCtr:     [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+		encryptedMsg:   []byte{0x00, 0x01, 0x02, 0x03},
+		oldMACKeys:     []macKey{{0x01}},
+	}.serialize()
+	unsignedLen := len(msg) - len(dataMsg{}.authenticator) - 4 - len(macKey{})
+
+	testCases := []struct {
+		length int
+		err    string
+	}{
+		{unsignedLen, "otr: dataMsg.deserialize corrupted authenticator"},
+		{unsignedLen + 19, "otr: dataMsg.deserialize corrupted authenticator"},
+		{unsignedLen + 20, "otr: dataMsg.deserialize corrupted revealMACKeys"},
+		{unsignedLen + 23, "otr: dataMsg.deserialize corrupted revealMACKeys"},
+		{len(msg) - 1, "otr: dataMsg.deserialize corrupted revealMACKeys"},
+	}
+
+	for _, tc := range testCases {
+		err := (&dataMsg{}).deserialize(msg[:tc.length])
+		assertEquals(t, err.Error(), tc.err)
+	}
+}
+
 func Test_dataMsgCheckSignWithoutError(t *testing.T) {
 	m := dataMsg{
 		serializeUnsignedCache: []byte{0x0, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1, 0x1, 0x0, 0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x0, 0x0, 0x0, 0x4, 0x0, 0x1, 0x2, 0x3},
@@ -434,7 +462,7 @@ func Test_encrypt_EncryptsPlainMessageUsingSendingAESKeyAndCounter(t *testing.T)
 	copy(sendingAESKey[:], bytesFromHex("42e258bebf031acf442f52d6ef52d6f1"))
 	expectedEncrypted := bytesFromHex("4f0de18011633ed0264ccc1840d64f4cf8f0c91ef78890ab82edef36cb38210bb80760585ff43d736a9ff3e4bb05fc088fa34c2f21012988d539ebc839e9bc97633f4c42de15ea5c3c55a2b9940ca35015ded14205b9df78f936cb1521aedbea98df7dc03c116570ba8d034abc8e2d23185d2ce225845f38c08cb2aae192d66d601c1bc86149c98e8874705ae365b31cda76d274429de5e07b93f0ff29152716980a63c31b7bda150b222ba1d373f786d5f59f580d4f690a71d7fc620e0a3b05d692221ddeebac98d6ed16272e7c4596de27fb104ad747aa9a3ad9d3bc4f988af0beb21760df06047e267af0109baceb0f363bcaff7b205f2c42b3cb67a942f2")
 
-	encrypted := plain.encrypt(sendingAESKey, topHalfCtr)
+	encrypted, _ := plain.encrypt(sendingAESKey, topHalfCtr)
 
 	assertDeepEquals(t, encrypted, expectedEncrypted)
 }
@@ -449,7 +477,7 @@ func Test_encrypt_EncryptsPlainMessageUsingSendingAESKeyAndCounterNotZero(t *tes
 	copy(sendingAESKey[:], bytesFromHex("42e258bebf031acf442f52d6ef52d6f1"))
 	expectedEncrypted := bytesFromHex("2dccced4937a337e01bc2ed969b4f60d3ab0a4844aef0a02ebc5c6f09f71a7819687cdbcf2a912be1e8ceda086d188ce3e0bbfecaa77a050a5ed9f98f0c6590579e4d1fb9f753102955dcfc5535af3906ff7d62490362e6e89e28c3b41081f2ce3e8c2ea154a582ff7a1449e7ad8abf295b5e3f8fb80e9b6482fc3bae869ccdb9144f0242604ddee924f388c308c6ce123b5ae22a93ac7c315b13019d474134dd9fd15334fade1b6737b11f79a3cfeed8dd18d72739436ebb560ecdca71a9a67c7b97c2526119a4b1323a6de7c70dffaf7229d798aaea4a692410a139249305d3059685b6ecd0760323ea16db9e02497f5657d1a5d82e09df0088e572b5d0bd7")
 
-	encrypted := plain.encrypt(sendingAESKey, topHalfCtr)
+	encrypted, _ := plain.encrypt(sendingAESKey, topHalfCtr)
 
 	assertDeepEquals(t, encrypted, expectedEncrypted)
 }
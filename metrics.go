@@ -0,0 +1,200 @@
+package otr3
+
+import "sync"
+
+// Metric identifies a counter that is incremented when something happens in a conversation
+type Metric int
+
+const (
+	// MetricAKEStarted is incremented when we start an AKE by sending a DH-Commit message
+	MetricAKEStarted Metric = iota
+	// MetricAKECompleted is incremented when an AKE has finished and the conversation is encrypted with new keys
+	MetricAKECompleted
+	// MetricAKEFailed is incremented when an AKE could not be completed
+	MetricAKEFailed
+	// MetricSecureSessionEnded is incremented when a secure session ends, either by us or by the peer
+	MetricSecureSessionEnded
+
+	// MetricSMPSucceeded is incremented when an SMP run finished and the secrets matched
+	MetricSMPSucceeded
+	// MetricSMPFailed is incremented when an SMP run finished and the secrets didn't match
+	MetricSMPFailed
+	// MetricSMPAborted is incremented when the peer aborts an SMP run, or when it times out
+	MetricSMPAborted
+	// MetricSMPCheated is incremented when the peer sends an SMP message with invalid proofs
+	MetricSMPCheated
+	// MetricSMPError is incremented when we receive an SMP message for another SMP state than we are in
+	MetricSMPError
+
+	// MetricDataMessageSent is incremented for every data message we generate, including heartbeats
+	MetricDataMessageSent
+	// MetricDataMessageReceived is incremented for every data message we can decrypt
+	MetricDataMessageReceived
+	// MetricHeartbeatSent is incremented when we send a heartbeat
+	MetricHeartbeatSent
+	// MetricHeartbeatReceived is incremented when we receive a heartbeat
+	MetricHeartbeatReceived
+	// MetricMessageResent is incremented when a message is resent
+	MetricMessageResent
+	// MetricMessageMalformed is incremented when we receive a malformed message
+	MetricMessageMalformed
+	// MetricMessageUnreadable is incremented when we receive an encrypted message we can't read
+	MetricMessageUnreadable
+	// MetricFragmentDropped is incremented when a fragment is discarded, or when incomplete fragments are forgotten
+	MetricFragmentDropped
+)
+
+// String returns the string representation of the Metric
+func (m Metric) String() string {
+	switch m {
+	case MetricAKEStarted:
+		return "MetricAKEStarted"
+	case MetricAKECompleted:
+		return "MetricAKECompleted"
+	case MetricAKEFailed:
+		return "MetricAKEFailed"
+	case MetricSecureSessionEnded:
+		return "MetricSecureSessionEnded"
+	case MetricSMPSucceeded:
+		return "MetricSMPSucceeded"
+	case MetricSMPFailed:
+		return "MetricSMPFailed"
+	case MetricSMPAborted:
+		return "MetricSMPAborted"
+	case MetricSMPCheated:
+		return "MetricSMPCheated"
+	case MetricSMPError:
+		return "MetricSMPError"
+	case MetricDataMessageSent:
+		return "MetricDataMessageSent"
+	case MetricDataMessageReceived:
+		return "MetricDataMessageReceived"
+	case MetricHeartbeatSent:
+		return "MetricHeartbeatSent"
+	case MetricHeartbeatReceived:
+		return "MetricHeartbeatReceived"
+	case MetricMessageResent:
+		return "MetricMessageResent"
+	case MetricMessageMalformed:
+		return "MetricMessageMalformed"
+	case MetricMessageUnreadable:
+		return "MetricMessageUnreadable"
+	case MetricFragmentDropped:
+		return "MetricFragmentDropped"
+	default:
+		return "METRIC: (THIS SHOULD NEVER HAPPEN)"
+	}
+}
+
+// Metrics receives the counters for the health of a conversation.
+// The same Metrics can be shared between several conversations, so implementations should be safe for concurrent use.
+type Metrics interface {
+	// Increment is called every time the given metric should be incremented by one
+	Increment(m Metric)
+}
+
+// NoMetrics is a Metrics implementation that ignores all metrics. It is used when no Metrics has been set.
+type NoMetrics struct{}
+
+// Increment does nothing
+func (NoMetrics) Increment(Metric) {}
+
+// MemoryMetrics is a Metrics implementation that keeps all counters in memory. It is safe for concurrent use.
+type MemoryMetrics struct {
+	sync.Mutex
+	counters map[Metric]uint64
+}
+
+// NewMemoryMetrics creates a new MemoryMetrics with all counters at zero
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{counters: make(map[Metric]uint64)}
+}
+
+// Increment increments the counter for the given metric
+func (m *MemoryMetrics) Increment(metric Metric) {
+	m.Lock()
+	defer m.Unlock()
+	m.counters[metric]++
+}
+
+// Count returns the current value of the counter for the given metric
+func (m *MemoryMetrics) Count(metric Metric) uint64 {
+	m.Lock()
+	defer m.Unlock()
+	return m.counters[metric]
+}
+
+// Snapshot returns a copy of all counters that have been incremented at least once
+func (m *MemoryMetrics) Snapshot() map[Metric]uint64 {
+	m.Lock()
+	defer m.Unlock()
+
+	ret := make(map[Metric]uint64, len(m.counters))
+	for k, v := range m.counters {
+		ret[k] = v
+	}
+	return ret
+}
+
+// SetMetrics assigns the Metrics that will receive the counters of this conversation
+func (c *Conversation) SetMetrics(m Metrics) {
+	c.metrics = m
+}
+
+func (c *Conversation) incrementMetric(m Metric) {
+	if c.metrics != nil {
+		c.metrics.Increment(m)
+	}
+}
+
+func metricForMessageEvent(e MessageEvent) (Metric, bool) {
+	switch e {
+	case MessageEventSetupError:
+		return MetricAKEFailed, true
+	case MessageEventMessageResent:
+		return MetricMessageResent, true
+	case MessageEventReceivedMessageUnreadable:
+		return MetricMessageUnreadable, true
+	case MessageEventReceivedMessageMalformed:
+		return MetricMessageMalformed, true
+	case MessageEventLogHeartbeatReceived:
+		return MetricHeartbeatReceived, true
+	case MessageEventLogHeartbeatSent:
+		return MetricHeartbeatSent, true
+	}
+	return 0, false
+}
+
+func metricForSMPEvent(e SMPEvent) (Metric, bool) {
+	switch e {
+	case SMPEventSuccess:
+		return MetricSMPSucceeded, true
+	case SMPEventFailure:
+		return MetricSMPFailed, true
+	case SMPEventAbort, SMPEventTimeout:
+		return MetricSMPAborted, true
+	case SMPEventCheated:
+		return MetricSMPCheated, true
+	case SMPEventError:
+		return MetricSMPError, true
+	}
+	return 0, false
+}
+
+func (c *Conversation) countMessageEvent(e MessageEvent) {
+	if m, ok := metricForMessageEvent(e); ok {
+		c.incrementMetric(m)
+	}
+}
+
+func (c *Conversation) countSecurityEvent(e SecurityEvent) {
+	if e == GoneInsecure {
+		c.incrementMetric(MetricSecureSessionEnded)
+	}
+}
+
+func (c *Conversation) countSMPEvent(e SMPEvent) {
+	if m, ok := metricForSMPEvent(e); ok {
+		c.incrementMetric(m)
+	}
+}
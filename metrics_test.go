@@ -0,0 +1,94 @@
+package otr3
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func Test_Metric_hasValidStringImplementation(t *testing.T) {
+	assertEquals(t, MetricAKEStarted.String(), "MetricAKEStarted")
+	assertEquals(t, MetricAKECompleted.String(), "MetricAKECompleted")
+	assertEquals(t, MetricAKEFailed.String(), "MetricAKEFailed")
+	assertEquals(t, MetricSecureSessionEnded.String(), "MetricSecureSessionEnded")
+	assertEquals(t, MetricSMPSucceeded.String(), "MetricSMPSucceeded")
+	assertEquals(t, MetricSMPFailed.String(), "MetricSMPFailed")
+	assertEquals(t, MetricSMPAborted.String(), "MetricSMPAborted")
+	assertEquals(t, MetricSMPCheated.String(), "MetricSMPCheated")
+	assertEquals(t, MetricSMPError.String(), "MetricSMPError")
+	assertEquals(t, MetricDataMessageSent.String(), "MetricDataMessageSent")
+	assertEquals(t, MetricDataMessageReceived.String(), "MetricDataMessageReceived")
+	assertEquals(t, MetricHeartbeatSent.String(), "MetricHeartbeatSent")
+	assertEquals(t, MetricHeartbeatReceived.String(), "MetricHeartbeatReceived")
+	assertEquals(t, MetricMessageResent.String(), "MetricMessageResent")
+	assertEquals(t, MetricMessageMalformed.String(), "MetricMessageMalformed")
+	assertEquals(t, MetricMessageUnreadable.String(), "MetricMessageUnreadable")
+	assertEquals(t, MetricFragmentDropped.String(), "MetricFragmentDropped")
+	assertEquals(t, Metric(20000).String(), "METRIC: (THIS SHOULD NEVER HAPPEN)")
+}
+
+func Test_MemoryMetrics_countsIncrements(t *testing.T) {
+	m := NewMemoryMetrics()
+	m.Increment(MetricSMPFailed)
+	m.Increment(MetricSMPFailed)
+	m.Increment(MetricAKEStarted)
+
+	assertEquals(t, m.Count(MetricSMPFailed), uint64(2))
+	assertEquals(t, m.Count(MetricSMPCheated), uint64(0))
+	assertDeepEquals(t, m.Snapshot(), map[Metric]uint64{MetricSMPFailed: 2, MetricAKEStarted: 1})
+}
+
+func Test_incrementMetric_doesNothingWithoutMetrics(t *testing.T) {
+	c := &Conversation{}
+	c.incrementMetric(MetricAKEStarted)
+	c.SetMetrics(NoMetrics{})
+	c.incrementMetric(MetricAKEStarted)
+}
+
+func Test_events_incrementTheCorrespondingMetrics(t *testing.T) {
+	m := NewMemoryMetrics()
+	c := &Conversation{}
+	c.SetMetrics(m)
+
+	c.messageEvent(MessageEventReceivedMessageMalformed)
+	c.messageEventWithError(MessageEventSetupError, newOtrError("bla"))
+	c.messageEvent(MessageEventReceivedMessageNotInPrivate)
+	c.smpEvent(SMPEventCheated, 0)
+	c.smpEvent(SMPEventInProgress, 20)
+	c.securityEvent(GoneInsecure)
+
+	assertDeepEquals(t, m.Snapshot(), map[Metric]uint64{
+		MetricMessageMalformed:   1,
+		MetricAKEFailed:          1,
+		MetricSMPCheated:         1,
+		MetricSecureSessionEnded: 1,
+	})
+}
+
+func Test_metrics_areCountedDuringTheAKEAndDataExchange(t *testing.T) {
+	aliceMetrics, bobMetrics := NewMemoryMetrics(), NewMemoryMetrics()
+
+	alice := &Conversation{Rand: rand.Reader}
+	alice.ourKey = alicePrivateKey
+	alice.Policies = policies(allowV2 | allowV3)
+	alice.SetMetrics(aliceMetrics)
+
+	bob := &Conversation{Rand: rand.Reader}
+	bob.ourKey = bobPrivateKey
+	bob.Policies = policies(allowV2 | allowV3)
+	bob.SetMetrics(bobMetrics)
+
+	_, toSend, _ := bob.Receive(alice.QueryMessage())
+	_, toSend, _ = alice.Receive(toSend[0])
+	_, toSend, _ = bob.Receive(toSend[0])
+	_, toSend, _ = alice.Receive(toSend[0])
+	bob.Receive(toSend[0])
+
+	toSend, _ = alice.Send(ValidMessage("hello"))
+	bob.Receive(toSend[0])
+
+	assertEquals(t, bobMetrics.Count(MetricAKEStarted), uint64(1))
+	assertEquals(t, aliceMetrics.Count(MetricAKECompleted), uint64(1))
+	assertEquals(t, bobMetrics.Count(MetricAKECompleted), uint64(1))
+	assertEquals(t, aliceMetrics.Count(MetricDataMessageSent), uint64(1))
+	assertEquals(t, bobMetrics.Count(MetricDataMessageReceived), uint64(1))
+}
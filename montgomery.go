@@ -0,0 +1,175 @@
+package otr3
+
+import "math/big"
+
+// montgomeryModulus implements constant-time modular exponentiation for a fixed odd modulus,
+// using Montgomery multiplication on 32-bit limbs. The running time only depends on the size of
+// the modulus, not on the values of the base or the exponent
+type montgomeryModulus struct {
+	n     []uint32 // the modulus, least significant limb first
+	n0inv uint32   // -n^-1 mod 2^32
+	rr    []uint32 // R^2 mod n, used to convert into Montgomery form
+	one   []uint32 // R mod n, the number one in Montgomery form
+	bits  int      // the number of exponent bits that are always processed
+}
+
+const montgomeryWindow = 4
+
+func newMontgomeryModulus(n *big.Int) *montgomeryModulus {
+	limbs := (n.BitLen() + 31) / 32
+
+	r := new(big.Int).Lsh(big.NewInt(1), uint(32*limbs))
+	rr := new(big.Int).Mul(r, r)
+
+	m := &montgomeryModulus{
+		n:     limbsFromBig(n, limbs),
+		n0inv: negInverse32(uint32(n.Uint64())),
+		rr:    limbsFromBig(rr.Mod(rr, n), limbs),
+		one:   limbsFromBig(r.Mod(r, n), limbs),
+		bits:  32 * limbs,
+	}
+	return m
+}
+
+// negInverse32 returns -x^-1 mod 2^32 for an odd x
+func negInverse32(x uint32) uint32 {
+	inv := x
+	for i := 0; i < 4; i++ {
+		inv *= 2 - x*inv
+	}
+	return -inv
+}
+
+func limbsFromBig(x *big.Int, limbs int) []uint32 {
+	ret := make([]uint32, limbs)
+	b := x.Bytes()
+	for i := 0; i < len(b) && i < 4*limbs; i++ {
+		ret[i/4] |= uint32(b[len(b)-1-i]) << uint(8*(i%4))
+	}
+	return ret
+}
+
+func bigFromLimbs(x []uint32) *big.Int {
+	b := make([]byte, 4*len(x))
+	for i, l := range x {
+		j := len(b) - 4*i
+		b[j-1], b[j-2], b[j-3], b[j-4] = byte(l), byte(l>>8), byte(l>>16), byte(l>>24)
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+// mul sets z = x * y * R^-1 mod n. z must not alias x or y. t is scratch space of two limbs more than n
+func (m *montgomeryModulus) mul(z, x, y, t []uint32) {
+	l := len(m.n)
+	n := m.n
+	x = x[:l]
+	for j := range t {
+		t[j] = 0
+	}
+
+	for i := 0; i < l; i++ {
+		var c uint64
+		yi := uint64(y[i])
+		for j, xj := range x {
+			s := uint64(t[j]) + uint64(xj)*yi + c
+			t[j], c = uint32(s), s>>32
+		}
+		s := uint64(t[l]) + c
+		t[l], t[l+1] = uint32(s), uint32(s>>32)
+
+		u := uint64(t[0] * m.n0inv)
+		c = (uint64(t[0]) + u*uint64(n[0])) >> 32
+		for j := 1; j < l; j++ {
+			s := uint64(t[j]) + u*uint64(n[j]) + c
+			t[j-1], c = uint32(s), s>>32
+		}
+		s = uint64(t[l]) + c
+		t[l-1] = uint32(s)
+		t[l] = t[l+1] + uint32(s>>32)
+	}
+
+	// Subtract n if the result is not below n, without branching on the value
+	var borrow uint64
+	for j := 0; j < l; j++ {
+		d := uint64(t[j]) - uint64(m.n[j]) - borrow
+		z[j], borrow = uint32(d), (d>>32)&1
+	}
+	keep := ctMask(uint32(borrow) &^ ctMask(t[l]))
+	for j := 0; j < l; j++ {
+		z[j] = (t[j] & keep) | (z[j] &^ keep)
+	}
+}
+
+// ctMask returns all ones if x is not zero and zero otherwise
+func ctMask(x uint32) uint32 {
+	return uint32(-int32((x | -x) >> 31))
+}
+
+// ctSelect copies the table entry at index into z, reading every entry
+func ctSelect(z []uint32, table [][]uint32, index uint32) {
+	for j := range z {
+		z[j] = 0
+	}
+	for k, entry := range table {
+		mask := ^ctMask(uint32(k) ^ index)
+		for j := range z {
+			z[j] |= entry[j] & mask
+		}
+	}
+}
+
+// exp returns base^exponent mod n. The exponent must not be larger than the modulus.
+// Conversions to and from big.Int are not constant-time with regards to leading zeros
+func (m *montgomeryModulus) exp(base, exponent *big.Int) *big.Int {
+	l := len(m.n)
+	b := limbsFromBig(new(big.Int).Mod(base, bigFromLimbs(m.n)), l)
+	e := limbsFromBig(exponent, l)
+
+	scratch := make([]uint32, l+2)
+
+	table := make([][]uint32, 1<<montgomeryWindow)
+	table[0] = append([]uint32{}, m.one...)
+	table[1] = make([]uint32, l)
+	m.mul(table[1], b, m.rr, scratch)
+	for i := 2; i < len(table); i++ {
+		table[i] = make([]uint32, l)
+		m.mul(table[i], table[i-1], table[1], scratch)
+	}
+
+	acc := append([]uint32{}, m.one...)
+	tmp := make([]uint32, l)
+	sel := make([]uint32, l)
+
+	for i := m.bits/montgomeryWindow - 1; i >= 0; i-- {
+		for k := 0; k < montgomeryWindow; k++ {
+			m.mul(tmp, acc, acc, scratch)
+			acc, tmp = tmp, acc
+		}
+
+		bit := i * montgomeryWindow
+		w := (e[bit/32] >> uint(bit%32)) & (1<<montgomeryWindow - 1)
+		ctSelect(sel, table, w)
+		m.mul(tmp, acc, sel, scratch)
+		acc, tmp = tmp, acc
+	}
+
+	plain := make([]uint32, l)
+	one := make([]uint32, l)
+	one[0] = 1
+	m.mul(plain, acc, one, scratch)
+
+	ret := bigFromLimbs(plain)
+	wipeLimbs(b, e, acc, tmp, sel, plain, scratch)
+	for _, t := range table {
+		wipeLimbs(t)
+	}
+	return ret
+}
+
+func wipeLimbs(ls ...[]uint32) {
+	for _, l := range ls {
+		for i := range l {
+			l[i] = 0
+		}
+	}
+}
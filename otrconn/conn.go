@@ -0,0 +1,246 @@
+package otrconn
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/twstrike/otr3"
+)
+
+var (
+	// ErrNotEncrypted is returned when the peer sends data outside of the encrypted conversation
+	ErrNotEncrypted = errors.New("otrconn: received unencrypted message")
+	// ErrNULByte is returned when writing data that contains a NUL byte, which OTR can't transport
+	ErrNULByte = errors.New("otrconn: data contains a NUL byte")
+	// ErrDeadlineNotSupported is returned when setting a deadline on a transport that doesn't support deadlines
+	ErrDeadlineNotSupported = errors.New("otrconn: the transport doesn't support deadlines")
+
+	errClosed = errors.New("otrconn: use of closed connection")
+)
+
+// Conn is a net.Conn that encrypts everything written to it with OTR
+type Conn struct {
+	transport MessageTransport
+
+	// lock protects the conversation and the writes to the transport, since Read and Write can be called concurrently
+	lock   sync.Mutex
+	conv   *otr3.Conversation
+	closed bool
+
+	readLock sync.Mutex
+	readBuf  []byte
+}
+
+// Client opens an OTR connection by asking the peer to start the AKE, and waits until it has finished.
+// The conversation must have its keys and policies set
+func Client(t MessageTransport, conv *otr3.Conversation) (*Conn, error) {
+	c := &Conn{transport: t, conv: conv}
+
+	if err := c.write([]otr3.ValidMessage{conv.QueryMessage()}); err != nil {
+		return nil, err
+	}
+
+	return c, c.handshake()
+}
+
+// Server opens an OTR connection by waiting for the peer to start the AKE, and waits until it has finished.
+// The conversation must have its keys and policies set
+func Server(t MessageTransport, conv *otr3.Conversation) (*Conn, error) {
+	c := &Conn{transport: t, conv: conv}
+	return c, c.handshake()
+}
+
+func (c *Conn) handshake() error {
+	for !c.conv.IsEncrypted() {
+		msg, err := c.transport.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		// Plaintext messages from before the conversation is encrypted are not part of the stream
+		if _, err := c.receive(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Conn) receive(msg []byte) (otr3.MessagePlaintext, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	plain, toSend, err := c.conv.Receive(msg)
+	if werr := c.write(toSend); err == nil {
+		err = werr
+	}
+	return plain, err
+}
+
+func (c *Conn) write(msgs []otr3.ValidMessage) error {
+	for _, m := range msgs {
+		if err := c.transport.WriteMessage(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads decrypted data from the peer. It returns io.EOF once the peer has ended the OTR conversation
+func (c *Conn) Read(b []byte) (int, error) {
+	c.readLock.Lock()
+	defer c.readLock.Unlock()
+
+	for len(c.readBuf) == 0 {
+		msg, err := c.transport.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+
+		plain, err := c.receive(msg)
+		if err != nil {
+			return 0, err
+		}
+
+		c.lock.Lock()
+		encrypted := c.conv.IsEncrypted()
+		c.lock.Unlock()
+
+		if !encrypted {
+			return 0, io.EOF
+		}
+
+		if len(plain) > 0 && !isEncoded(msg) {
+			return 0, ErrNotEncrypted
+		}
+		c.readBuf = plain
+	}
+
+	n := copy(b, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+// isEncoded returns true for data messages and their fragments, the only messages that carry encrypted data
+func isEncoded(msg []byte) bool {
+	return bytes.HasPrefix(msg, []byte("?OTR:")) || bytes.HasPrefix(msg, []byte("?OTR|")) || bytes.HasPrefix(msg, []byte("?OTR,"))
+}
+
+// Write encrypts the data and sends it to the peer
+func (c *Conn) Write(b []byte) (int, error) {
+	if bytes.IndexByte(b, 0) != -1 {
+		return 0, ErrNULByte
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return 0, errClosed
+	}
+
+	toSend, err := c.conv.Send(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.write(toSend); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close ends the OTR conversation and closes the transport, if it can be closed
+func (c *Conn) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return errClosed
+	}
+	c.closed = true
+
+	toSend, err := c.conv.End()
+	if werr := c.write(toSend); err == nil {
+		err = werr
+	}
+
+	if closer, ok := c.transport.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Conversation returns the OTR conversation of the connection, for example to authenticate the peer with SMP.
+// It must not be used concurrently with Read, Write or Close
+func (c *Conn) Conversation() *otr3.Conversation {
+	return c.conv
+}
+
+// stream returns the connection the transport runs on, to access its addresses and deadlines
+func (c *Conn) stream() interface{} {
+	if t, ok := c.transport.(*lineTransport); ok {
+		return t.rw
+	}
+	return c.transport
+}
+
+type addr struct{}
+
+func (addr) Network() string { return "otr" }
+func (addr) String() string  { return "otr" }
+
+// LocalAddr returns the local address of the transport, if it has one
+func (c *Conn) LocalAddr() net.Addr {
+	if t, ok := c.stream().(interface {
+		LocalAddr() net.Addr
+	}); ok {
+		return t.LocalAddr()
+	}
+	return addr{}
+}
+
+// RemoteAddr returns the remote address of the transport, if it has one
+func (c *Conn) RemoteAddr() net.Addr {
+	if t, ok := c.stream().(interface {
+		RemoteAddr() net.Addr
+	}); ok {
+		return t.RemoteAddr()
+	}
+	return addr{}
+}
+
+// SetDeadline sets the deadline of the transport, if it supports deadlines
+func (c *Conn) SetDeadline(t time.Time) error {
+	if d, ok := c.stream().(interface {
+		SetDeadline(time.Time) error
+	}); ok {
+		return d.SetDeadline(t)
+	}
+	return ErrDeadlineNotSupported
+}
+
+// SetReadDeadline sets the read deadline of the transport, if it supports deadlines
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	if d, ok := c.stream().(interface {
+		SetReadDeadline(time.Time) error
+	}); ok {
+		return d.SetReadDeadline(t)
+	}
+	return ErrDeadlineNotSupported
+}
+
+// SetWriteDeadline sets the write deadline of the transport, if it supports deadlines
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	if d, ok := c.stream().(interface {
+		SetWriteDeadline(time.Time) error
+	}); ok {
+		return d.SetWriteDeadline(t)
+	}
+	return ErrDeadlineNotSupported
+}
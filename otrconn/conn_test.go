@@ -0,0 +1,210 @@
+package otrconn
+
+import (
+	"bufio"
+	"crypto/rand"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/twstrike/otr3"
+)
+
+var testKeys []*otr3.PrivateKey
+
+func newConversation(i int) *otr3.Conversation {
+	for len(testKeys) <= i {
+		k := &otr3.PrivateKey{}
+		k.Generate(rand.Reader)
+		testKeys = append(testKeys, k)
+	}
+
+	c := &otr3.Conversation{}
+	c.Policies.AllowV2()
+	c.Policies.AllowV3()
+	c.SetKeys(testKeys[i], nil)
+	return c
+}
+
+type chanTransport struct {
+	in  <-chan []byte
+	out chan<- []byte
+}
+
+func (t chanTransport) ReadMessage() ([]byte, error) {
+	m, ok := <-t.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return m, nil
+}
+
+func (t chanTransport) WriteMessage(msg []byte) error {
+	t.out <- append([]byte{}, msg...)
+	return nil
+}
+
+func connect(t *testing.T, clientTransport, serverTransport MessageTransport) (client, server *Conn) {
+	done := make(chan error)
+	go func() {
+		var err error
+		server, err = Server(serverTransport, newConversation(1))
+		done <- err
+	}()
+
+	client, err := Client(clientTransport, newConversation(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	return client, server
+}
+
+// pipeConns connects over a socket rather than net.Pipe, since the conversations can send messages
+// like heartbeats that nobody reads right away
+func pipeConns(t *testing.T) (client, server *Conn, clientStream, serverStream net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan net.Conn)
+	go func() {
+		c, _ := l.Accept()
+		accepted <- c
+	}()
+
+	clientStream, err = net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverStream = <-accepted
+
+	client, server = connect(t, NewLineTransport(clientStream), NewLineTransport(serverStream))
+	return
+}
+
+func Test_Client_andServer_establishAnEncryptedConversation(t *testing.T) {
+	client, server, _, _ := pipeConns(t)
+
+	if !client.Conversation().IsEncrypted() || !server.Conversation().IsEncrypted() {
+		t.Error("conversation is not encrypted")
+	}
+}
+
+func Test_Conn_transportsDataInBothDirections(t *testing.T) {
+	client, server, _, _ := pipeConns(t)
+
+	go io.WriteString(client, "ping\n")
+	line, err := bufio.NewReader(server).ReadString('\n')
+	if err != nil || line != "ping\n" {
+		t.Fatalf("unexpected read %q, %v", line, err)
+	}
+
+	go io.WriteString(server, "pong\n")
+	line, err = bufio.NewReader(client).ReadString('\n')
+	if err != nil || line != "pong\n" {
+		t.Fatalf("unexpected read %q, %v", line, err)
+	}
+}
+
+func Test_Conn_Read_returnsDataInPiecesWhenTheBufferIsSmall(t *testing.T) {
+	client, server, _, _ := pipeConns(t)
+	go io.WriteString(client, "hello")
+
+	b := make([]byte, 3)
+	n, _ := server.Read(b)
+	m, _ := server.Read(b[n:])
+
+	if n != 3 || m != 0 {
+		t.Fatalf("unexpected reads %d and %d", n, m)
+	}
+
+	n, _ = server.Read(b)
+	if string(b[:n]) != "lo" {
+		t.Errorf("unexpected read %q", b[:n])
+	}
+}
+
+func Test_Conn_Close_endsTheConversationForThePeer(t *testing.T) {
+	client, server, _, _ := pipeConns(t)
+
+	go client.Close()
+	_, err := server.Read(make([]byte, 10))
+
+	if err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func Test_Conn_Write_failsAfterClose(t *testing.T) {
+	in, out := make(chan []byte, 10), make(chan []byte, 10)
+	c := &Conn{transport: chanTransport{in, out}, conv: newConversation(0)}
+	c.Close()
+
+	if _, err := c.Write([]byte("hello")); err != errClosed {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := c.Close(); err != errClosed {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func Test_Conn_Write_rejectsNULBytes(t *testing.T) {
+	client, _, _, _ := pipeConns(t)
+
+	if _, err := client.Write([]byte("hello\x00")); err != ErrNULByte {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func Test_Conn_Read_rejectsUnencryptedMessages(t *testing.T) {
+	clientIn, serverIn := make(chan []byte, 10), make(chan []byte, 10)
+	_, server := connect(t, chanTransport{clientIn, serverIn}, chanTransport{serverIn, clientIn})
+
+	serverIn <- []byte("not encrypted")
+
+	if _, err := server.Read(make([]byte, 10)); err != ErrNotEncrypted {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func Test_Conn_delegatesAddressesAndDeadlinesToTheStream(t *testing.T) {
+	client, _, clientStream, _ := pipeConns(t)
+
+	if client.LocalAddr() != clientStream.LocalAddr() || client.RemoteAddr() != clientStream.RemoteAddr() {
+		t.Error("addresses are not the ones of the stream")
+	}
+
+	client.SetReadDeadline(time.Now().Add(-time.Second))
+	if _, err := client.Read(make([]byte, 10)); err == nil {
+		t.Error("expected the read deadline to be exceeded")
+	}
+}
+
+func Test_Conn_deadlinesAreNotSupportedForMessageTransports(t *testing.T) {
+	c := &Conn{transport: chanTransport{}}
+
+	if err := c.SetDeadline(time.Now()); err != ErrDeadlineNotSupported {
+		t.Errorf("unexpected error %v", err)
+	}
+	if c.LocalAddr().Network() != "otr" {
+		t.Errorf("unexpected address %v", c.LocalAddr())
+	}
+}
+
+func Test_Conn_isANetConn(t *testing.T) {
+	var _ net.Conn = &Conn{}
+}
+
+func Test_lineTransport_rejectsMessagesWithNewlines(t *testing.T) {
+	a, _ := net.Pipe()
+
+	if err := NewLineTransport(a).WriteMessage([]byte("a\nb")); err == nil {
+		t.Error("expected an error")
+	}
+}
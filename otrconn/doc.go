@@ -0,0 +1,7 @@
+// Package otrconn tunnels a byte stream over an OTR conversation. It wraps a message oriented transport,
+// for example a chat connection to the peer, and an otr3.Conversation into a net.Conn: the AKE is
+// performed when the connection is opened, writes are encrypted, reads are decrypted and closing
+// the connection ends the OTR conversation.
+//
+// OTR messages can't contain NUL bytes, so the tunnel is meant for text based protocols.
+package otrconn
@@ -0,0 +1,68 @@
+package otrconn
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// MessageTransport carries OTR messages to and from the peer
+type MessageTransport interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(msg []byte) error
+}
+
+// maxLineSize limits the size of messages read from a stream, to avoid allocating arbitrary amounts of memory
+const maxLineSize = 1 << 20
+
+var errLineTooLong = errors.New("otrconn: message too long")
+
+type lineTransport struct {
+	rw io.ReadWriter
+	r  *bufio.Reader
+	c  io.Closer
+}
+
+// NewLineTransport returns a MessageTransport that sends one message per line over the stream.
+// OTR encoded messages never contain newlines, so this works for everything the tunnel sends.
+// If the stream is an io.Closer, closing the transport closes it
+func NewLineTransport(rw io.ReadWriter) MessageTransport {
+	t := &lineTransport{rw: rw, r: bufio.NewReader(rw)}
+	t.c, _ = rw.(io.Closer)
+	return t
+}
+
+func (t *lineTransport) ReadMessage() ([]byte, error) {
+	var line []byte
+	for {
+		part, isPrefix, err := t.r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		line = append(line, part...)
+		if len(line) > maxLineSize {
+			return nil, errLineTooLong
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+func (t *lineTransport) WriteMessage(msg []byte) error {
+	if bytes.IndexByte(msg, '\n') != -1 {
+		return errors.New("otrconn: message contains a newline")
+	}
+
+	_, err := t.rw.Write(append(msg, '\n'))
+	return err
+}
+
+func (t *lineTransport) Close() error {
+	if t.c != nil {
+		return t.c.Close()
+	}
+	return nil
+}
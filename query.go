@@ -57,8 +57,8 @@ func (c *Conversation) receiveQueryMessage(msg ValidMessage) ([]messageWithHeade
 	return c.potentialAuthError(compactMessagesWithHeader(ts), err)
 }
 
-//QueryMessage will return a QueryMessage determined by Conversation Policies
-func (c Conversation) QueryMessage() ValidMessage {
+// QueryMessage will return a QueryMessage determined by Conversation Policies
+func (c *Conversation) QueryMessage() ValidMessage {
 	queryMessage := []byte("?OTRv")
 
 	if c.Policies.has(allowV2) {
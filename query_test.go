@@ -51,7 +51,7 @@ func Test_receiveQueryMessage_StoresRAndXAndGx(t *testing.T) {
 
 	assertNil(t, err)
 	assertDeepEquals(t, cxt.ake.r, fixture.ake.r)
-	assertDeepEquals(t, cxt.ake.secretExponent, fixture.ake.secretExponent)
+	assertDeepEquals(t, cxt.ake.secretExponent.bigInt(), fixture.ake.secretExponent.bigInt())
 	assertDeepEquals(t, cxt.ake.ourPublicValue, fixture.ake.ourPublicValue)
 }
 
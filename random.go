@@ -7,10 +7,24 @@ import (
 )
 
 func (c *Conversation) rand() io.Reader {
-	if c.Rand != nil {
-		return c.Rand
+	r := c.Rand
+	if r == nil {
+		r = rand.Reader
 	}
-	return rand.Reader
+
+	if c.transcript != nil && c.transcript.recordRandomness {
+		return recordingReader{r, c.transcript}
+	}
+	return r
+}
+
+// signingRand returns the random source for signatures. It is never recorded or replayed, since
+// signing a different message with the same DSA nonce would reveal the private key
+func (c *Conversation) signingRand() io.Reader {
+	if _, replaying := c.Rand.(*replayReader); c.Rand == nil || replaying {
+		return rand.Reader
+	}
+	return c.Rand
 }
 
 func randomInto(r io.Reader, b []byte) error {
@@ -16,6 +16,22 @@ func Test_conversation_rand_returnsRandReaderIfNoRandomnessIsSet(t *testing.T) {
 	assertEquals(t, c.rand(), rand.Reader)
 }
 
+func Test_conversation_signingRand_returnsTheSetRandomIfThereIsOne(t *testing.T) {
+	r := fixtureRand()
+	c := &Conversation{Rand: r}
+	assertEquals(t, c.signingRand(), r)
+}
+
+func Test_conversation_signingRand_doesNotUseReplayedRandomness(t *testing.T) {
+	c := &Conversation{Rand: &replayReader{}}
+	assertEquals(t, c.signingRand(), rand.Reader)
+}
+
+func Test_conversation_signingRand_doesNotRecordRandomness(t *testing.T) {
+	c := &Conversation{transcript: NewTranscriptRecorder(true)}
+	assertEquals(t, c.signingRand(), rand.Reader)
+}
+
 func Test_randMPI_returnsNilForARealRead(t *testing.T) {
 	c := newConversation(otrV3{}, fixedRand([]string{"ABCD"}))
 	var buf [2]byte
@@ -2,18 +2,27 @@ package otr3
 
 // Receive handles a message from a peer. It returns a human readable message and zero or more messages to send back to the peer.
 func (c *Conversation) Receive(m ValidMessage) (plain MessagePlaintext, toSend []ValidMessage, err error) {
-	return c.receiveUnit(m, true)
+	at := c.now()
+	lastSMPActivity := c.smp.lastActivity
+	plain, toSend, err = c.receiveUnit(m, true)
+	if c.smp.lastActivity.Equal(lastSMPActivity) {
+		toSend = append(toSend, c.maybeTimeoutSMP()...)
+	}
+	c.recordTranscript(TranscriptReceived, at, m, plain, toSend, err)
+	return plain, toSend, err
 }
 
 // Receive handles a message from a peer. It returns a human readable message and zero or more messages to send back to the peer.
 func (c *Conversation) receiveUnit(m ValidMessage, forgetFragments bool) (plain MessagePlaintext, toSend []ValidMessage, err error) {
-	message := makeCopy(m)
-	defer wipeBytes(message)
-
 	if !c.Policies.isOTREnabled() {
+		message := makeCopy(m)
+		defer wipeBytes(message)
 		return c.receiveWithoutOTR(message)
 	}
 
+	message := c.stripMarkup(makeCopy(m))
+	defer wipeBytes(message)
+
 	msgType := guessMessageType(message)
 	var messagesToSend []messageWithHeader
 	shouldForgetFragment := true
@@ -69,6 +78,11 @@ func (c *Conversation) receiveErrorMessage(message ValidMessage) (plain MessageP
 		c.updateMayRetransmitTo(retransmitWithPrefix)
 	}
 
+	if code, ok := ParseErrorMessage(msg); ok {
+		c.messageEventWithMessageAndError(MessageEventReceivedMessageGeneralError, withoutPotentialSpaceStart(msg), ReceivedErrorCode{code})
+		return
+	}
+
 	c.messageEventWithMessage(MessageEventReceivedMessageGeneralError, withoutPotentialSpaceStart(msg))
 	return
 }
@@ -167,6 +181,7 @@ func (c *Conversation) receiveAKEMessage(msgType byte, messageBody []byte) (plai
 func (c *Conversation) receiveDataMessage(messageHeader, messageBody []byte) (plain MessagePlaintext, toSend []messageWithHeader, err error) {
 	plain, toSend, err = c.maybeHeartbeat(c.processDataMessage(messageHeader, messageBody))
 	if err != nil {
+		c.log(LogLevelWarn, "failed to process data message", LogField{"error", err})
 		c.notifyDataMessageError(err)
 	}
 
@@ -211,6 +211,16 @@ func Test_receiveErrorMessage_willSignalAnEventWithTheErrorMessageWithoutLeading
 	}, MessageEventReceivedMessageGeneralError, []byte("an error msg"), nil)
 }
 
+func Test_receiveErrorMessage_willSignalTheErrorCodeForStandardErrorMessages(t *testing.T) {
+	c := aliceContextAfterAKE()
+	c.msgState = encrypted
+	m := []byte("?OTR Error: You transmitted an unreadable encrypted message.")
+
+	c.expectMessageEvent(t, func() {
+		c.receiveErrorMessage(m)
+	}, MessageEventReceivedMessageGeneralError, []byte("You transmitted an unreadable encrypted message."), ReceivedErrorCode{ErrorCodeMessageUnreadable})
+}
+
 func Test_Receive_returnsAnErrorIfWeReceiveARequestToStartAVersion1KeyExchange(t *testing.T) {
 	c := &Conversation{}
 	c.Policies = policies(allowV3)
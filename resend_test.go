@@ -66,7 +66,7 @@ func Test_maybeRetransmit_createsADataMessageWithTheExactMessageWhenAskedToRetra
 	c := newConversation(otrV3{}, rand.Reader)
 	c.Policies.add(allowV3)
 	c.ourKey = bobPrivateKey
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
@@ -92,7 +92,7 @@ func Test_maybeRetransmit_createsADataMessageWithTheResendPrefixAndMessageWhenAs
 	c := newConversation(otrV3{}, rand.Reader)
 	c.Policies.add(allowV3)
 	c.ourKey = bobPrivateKey
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
@@ -119,7 +119,7 @@ func Test_maybeRetransmit_createsADataMessageWithTheCustomResendPrefixAndMessage
 	c := newConversation(otrV3{}, rand.Reader)
 	c.Policies.add(allowV3)
 	c.ourKey = bobPrivateKey
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
@@ -149,7 +149,7 @@ func Test_maybeRetransmit_updatesLastSentWhenSendingAMessage(t *testing.T) {
 	c := newConversation(otrV3{}, rand.Reader)
 	c.Policies.add(allowV3)
 	c.ourKey = bobPrivateKey
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
@@ -173,7 +173,7 @@ func Test_maybeRetransmit_returnsErrorIfWeFailAtGeneratingDataMsg(t *testing.T)
 	c := newConversation(otrV3{}, rand.Reader)
 	c.Policies.add(allowV3)
 	c.ourKey = bobPrivateKey
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
@@ -192,7 +192,7 @@ func Test_maybeRetransmit_signalsMessageEventWhenResendingMessage(t *testing.T)
 	c := newConversation(otrV3{}, rand.Reader)
 	c.Policies.add(allowV3)
 	c.ourKey = bobPrivateKey
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
@@ -214,7 +214,7 @@ func Test_maybeRetransmit_doesntSignalMessageEventWhenResendingMessageExact(t *t
 	c := newConversation(otrV3{}, rand.Reader)
 	c.Policies.add(allowV3)
 	c.ourKey = bobPrivateKey
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	plain := plainDataMsg{
 		message: []byte(""),
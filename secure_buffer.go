@@ -0,0 +1,99 @@
+package otr3
+
+import (
+	"math/big"
+	"runtime"
+)
+
+// SecureBuffer is memory for secret bytes that is kept outside of the Go heap where the platform allows it.
+// On Linux the memory is locked so it can't be swapped out, and surrounded by inaccessible guard pages.
+// On other platforms it is ordinary memory that is wiped when destroyed.
+// The memory is not managed by the garbage collector, so every buffer has to be released with Destroy,
+// and slices of it must not be used afterwards
+type SecureBuffer struct {
+	data   []byte
+	mem    []byte
+	locked bool
+}
+
+// NewSecureBuffer allocates a zeroed SecureBuffer of the given size
+func NewSecureBuffer(size int) (*SecureBuffer, error) {
+	b := &SecureBuffer{}
+	if err := b.allocate(size); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Bytes returns the memory of the buffer. It returns nil after the buffer has been destroyed
+func (b *SecureBuffer) Bytes() []byte {
+	return b.data
+}
+
+// Locked returns true if the memory is locked and can't be swapped out
+func (b *SecureBuffer) Locked() bool {
+	return b.locked
+}
+
+// Destroy wipes and releases the memory. The buffer can't be used afterwards
+func (b *SecureBuffer) Destroy() {
+	if b.data == nil {
+		return
+	}
+
+	wipeBytes(b.data)
+	wipedSecureBuffer(b)
+	b.data = nil
+	b.release()
+}
+
+// wipedSecureBuffer is called with the memory of every buffer after it has been wiped, right before it is released
+var wipedSecureBuffer = func(b *SecureBuffer) {}
+
+// secretInt is a non-negative secret integer stored in a SecureBuffer. The value only exists as a big.Int while it
+// is used: every big.Int returned by bigInt has to be wiped by the caller as soon as it is not needed anymore
+type secretInt struct {
+	buf *SecureBuffer
+}
+
+// newSecretBuffer allocates a SecureBuffer for a secret owned by the library. If no SecureBuffer can be allocated
+// the secret is kept in ordinary memory. The owner destroys the buffer as soon as the secret is not needed anymore;
+// the buffer is also destroyed when it is garbage collected, so dropping a conversation without ending it doesn't
+// leak locked memory. Slices of the buffer must not outlive the owner
+func newSecretBuffer(size int) *SecureBuffer {
+	b, err := NewSecureBuffer(size)
+	if err != nil {
+		b = &SecureBuffer{data: make([]byte, size)}
+	}
+	runtime.SetFinalizer(b, (*SecureBuffer).Destroy)
+	return b
+}
+
+// newSecretInt stores a copy of v in a SecureBuffer. The caller is still responsible for wiping v
+func newSecretInt(v *big.Int) *secretInt {
+	if v == nil {
+		return nil
+	}
+
+	value := v.Bytes()
+	defer wipeBytes(value)
+
+	buf := newSecretBuffer(len(value))
+	copy(buf.Bytes(), value)
+	return &secretInt{buf: buf}
+}
+
+// bigInt returns a new big.Int with the value, that the caller has to wipe after use
+func (s *secretInt) bigInt() *big.Int {
+	if s == nil {
+		return nil
+	}
+	return new(big.Int).SetBytes(s.buf.Bytes())
+}
+
+func (s *secretInt) destroy() {
+	if s == nil {
+		return
+	}
+	s.buf.Destroy()
+}
@@ -0,0 +1,60 @@
+package otr3
+
+import (
+	"os"
+	"syscall"
+)
+
+func (b *SecureBuffer) allocate(size int) error {
+	page := os.Getpagesize()
+	dataPages := (size + page - 1) / page
+	if dataPages == 0 {
+		dataPages = 1
+	}
+
+	mem, err := syscall.Mmap(-1, 0, (dataPages+2)*page, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return err
+	}
+
+	// Guard pages before and after the data make stray reads and writes fault instead of touching secrets
+	if err := syscall.Mprotect(mem[:page], syscall.PROT_NONE); err != nil {
+		syscall.Munmap(mem)
+		return err
+	}
+	if err := syscall.Mprotect(mem[(dataPages+1)*page:], syscall.PROT_NONE); err != nil {
+		syscall.Munmap(mem)
+		return err
+	}
+
+	usable := mem[page : (dataPages+1)*page]
+
+	// Locking can fail when RLIMIT_MEMLOCK is exhausted - the buffer is still usable, just not locked
+	b.locked = syscall.Mlock(usable) == nil
+
+	// The data ends right before the trailing guard page, so overflows are caught
+	b.data = usable[len(usable)-roundUpTo(size, 16):][:size:size]
+	b.mem = mem
+	return nil
+}
+
+func (b *SecureBuffer) release() {
+	if b.mem == nil {
+		return
+	}
+
+	page := os.Getpagesize()
+	usable := b.mem[page : len(b.mem)-page]
+	wipeBytes(usable)
+
+	if b.locked {
+		syscall.Munlock(usable)
+	}
+	syscall.Munmap(b.mem)
+	b.mem = nil
+	b.locked = false
+}
+
+func roundUpTo(n, m int) int {
+	return (n + m - 1) / m * m
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package otr3
+
+func (b *SecureBuffer) allocate(size int) error {
+	b.data = make([]byte, size)
+	return nil
+}
+
+func (b *SecureBuffer) release() {}
@@ -0,0 +1,171 @@
+package otr3
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func allWordsAreZero(words []big.Word) bool {
+	for _, w := range words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_NewSecureBuffer_returnsAZeroedBufferOfTheRequestedSize(t *testing.T) {
+	b, err := NewSecureBuffer(42)
+	assertNil(t, err)
+	defer b.Destroy()
+
+	assertEquals(t, len(b.Bytes()), 42)
+	assertDeepEquals(t, b.Bytes(), make([]byte, 42))
+}
+
+func Test_SecureBuffer_Destroy_wipesAndReleasesTheMemory(t *testing.T) {
+	b, _ := NewSecureBuffer(8)
+	copy(b.Bytes(), []byte("secretss"))
+
+	b.Destroy()
+
+	assertNil(t, b.Bytes())
+	assertEquals(t, b.Locked(), false)
+}
+
+func Test_SecureBuffer_Destroy_canBeCalledTwice(t *testing.T) {
+	b, _ := NewSecureBuffer(8)
+	b.Destroy()
+	b.Destroy()
+
+	assertNil(t, b.Bytes())
+}
+
+func Test_wipeBigInt_zeroesTheWordsInPlace(t *testing.T) {
+	s, _ := new(big.Int).SetString("FFEEDDCCBBAA99887766554433221100FFEEDDCCBBAA9988", 16)
+	words := s.Bits()
+
+	wipeBigInt(s)
+
+	assertEquals(t, allWordsAreZero(words), true)
+}
+
+func Test_PrivateKey_Wipe_removesThePrivateComponent(t *testing.T) {
+	priv := &PrivateKey{}
+	priv.Parse(alicePrivateKey.serialize())
+	words := priv.PrivateKey.X.Bits()
+
+	priv.Wipe()
+
+	assertNil(t, priv.PrivateKey.X)
+	assertEquals(t, allWordsAreZero(words), true)
+}
+
+func Test_End_wipesTheMemoryOfTheDHPrivateKeysAndSessionKeys(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.Send(ValidMessage("hello"))
+
+	current := alice.keys.ourCurrentDHKeys.priv.buf
+	previous := alice.keys.ourPreviousDHKeys.priv.buf
+	sessionKeys := alice.keys.sessionKeysCache.items[0].keys
+	assertEquals(t, allBytesAreZero(current.Bytes()), false)
+	assertEquals(t, allBytesAreZero(sessionKeys.Bytes()), false)
+
+	wiped, stop := watchWipedSecureBuffers()
+	defer stop()
+	alice.End()
+
+	wiped.assertZeroed(t, current, previous, sessionKeys)
+}
+
+func Test_keyRotation_wipesTheMemoryOfTheReplacedDHPrivateKeyAndItsSessionKeys(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+
+	toSend, _ := alice.Send(ValidMessage("hello"))
+	previous := alice.keys.ourPreviousDHKeys.priv.buf
+	sessionKeys := alice.keys.sessionKeysCache.items[0].keys
+	assertEquals(t, allBytesAreZero(previous.Bytes()), false)
+
+	wiped, stop := watchWipedSecureBuffers()
+	defer stop()
+	bob.Receive(toSend[0])
+	toSend, _ = bob.Send(ValidMessage("hi"))
+	alice.Receive(toSend[0])
+	toSend, _ = alice.Send(ValidMessage("hello again"))
+	bob.Receive(toSend[0])
+	toSend, _ = bob.Send(ValidMessage("hi again"))
+	alice.Receive(toSend[0])
+
+	wiped.assertZeroed(t, previous, sessionKeys)
+}
+
+func Test_SMP_wipesTheMemoryOfTheSecretWhenFinished(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.lastSMPEvent()
+	bob.lastSMPEvent()
+
+	toSend, _ := alice.StartAuthenticate("", []byte("secret"))
+	secret := alice.smp.secret.buf
+	assertEquals(t, allBytesAreZero(secret.Bytes()), false)
+
+	wiped, stop := watchWipedSecureBuffers()
+	defer stop()
+	bob.Receive(toSend[0])
+	toSend, _ = bob.ProvideAuthenticationSecret([]byte("secret"))
+	_, toSend, _ = alice.Receive(toSend[0])
+	_, toSend, _ = bob.Receive(toSend[0])
+	alice.Receive(toSend[0])
+
+	assertNil(t, alice.smp.secret)
+	wiped.assertZeroed(t, secret)
+}
+
+func Test_secretInt_keepsTheValueInASecureBuffer(t *testing.T) {
+	s := newSecretInt(fixedX())
+	defer s.destroy()
+
+	assertDeepEquals(t, s.buf.Bytes(), fixedX().Bytes())
+	assertDeepEquals(t, s.bigInt(), fixedX())
+}
+
+func allBytesAreZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// wipedBuffers records the contents of the secure buffers at the moment they are released,
+// since their memory can't be read anymore afterwards
+type wipedBuffers struct {
+	sync.Mutex
+	contents map[*SecureBuffer][]byte
+}
+
+func watchWipedSecureBuffers() (*wipedBuffers, func()) {
+	w := &wipedBuffers{contents: make(map[*SecureBuffer][]byte)}
+	original := wipedSecureBuffer
+	wipedSecureBuffer = func(b *SecureBuffer) {
+		w.Lock()
+		defer w.Unlock()
+		w.contents[b] = append([]byte{}, b.Bytes()...)
+	}
+	return w, func() { wipedSecureBuffer = original }
+}
+
+func (w *wipedBuffers) assertZeroed(t *testing.T, buffers ...*SecureBuffer) {
+	w.Lock()
+	defer w.Unlock()
+	for _, b := range buffers {
+		contents, released := w.contents[b]
+		assertEquals(t, released, true)
+		assertEquals(t, allBytesAreZero(contents), true)
+		assertNil(t, b.Bytes())
+	}
+}
@@ -1,8 +1,6 @@
 package otr3
 
-import "fmt"
-
-// SecurityEvent define the events used to indicate changes in security status. In comparison with libotr, this library does not take trust levels into concern for security events
+// SecurityEvent define the events used to indicate changes in security status. Trust levels are only reported when a KnownKeySource has been set for the conversation
 type SecurityEvent int
 
 const (
@@ -12,6 +10,14 @@ const (
 	GoneSecure
 	// StillSecure is signalled when we have refreshed the security state but is still in a secure state
 	StillSecure
+	// SecureUnverified is signalled after GoneSecure or StillSecure when the key of the peer has not been verified by the user
+	SecureUnverified
+	// SecureVerified is signalled after GoneSecure or StillSecure when the key of the peer has been verified by the user
+	SecureVerified
+	// FingerprintChanged is signalled when the peer uses a key we have never seen, even though other keys are known for the peer
+	FingerprintChanged
+	// KeyChangedMidSession is signalled when a refreshed AKE in a secure session was done with a different key for the peer
+	KeyChangedMidSession
 )
 
 // SecurityEventHandler is an interface for events that are related to changes of security status
@@ -29,6 +35,9 @@ func (d dynamicSecurityEventHandler) HandleSecurityEvent(event SecurityEvent) {
 }
 
 func (c *Conversation) securityEvent(e SecurityEvent) {
+	c.countSecurityEvent(e)
+	c.log(LogLevelDebug, "security event", LogField{"event", e})
+	c.publishSecurityEvent(e)
 	if c.securityEventHandler != nil {
 		c.securityEventHandler.HandleSecurityEvent(e)
 	}
@@ -43,6 +52,14 @@ func (s SecurityEvent) String() string {
 		return "GoneSecure"
 	case StillSecure:
 		return "StillSecure"
+	case SecureUnverified:
+		return "SecureUnverified"
+	case SecureVerified:
+		return "SecureVerified"
+	case FingerprintChanged:
+		return "FingerprintChanged"
+	case KeyChangedMidSession:
+		return "KeyChangedMidSession"
 	default:
 		return "SECURITY EVENT: (THIS SHOULD NEVER HAPPEN)"
 	}
@@ -72,10 +89,12 @@ func (c *Conversation) signalSecurityEventIf(cond bool, event SecurityEvent) {
 	}
 }
 
-// DebugSecurityEventHandler is a SecurityEventHandler that dumps all SecurityEvents to standard error
+// DebugSecurityEventHandler is a SecurityEventHandler that dumps all SecurityEvents to standard error.
+// Every security event is also logged at LogLevelDebug by the Logger of the conversation, so SetLogger(DebugLogger{})
+// gives the same information together with the instance tags of the conversation
 type DebugSecurityEventHandler struct{}
 
 // HandleSecurityEvent dumps all security events
 func (DebugSecurityEventHandler) HandleSecurityEvent(event SecurityEvent) {
-	fmt.Fprintf(standardErrorOutput, "%sHandleSecurityEvent(%s)\n", debugPrefix, event)
+	DebugLogger{}.Log(LogLevelDebug, "security event", []LogField{LogField{"event", event}})
 }
@@ -6,6 +6,10 @@ func Test_SecurityEvent_hasValidStringImplementation(t *testing.T) {
 	assertEquals(t, GoneInsecure.String(), "GoneInsecure")
 	assertEquals(t, GoneSecure.String(), "GoneSecure")
 	assertEquals(t, StillSecure.String(), "StillSecure")
+	assertEquals(t, SecureUnverified.String(), "SecureUnverified")
+	assertEquals(t, SecureVerified.String(), "SecureVerified")
+	assertEquals(t, FingerprintChanged.String(), "FingerprintChanged")
+	assertEquals(t, KeyChangedMidSession.String(), "KeyChangedMidSession")
 	assertEquals(t, SecurityEvent(20000).String(), "SECURITY EVENT: (THIS SHOULD NEVER HAPPEN)")
 }
 
@@ -32,5 +36,5 @@ func Test_debugSecurityEventHandler_writesTheEventToStderr(t *testing.T) {
 	ss := captureStderr(func() {
 		DebugSecurityEventHandler{}.HandleSecurityEvent(StillSecure)
 	})
-	assertEquals(t, ss, "[DEBUG] HandleSecurityEvent(StillSecure)\n")
+	assertEquals(t, ss, "[DEBUG] security event event=StillSecure\n")
 }
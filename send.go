@@ -8,7 +8,16 @@ import (
 // Send takes a human readable message from the local user, possibly encrypts
 // it and returns zero or more messages to send to the peer.
 func (c *Conversation) Send(m ValidMessage) ([]ValidMessage, error) {
-	message := makeCopy(m)
+	at := c.now()
+	aborted := c.maybeTimeoutSMP()
+	toSend, err := c.send(m)
+	toSend = append(aborted, toSend...)
+	c.recordTranscript(TranscriptSent, at, m, nil, toSend, err)
+	return toSend, err
+}
+
+func (c *Conversation) send(m ValidMessage) ([]ValidMessage, error) {
+	message := c.escapeMarkup(m)
 	defer wipeBytes(message)
 
 	if !c.Policies.isOTREnabled() {
@@ -48,7 +57,11 @@ func (c *Conversation) sendMessageOnPlaintext(message ValidMessage) ([]ValidMess
 func (c *Conversation) sendMessageOnEncrypted(message ValidMessage) ([]ValidMessage, error) {
 	result, _, err := c.createSerializedDataMessage(message, messageFlagNormal, []tlv{})
 	if err != nil {
-		c.messageEvent(MessageEventEncryptionError)
+		if encErr, ok := err.(EncryptionError); ok {
+			c.messageEventWithError(MessageEventEncryptionError, encErr)
+		} else {
+			c.messageEvent(MessageEventEncryptionError)
+		}
 		c.generatePotentialErrorMessage(ErrorCodeEncryptionError)
 	}
 
@@ -70,6 +83,8 @@ func (c *Conversation) sendDHCommit() (toSend messageWithHeader, err error) {
 	}
 
 	c.ake.state = authStateAwaitingDHKey{}
+	c.incrementMetric(MetricAKEStarted)
+	c.log(LogLevelInfo, "starting AKE", LogField{"protocolVersion", c.version.protocolVersion()})
 
 	return
 }
@@ -0,0 +1,34 @@
+package otr3
+
+import "io"
+
+// Signer creates the signatures that prove our identity during the AKE.
+// A PrivateKey is the default Signer, but the signing can be delegated to somewhere else,
+// for example a signing daemon that never exposes the private key - see AgentSigner
+type Signer interface {
+	// Public returns the public key that verifies the signatures of this Signer
+	Public() *PublicKey
+	// Sign returns the 40 byte DSA signature (r || s) of the hashed data
+	Sign(rand io.Reader, hashed []byte) ([]byte, error)
+}
+
+// Public returns the public part of the key
+func (priv *PrivateKey) Public() *PublicKey {
+	return &priv.PublicKey
+}
+
+// SetSigner sets the Signer used to sign during the AKE. If none is set, the private key given to SetKeys is used
+func (c *Conversation) SetSigner(s Signer) {
+	c.signer = s
+}
+
+func (c *Conversation) ourSigner() Signer {
+	if c.signer != nil {
+		return c.signer
+	}
+	return c.ourKey
+}
+
+func (c *Conversation) ourPublicKey() *PublicKey {
+	return c.ourSigner().Public()
+}
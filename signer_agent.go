@@ -0,0 +1,198 @@
+package otr3
+
+import (
+	"io"
+	"net"
+	"sync"
+)
+
+// The agent protocol is a sequence of requests and responses, each framed as OTR DATA
+// (a 4 byte big-endian length followed by the payload).
+// Requests start with an operation byte, responses with a status byte followed by the result or an error text
+const (
+	agentOpPublicKey byte = 0x01
+	agentOpSign      byte = 0x02
+
+	agentStatusOK    byte = 0x00
+	agentStatusError byte = 0x01
+
+	agentMaxFrameSize = 64 * 1024
+)
+
+// AgentSigner is a Signer that delegates signing to a separate process, for example a signing daemon
+// listening on a local socket. The private key never has to be loaded into this process.
+// The agent is expected to use its own source of randomness for the signatures
+type AgentSigner struct {
+	lock   sync.Mutex
+	conn   io.ReadWriter
+	public *PublicKey
+}
+
+// NewAgentSigner creates an AgentSigner that talks to the agent over the given connection.
+// It asks the agent for its public key before returning
+func NewAgentSigner(conn io.ReadWriter) (*AgentSigner, error) {
+	s := &AgentSigner{conn: conn}
+
+	resp, err := s.request([]byte{agentOpPublicKey})
+	if err != nil {
+		return nil, err
+	}
+
+	pub := &PublicKey{}
+	if _, ok := pub.Parse(resp); !ok {
+		return nil, errAgentProtocol
+	}
+	s.public = pub
+
+	return s, nil
+}
+
+// DialAgentSigner connects to an agent listening on the given address and creates an AgentSigner for it
+func DialAgentSigner(network, address string) (*AgentSigner, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := NewAgentSigner(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Public returns the public key of the agent
+func (s *AgentSigner) Public() *PublicKey {
+	return s.public
+}
+
+// Sign asks the agent to sign the hashed data. The rand argument is ignored
+func (s *AgentSigner) Sign(_ io.Reader, hashed []byte) ([]byte, error) {
+	sig, err := s.request(append([]byte{agentOpSign}, hashed...))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sig) != 40 {
+		return nil, errAgentProtocol
+	}
+	return sig, nil
+}
+
+// Close closes the connection to the agent, if it can be closed
+func (s *AgentSigner) Close() error {
+	if c, ok := s.conn.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (s *AgentSigner) request(req []byte) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := writeAgentFrame(s.conn, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := readAgentFrame(s.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp) == 0 {
+		return nil, errAgentProtocol
+	}
+
+	switch resp[0] {
+	case agentStatusOK:
+		return resp[1:], nil
+	case agentStatusError:
+		return nil, newOtrErrorf("signing agent: %s", resp[1:])
+	}
+
+	return nil, errAgentProtocol
+}
+
+// ServeSignerAgent accepts connections on the listener and answers the requests of AgentSigners on them,
+// using the given Signer. It returns when the listener fails, for example after being closed
+func ServeSignerAgent(l net.Listener, signer Signer, rand io.Reader) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+			ServeSignerAgentConn(conn, signer, rand)
+		}()
+	}
+}
+
+// ServeSignerAgentConn answers the requests of an AgentSigner on a single connection until it fails or is closed
+func ServeSignerAgentConn(conn io.ReadWriter, signer Signer, rand io.Reader) error {
+	for {
+		req, err := readAgentFrame(conn)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := writeAgentFrame(conn, handleAgentRequest(req, signer, rand)); err != nil {
+			return err
+		}
+	}
+}
+
+func handleAgentRequest(req []byte, signer Signer, rand io.Reader) []byte {
+	if len(req) == 0 {
+		return agentErrorResponse("empty request")
+	}
+
+	switch req[0] {
+	case agentOpPublicKey:
+		return append([]byte{agentStatusOK}, signer.Public().serialize()...)
+	case agentOpSign:
+		sig, err := signer.Sign(rand, req[1:])
+		if err != nil {
+			return agentErrorResponse(err.Error())
+		}
+		return append([]byte{agentStatusOK}, sig...)
+	}
+
+	return agentErrorResponse("unknown operation")
+}
+
+func agentErrorResponse(msg string) []byte {
+	return append([]byte{agentStatusError}, msg...)
+}
+
+func writeAgentFrame(w io.Writer, payload []byte) error {
+	_, err := w.Write(appendData(nil, payload))
+	return err
+}
+
+func readAgentFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	_, length, _ := extractWord(header)
+	if length > agentMaxFrameSize {
+		return nil, errAgentFrameTooLarge
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,140 @@
+package otr3
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type dynamicSigner struct {
+	public *PublicKey
+	f      func(rand io.Reader, hashed []byte) ([]byte, error)
+}
+
+func (d dynamicSigner) Public() *PublicKey {
+	return d.public
+}
+
+func (d dynamicSigner) Sign(rand io.Reader, hashed []byte) ([]byte, error) {
+	return d.f(rand, hashed)
+}
+
+func startAgent(t *testing.T, signer Signer) *AgentSigner {
+	client, server := net.Pipe()
+	go ServeSignerAgentConn(server, signer, rand.Reader)
+
+	s, err := NewAgentSigner(client)
+	assertNil(t, err)
+	return s
+}
+
+func Test_PrivateKey_Public_returnsThePublicKey(t *testing.T) {
+	assertEquals(t, alicePrivateKey.Public(), &alicePrivateKey.PublicKey)
+}
+
+func Test_AKE_usesTheSigner(t *testing.T) {
+	signatures := 0
+	alice, bob := newAKEConversation(nil), newAKEConversation(bobPrivateKey)
+	alice.SetSigner(dynamicSigner{&alicePrivateKey.PublicKey, func(r io.Reader, hashed []byte) ([]byte, error) {
+		signatures++
+		return alicePrivateKey.Sign(r, hashed)
+	}})
+
+	performAKE(alice, bob)
+
+	assertEquals(t, signatures, 1)
+	assertEquals(t, bob.IsEncrypted(), true)
+	assertDeepEquals(t, *bob.GetTheirKey(), alicePrivateKey.PublicKey)
+}
+
+func Test_AKE_returnsTheErrorOfTheSigner(t *testing.T) {
+	alice, bob := newAKEConversation(nil), newAKEConversation(bobPrivateKey)
+	alice.SetSigner(dynamicSigner{&alicePrivateKey.PublicKey, func(io.Reader, []byte) ([]byte, error) {
+		return nil, errors.New("signer is gone")
+	}})
+
+	_, toSend, _ := bob.Receive(alice.QueryMessage())
+	_, toSend, _ = alice.Receive(toSend[0])
+	_, toSend, _ = bob.Receive(toSend[0])
+	_, _, err := alice.Receive(toSend[0])
+
+	assertDeepEquals(t, err, errors.New("signer is gone"))
+}
+
+func Test_AgentSigner_returnsThePublicKeyOfTheAgent(t *testing.T) {
+	s := startAgent(t, alicePrivateKey)
+	defer s.Close()
+
+	assertDeepEquals(t, *s.Public(), alicePrivateKey.PublicKey)
+}
+
+func Test_AgentSigner_Sign_returnsAValidSignature(t *testing.T) {
+	s := startAgent(t, alicePrivateKey)
+	defer s.Close()
+
+	hashed := []byte("0123456789abcdef0123")
+	sig, err := s.Sign(nil, hashed)
+
+	assertNil(t, err)
+	_, ok := alicePrivateKey.PublicKey.Verify(hashed, sig)
+	assertEquals(t, ok, true)
+}
+
+func Test_AgentSigner_Sign_returnsTheErrorOfTheAgent(t *testing.T) {
+	s := startAgent(t, dynamicSigner{&alicePrivateKey.PublicKey, func(io.Reader, []byte) ([]byte, error) {
+		return nil, errors.New("key is locked")
+	}})
+	defer s.Close()
+
+	_, err := s.Sign(nil, []byte("hello"))
+
+	assertDeepEquals(t, err, newOtrError("signing agent: key is locked"))
+}
+
+func Test_NewAgentSigner_failsOnAnInvalidResponse(t *testing.T) {
+	client, server := net.Pipe()
+	go func() {
+		readAgentFrame(server)
+		writeAgentFrame(server, []byte{agentStatusOK, 0x01, 0x02})
+	}()
+
+	_, err := NewAgentSigner(client)
+
+	assertEquals(t, err, errAgentProtocol)
+}
+
+func Test_readAgentFrame_rejectsFramesThatAreTooLarge(t *testing.T) {
+	client, server := net.Pipe()
+	go server.Write(appendWord(nil, agentMaxFrameSize+1))
+
+	_, err := readAgentFrame(client)
+
+	assertEquals(t, err, errAgentFrameTooLarge)
+}
+
+func Test_AKE_withAnAgentSignerOverAUnixSocket(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "otr3-agent")
+	defer os.RemoveAll(dir)
+
+	l, err := net.Listen("unix", filepath.Join(dir, "agent.sock"))
+	assertNil(t, err)
+	defer l.Close()
+	go ServeSignerAgent(l, alicePrivateKey, rand.Reader)
+
+	s, err := DialAgentSigner("unix", filepath.Join(dir, "agent.sock"))
+	assertNil(t, err)
+	defer s.Close()
+
+	alice, bob := newAKEConversation(nil), newAKEConversation(bobPrivateKey)
+	alice.SetSigner(s)
+	performAKE(alice, bob)
+
+	assertEquals(t, alice.IsEncrypted(), true)
+	assertEquals(t, bob.IsEncrypted(), true)
+	assertDeepEquals(t, *bob.GetTheirKey(), alicePrivateKey.PublicKey)
+}
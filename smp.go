@@ -3,30 +3,60 @@ package otr3
 import (
 	"crypto/sha256"
 	"math/big"
+	"time"
+
+	"github.com/twstrike/otr3/smp"
 )
 
-type smp struct {
+type smpContext struct {
 	state    smpState
 	question *string
-	secret   *big.Int
+	secret   *secretInt
 	s1       *smp1State
 	s2       *smp2State
 	s3       *smp3State
+
+	lastActivity time.Time
 }
 
 const smpVersion = 1
 
-func (s *smp) wipe() {
+// smpGroup computes the SMP messages in the OTR group, using the arithmetic backend for secret exponents
+var smpGroup *smp.Group
+
+// versionSMPGroup returns the SMP group that checks received group elements the way the protocol version does
+func (c *Conversation) versionSMPGroup() *smp.Group {
+	g := *smpGroup
+	g.IsElement = c.version.isGroupElement
+	return &g
+}
+
+// smpVerifyError turns an invalid message reported by the smp package into an OTR error
+func smpVerifyError(err error) error {
+	if e, ok := err.(smp.InvalidMessageError); ok {
+		return newOtrError(string(e))
+	}
+	return err
+}
+
+func (s *smpContext) wipe() {
 	s.state = nil
 	s.question = nil
-	wipeBigInt(s.secret)
+	s.secret.destroy()
 	s.secret = nil
 	s.s1 = nil
 	s.s2 = nil
 	s.s3 = nil
 }
 
-func (s *smp) ensureSMP() {
+// setSecret moves the secret into secure memory and wipes v
+func (s *smpContext) setSecret(v *big.Int) {
+	s.secret.destroy()
+	s.secret = newSecretInt(v)
+	wipeBigInt(v)
+}
+
+func (s *smpContext) ensureSMP() {
 	if s.state != nil {
 		return
 	}
@@ -52,50 +82,6 @@ func generateSMPSecret(initiatorFingerprint, recipientFingerprint, ssid, secret
 	return new(big.Int).SetBytes(h.Sum(nil))
 }
 
-func generateDZKP(r, a, c *big.Int) *big.Int {
-	return subMod(r, mul(a, c), q)
-}
-
-func generateZKP(r, a *big.Int, ix byte) (c, d *big.Int) {
-	c = hashMPIsBN(nil, ix, modExp(g1, r))
-	d = generateDZKP(r, a, c)
-	return
-}
-
-func verifyZKP(d, gen, c *big.Int, ix byte) bool {
-	r := modExp(g1, d)
-	s := modExp(gen, c)
-	t := hashMPIsBN(nil, ix, mulMod(r, s, p))
-	return eq(c, t)
-}
-
-func verifyZKP2(g2, g3, d5, d6, pb, qb, cp *big.Int, ix byte) bool {
-	l := mulMod(
-		modExp(g3, d5),
-		modExp(pb, cp),
-		p)
-	r := mulMod(mul(modExp(g1, d5),
-		modExp(g2, d6)),
-		modExp(qb, cp),
-		p)
-	t := hashMPIsBN(nil, ix, l, r)
-	return eq(cp, t)
-}
-
-func verifyZKP3(cp, g2, g3, d5, d6, pa, qa *big.Int, ix byte) bool {
-	l := mulMod(modExp(g3, d5), modExp(pa, cp), p)
-	r := mulMod(mul(modExp(g1, d5), modExp(g2, d6)), modExp(qa, cp), p)
-	t := hashMPIsBN(nil, ix, l, r)
-	return eq(cp, t)
-}
-
-func verifyZKP4(cr, g3a, d7, qaqb, ra *big.Int, ix byte) bool {
-	l := mulMod(modExp(g1, d7), modExp(g3a, cr), p)
-	r := mulMod(modExp(qaqb, d7), modExp(ra, cr), p)
-	t := hashMPIsBN(nil, ix, l, r)
-	return eq(cr, t)
-}
-
 func genSMPTLV(tp uint16, mpis ...*big.Int) tlv {
 	data := make([]byte, 0, 1000)
 
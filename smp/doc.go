@@ -0,0 +1,7 @@
+// Package smp implements the socialist millionaires protocol as used by OTR, independent of OTR sessions.
+// Two parties can use it to find out whether they share the same secret without revealing anything else
+// about the secret, for example to pair devices over an untrusted channel.
+//
+// Engine runs a complete exchange. The Group methods compute and verify the individual messages, for protocols
+// like OTR that handle randomness, state and encoding themselves.
+package smp
@@ -0,0 +1,254 @@
+package smp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	// ErrAborted is returned when the peer aborts the exchange
+	ErrAborted = errors.New("smp: exchange aborted by peer")
+	// ErrCheated is returned when the peer sends a message with invalid values or proofs
+	ErrCheated = errors.New("smp: peer sent an invalid message")
+	// ErrUnexpectedMessage is returned when a message arrives that doesn't fit the state of the exchange
+	ErrUnexpectedMessage = errors.New("smp: unexpected message")
+	// ErrAlreadyStarted is returned when trying to start an exchange that is already running
+	ErrAlreadyStarted = errors.New("smp: exchange already started")
+)
+
+type state int
+
+const (
+	stateExpect1 state = iota
+	stateExpect2
+	stateExpect3
+	stateExpect4
+	stateDone
+)
+
+// Engine executes one side of the socialist millionaires protocol. Each Engine can be used for one exchange.
+// It is not safe for concurrent use
+type Engine struct {
+	group  *Group
+	rand   io.Reader
+	secret *big.Int
+	state  state
+	match  bool
+
+	e1        Exponents1
+	b3        *big.Int
+	responder ResponderState
+	initiator InitiatorState
+}
+
+// NewEngine creates an Engine that will compare the given secret with the peer.
+// The secret should include everything both sides agree on, such as identifiers of both parties,
+// so that an exchange can't be replayed in another context. If group is nil the OTR group is used,
+// and if random is nil crypto/rand is used
+func NewEngine(secret []byte, group *Group, random io.Reader) *Engine {
+	if group == nil {
+		group = OTRGroup()
+	}
+
+	if random == nil {
+		random = rand.Reader
+	}
+
+	h := sha256.Sum256(secret)
+	return &Engine{
+		group:  group,
+		rand:   random,
+		secret: new(big.Int).SetBytes(h[:]),
+	}
+}
+
+// Done returns true when the exchange has finished
+func (e *Engine) Done() bool {
+	return e.state == stateDone
+}
+
+// Match returns true if the exchange has finished and both sides had the same secret
+func (e *Engine) Match() bool {
+	return e.state == stateDone && e.match
+}
+
+// Start generates the first message of the exchange. Only the initiator calls Start
+func (e *Engine) Start() (Message, error) {
+	if e.state != stateExpect1 {
+		return Message{}, ErrAlreadyStarted
+	}
+
+	var err error
+	e.e1.A2, e.e1.A3, e.e1.R2, e.e1.R3, err = e.randomExponents4()
+	if err != nil {
+		return Message{}, err
+	}
+
+	e.state = stateExpect2
+	return Message{Step1, e.group.GenerateMessage1(e.e1).Values()}, nil
+}
+
+// Abort resets the exchange and returns the message that tells the peer about it
+func (e *Engine) Abort() Message {
+	e.reset()
+	return Message{Step: StepAbort}
+}
+
+// Receive processes a message from the peer and returns the reply to send, if any.
+// After an error the exchange is reset, and the peer should be sent an abort message
+func (e *Engine) Receive(m Message) (*Message, error) {
+	if !m.valid() {
+		e.reset()
+		return nil, errMalformedMessage
+	}
+
+	var reply *Message
+	var err error
+
+	switch {
+	case m.Step == StepAbort:
+		err = ErrAborted
+	case m.Step == Step1 && e.state == stateExpect1:
+		reply, err = e.receive1(m.Values)
+	case m.Step == Step2 && e.state == stateExpect2:
+		reply, err = e.receive2(m.Values)
+	case m.Step == Step3 && e.state == stateExpect3:
+		reply, err = e.receive3(m.Values)
+	case m.Step == Step4 && e.state == stateExpect4:
+		err = e.receive4(m.Values)
+	default:
+		err = ErrUnexpectedMessage
+	}
+
+	if err != nil {
+		e.reset()
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (e *Engine) reset() {
+	*e = Engine{group: e.group, rand: e.rand, secret: e.secret}
+}
+
+func (e *Engine) receive1(v []*big.Int) (*Message, error) {
+	m1 := Message1{G2a: v[0], C2: v[1], D2: v[2], G3a: v[3], C3: v[4], D3: v[5]}
+	if e.group.VerifyMessage1(m1) != nil {
+		return nil, ErrCheated
+	}
+
+	var e2 Exponents2
+	var err error
+	if e2.B2, e2.B3, e2.R2, e2.R3, err = e.randomExponents4(); err != nil {
+		return nil, err
+	}
+	if e2.R4, e2.R5, e2.R6, err = e.randomExponents3(); err != nil {
+		return nil, err
+	}
+
+	var m2 Message2
+	m2, e.responder = e.group.GenerateMessage2(e2, e.secret, m1)
+	e.b3 = e2.B3
+
+	e.state = stateExpect3
+	return &Message{Step2, m2.Values()}, nil
+}
+
+func (e *Engine) receive2(v []*big.Int) (*Message, error) {
+	m2 := Message2{G2b: v[0], C2: v[1], D2: v[2], G3b: v[3], C3: v[4], D3: v[5], Pb: v[6], Qb: v[7], Cp: v[8], D5: v[9], D6: v[10]}
+	if e.group.VerifyMessage2(e.e1, m2) != nil {
+		return nil, ErrCheated
+	}
+
+	var e3 Exponents3
+	var err error
+	if e3.R4, e3.R5, e3.R6, e3.R7, err = e.randomExponents4(); err != nil {
+		return nil, err
+	}
+
+	var m3 Message3
+	m3, e.initiator = e.group.GenerateMessage3(e3, e.secret, e.e1, m2)
+
+	e.state = stateExpect4
+	return &Message{Step3, m3.Values()}, nil
+}
+
+func (e *Engine) receive3(v []*big.Int) (*Message, error) {
+	m3 := Message3{Pa: v[0], Qa: v[1], Cp: v[2], D5: v[3], D6: v[4], Ra: v[5], Cr: v[6], D7: v[7]}
+	if e.group.VerifyMessage3(e.responder, m3) != nil {
+		return nil, ErrCheated
+	}
+
+	r7, err := randomExponent(e.rand, e.group.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	m4 := e.group.GenerateMessage4(r7, e.b3, e.responder, m3)
+
+	e.match = e.group.ResponderMatches(e.b3, e.responder, m3)
+	e.state = stateDone
+	return &Message{Step4, m4.Values()}, nil
+}
+
+func (e *Engine) receive4(v []*big.Int) error {
+	m4 := Message4{Rb: v[0], Cr: v[1], D7: v[2]}
+	if e.group.VerifyMessage4(e.initiator, m4) != nil {
+		return ErrCheated
+	}
+
+	e.match = e.group.InitiatorMatches(e.e1.A3, e.initiator, m4)
+	e.state = stateDone
+	return nil
+}
+
+func (e *Engine) randomExponents3() (a, b, c *big.Int, err error) {
+	var err1, err2, err3 error
+	a, err1 = randomExponent(e.rand, e.group.Q)
+	b, err2 = randomExponent(e.rand, e.group.Q)
+	c, err3 = randomExponent(e.rand, e.group.Q)
+	return a, b, c, firstError(err1, err2, err3)
+}
+
+func (e *Engine) randomExponents4() (a, b, c, d *big.Int, err error) {
+	a, b, c, err = e.randomExponents3()
+	if err != nil {
+		return
+	}
+	d, err = randomExponent(e.rand, e.group.Q)
+	return
+}
+
+func randomExponent(r io.Reader, q *big.Int) (*big.Int, error) {
+	for {
+		n, err := rand.Int(r, q)
+		if err != nil {
+			return nil, err
+		}
+		if n.Sign() > 0 {
+			return n, nil
+		}
+	}
+}
+
+func hashValues(ix byte, values ...*big.Int) *big.Int {
+	h := sha256.New()
+	h.Write([]byte{ix})
+	for _, v := range values {
+		h.Write(appendMPI(nil, v))
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+func firstError(es ...error) error {
+	for _, e := range es {
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package smp
+
+import (
+	"math/big"
+	"net"
+	"testing"
+)
+
+func exchange(alice, bob *Engine) error {
+	m, err := alice.Start()
+	if err != nil {
+		return err
+	}
+
+	current, other := bob, alice
+	reply := &m
+	for reply != nil {
+		reply, err = current.Receive(*reply)
+		if err != nil {
+			return err
+		}
+		current, other = other, current
+	}
+	return nil
+}
+
+func Test_Engine_matchesWhenBothSidesHaveTheSameSecret(t *testing.T) {
+	alice, bob := NewEngine([]byte("pairing code 1234"), nil, nil), NewEngine([]byte("pairing code 1234"), nil, nil)
+
+	assertEquals(t, exchange(alice, bob), nil)
+
+	assertEquals(t, alice.Done(), true)
+	assertEquals(t, bob.Done(), true)
+	assertEquals(t, alice.Match(), true)
+	assertEquals(t, bob.Match(), true)
+}
+
+func Test_Engine_doesNotMatchWhenTheSecretsDiffer(t *testing.T) {
+	alice, bob := NewEngine([]byte("pairing code 1234"), nil, nil), NewEngine([]byte("pairing code 4321"), nil, nil)
+
+	assertEquals(t, exchange(alice, bob), nil)
+
+	assertEquals(t, alice.Done(), true)
+	assertEquals(t, bob.Done(), true)
+	assertEquals(t, alice.Match(), false)
+	assertEquals(t, bob.Match(), false)
+}
+
+func Test_Engine_worksWithOtherGroups(t *testing.T) {
+	// A 64-bit safe prime keeps the test fast, while making it unlikely that random values collide
+	p, _ := new(big.Int).SetString("18446744073709550147", 10)
+	q, _ := new(big.Int).SetString("9223372036854775073", 10)
+	small := &Group{P: p, Q: q, G: big.NewInt(4)}
+	alice, bob := NewEngine([]byte("secret"), small, nil), NewEngine([]byte("secret"), small, nil)
+
+	assertEquals(t, exchange(alice, bob), nil)
+	assertEquals(t, alice.Match(), true)
+	assertEquals(t, bob.Match(), true)
+}
+
+func Test_Engine_detectsInvalidProofs(t *testing.T) {
+	alice, bob := NewEngine([]byte("secret"), nil, nil), NewEngine([]byte("secret"), nil, nil)
+
+	m, _ := alice.Start()
+	m.Values[1] = new(big.Int).Add(m.Values[1], big.NewInt(1))
+	_, err := bob.Receive(m)
+
+	assertEquals(t, err, ErrCheated)
+	assertEquals(t, bob.Done(), false)
+}
+
+func Test_Engine_detectsValuesOutsideTheGroup(t *testing.T) {
+	alice, bob := NewEngine([]byte("secret"), nil, nil), NewEngine([]byte("secret"), nil, nil)
+
+	m, _ := alice.Start()
+	m.Values[0] = big.NewInt(1)
+	_, err := bob.Receive(m)
+
+	assertEquals(t, err, ErrCheated)
+}
+
+func Test_Engine_rejectsUnexpectedMessages(t *testing.T) {
+	alice := NewEngine([]byte("secret"), nil, nil)
+	m, _ := alice.Start()
+
+	_, err := alice.Receive(m)
+	assertEquals(t, err, ErrUnexpectedMessage)
+
+	_, err = alice.Start()
+	assertEquals(t, err, nil)
+}
+
+func Test_Engine_reportsAborts(t *testing.T) {
+	alice, bob := NewEngine([]byte("secret"), nil, nil), NewEngine([]byte("secret"), nil, nil)
+	m, _ := alice.Start()
+	bob.Receive(m)
+
+	_, err := alice.Receive(bob.Abort())
+	assertEquals(t, err, ErrAborted)
+}
+
+func Test_Engine_cantBeStartedTwice(t *testing.T) {
+	alice := NewEngine([]byte("secret"), nil, nil)
+	alice.Start()
+	_, err := alice.Start()
+	assertEquals(t, err, ErrAlreadyStarted)
+}
+
+func Test_Run_executesTheExchangeOverAStream(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	type result struct {
+		match bool
+		err   error
+	}
+	bobResult := make(chan result)
+	go func() {
+		match, err := Run(NewEngine([]byte("secret"), nil, nil), NewStreamTransport(c2), false)
+		bobResult <- result{match, err}
+	}()
+
+	match, err := Run(NewEngine([]byte("secret"), nil, nil), NewStreamTransport(c1), true)
+	assertEquals(t, err, nil)
+	assertEquals(t, match, true)
+
+	r := <-bobResult
+	assertEquals(t, r.err, nil)
+	assertEquals(t, r.match, true)
+}
+
+func Test_Engine_rejectsMessagesWithMissingValues(t *testing.T) {
+	alice, bob := NewEngine([]byte("secret"), nil, nil), NewEngine([]byte("secret"), nil, nil)
+
+	m, _ := alice.Start()
+	m.Values[3] = nil
+	_, err := bob.Receive(m)
+
+	assertEquals(t, err, errMalformedMessage)
+	assertEquals(t, bob.Done(), false)
+}
+
+func Test_Engine_rejectsMessagesWithNegativeValues(t *testing.T) {
+	alice, bob := NewEngine([]byte("secret"), nil, nil), NewEngine([]byte("secret"), nil, nil)
+
+	m, _ := alice.Start()
+	m.Values[2] = big.NewInt(-1)
+	_, err := bob.Receive(m)
+
+	assertEquals(t, err, errMalformedMessage)
+}
+
+func Test_Engine_usesTheSecretExponentiationOfTheGroup(t *testing.T) {
+	calls := 0
+	group := *OTRGroup()
+	group.SecretExp = func(base, exponent *big.Int) *big.Int {
+		calls++
+		return new(big.Int).Exp(base, exponent, group.P)
+	}
+	alice, bob := NewEngine([]byte("secret"), &group, nil), NewEngine([]byte("secret"), &group, nil)
+
+	assertEquals(t, exchange(alice, bob), nil)
+	assertEquals(t, alice.Match(), true)
+	assertEquals(t, calls > 0, true)
+}
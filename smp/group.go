@@ -0,0 +1,107 @@
+package smp
+
+import "math/big"
+
+// Group contains the parameters of the prime order group the protocol is executed in.
+// G has to generate a subgroup of order Q of the multiplicative group modulo P
+type Group struct {
+	P *big.Int
+	Q *big.Int
+	G *big.Int
+
+	// SecretExp, if set, computes the exponentiations modulo P that use secret exponents, for example
+	// in constant time. Exponentiations with public exponents always use math/big
+	SecretExp func(base, exponent *big.Int) *big.Int
+
+	// IsElement, if set, replaces the check done on the group elements of received messages. By default
+	// they have to be between 2 and P-2 and members of the subgroup generated by G
+	IsElement func(n *big.Int) bool
+}
+
+var otrGroup = Group{
+	P: hexInt("FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1" +
+		"29024E088A67CC74020BBEA63B139B22514A08798E3404DD" +
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245" +
+		"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED" +
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D" +
+		"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F" +
+		"83655D23DCA3AD961C62F356208552BB9ED529077096966D" +
+		"670C354E4ABC9804F1746C08CA237327FFFFFFFFFFFFFFFF"),
+	Q: hexInt("7FFFFFFFFFFFFFFFE487ED5110B4611A62633145C06E0E68" +
+		"948127044533E63A0105DF531D89CD9128A5043CC71A026E" +
+		"F7CA8CD9E69D218D98158536F92F8A1BA7F09AB6B6A8E122" +
+		"F242DABB312F3F637A262174D31BF6B585FFAE5B7A035BF6" +
+		"F71C35FDAD44CFD2D74F9208BE258FF324943328F6722D9E" +
+		"E1003E5C50B1DF82CC6D241B0E2AE9CD348B1FD47E9267AF" +
+		"C1B2AE91EE51D6CB0E3179AB1042A95DCF6A9483B84B4B36" +
+		"B3861AA7255E4C0278BA36046511B993FFFFFFFFFFFFFFFF"),
+	G: big.NewInt(2),
+}
+
+// OTRGroup returns a new copy of the 1536-bit group from RFC 3526 that OTR uses for SMP
+func OTRGroup() *Group {
+	return &Group{
+		P: new(big.Int).Set(otrGroup.P),
+		Q: new(big.Int).Set(otrGroup.Q),
+		G: new(big.Int).Set(otrGroup.G),
+	}
+}
+
+func hexInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("smp: invalid group constant")
+	}
+	return n
+}
+
+func (g *Group) exp(base, x *big.Int) *big.Int {
+	return new(big.Int).Exp(base, x, g.P)
+}
+
+func (g *Group) generate(x *big.Int) *big.Int {
+	return g.exp(g.G, x)
+}
+
+func (g *Group) expSecret(base, x *big.Int) *big.Int {
+	if g.SecretExp != nil {
+		return g.SecretExp(base, x)
+	}
+	return g.exp(base, x)
+}
+
+func (g *Group) generateSecret(x *big.Int) *big.Int {
+	return g.expSecret(g.G, x)
+}
+
+func (g *Group) mul(l, r *big.Int) *big.Int {
+	ret := new(big.Int).Mul(l, r)
+	return ret.Mod(ret, g.P)
+}
+
+func (g *Group) div(l, r *big.Int) *big.Int {
+	return g.mul(l, new(big.Int).ModInverse(r, g.P))
+}
+
+// subExponent returns r - a*c mod Q
+func (g *Group) subExponent(r, a, c *big.Int) *big.Int {
+	ret := new(big.Int).Mul(a, c)
+	ret.Sub(r, ret)
+	return ret.Mod(ret, g.Q)
+}
+
+// isElement returns true if n is a member of the subgroup generated by G, excluding the trivial elements
+func (g *Group) isElement(n *big.Int) bool {
+	if n == nil {
+		return false
+	}
+
+	if g.IsElement != nil {
+		return g.IsElement(n)
+	}
+
+	if n.Cmp(big.NewInt(2)) < 0 || n.Cmp(new(big.Int).Sub(g.P, big.NewInt(2))) > 0 {
+		return false
+	}
+	return g.exp(n, g.Q).Cmp(big.NewInt(1)) == 0
+}
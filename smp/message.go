@@ -0,0 +1,130 @@
+package smp
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// Step identifies an SMP message
+type Step byte
+
+const (
+	// StepAbort is sent to abort a running exchange
+	StepAbort Step = iota
+	// Step1 is sent by the initiator to start the exchange
+	Step1
+	// Step2 is the reply of the responder to Step1
+	Step2
+	// Step3 is the reply of the initiator to Step2
+	Step3
+	// Step4 is the final message, sent by the responder
+	Step4
+)
+
+var valuesForStep = map[Step]int{
+	StepAbort: 0,
+	Step1:     6,
+	Step2:     11,
+	Step3:     8,
+	Step4:     3,
+}
+
+// String returns the string representation of the Step
+func (s Step) String() string {
+	switch s {
+	case StepAbort:
+		return "StepAbort"
+	case Step1:
+		return "Step1"
+	case Step2:
+		return "Step2"
+	case Step3:
+		return "Step3"
+	case Step4:
+		return "Step4"
+	default:
+		return "STEP: (THIS SHOULD NEVER HAPPEN)"
+	}
+}
+
+// Message is one message of the SMP exchange. The values are in the same order as in the OTR SMP TLVs,
+// and can't be nil or negative
+type Message struct {
+	Step   Step
+	Values []*big.Int
+}
+
+var errMalformedMessage = errors.New("smp: malformed message")
+
+func (m Message) valid() bool {
+	n, ok := valuesForStep[m.Step]
+	if !ok || n != len(m.Values) {
+		return false
+	}
+
+	for _, v := range m.Values {
+		if v == nil || v.Sign() < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary serializes the message as the step, followed by the number of values and the values as OTR MPIs
+func (m Message) MarshalBinary() ([]byte, error) {
+	if !m.valid() {
+		return nil, errMalformedMessage
+	}
+
+	out := []byte{byte(m.Step)}
+	out = appendWord(out, uint32(len(m.Values)))
+	for _, v := range m.Values {
+		out = appendMPI(out, v)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary parses a message serialized with MarshalBinary
+func (m *Message) UnmarshalBinary(data []byte) error {
+	if len(data) < 5 {
+		return errMalformedMessage
+	}
+
+	step := Step(data[0])
+	count := binary.BigEndian.Uint32(data[1:5])
+	if expected, ok := valuesForStep[step]; !ok || uint32(expected) != count {
+		return errMalformedMessage
+	}
+
+	data = data[5:]
+	values := make([]*big.Int, 0, count)
+	for i := uint32(0); i < count; i++ {
+		if len(data) < 4 {
+			return errMalformedMessage
+		}
+		l := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint32(len(data)) < l {
+			return errMalformedMessage
+		}
+		values = append(values, new(big.Int).SetBytes(data[:l]))
+		data = data[l:]
+	}
+
+	if len(data) != 0 {
+		return errMalformedMessage
+	}
+
+	m.Step, m.Values = step, values
+	return nil
+}
+
+func appendWord(l []byte, r uint32) []byte {
+	return append(l, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+}
+
+func appendMPI(l []byte, r *big.Int) []byte {
+	b := r.Bytes()
+	return append(appendWord(l, uint32(len(b))), b...)
+}
@@ -0,0 +1,53 @@
+package smp
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_Step_hasValidStringImplementation(t *testing.T) {
+	assertEquals(t, StepAbort.String(), "StepAbort")
+	assertEquals(t, Step1.String(), "Step1")
+	assertEquals(t, Step2.String(), "Step2")
+	assertEquals(t, Step3.String(), "Step3")
+	assertEquals(t, Step4.String(), "Step4")
+	assertEquals(t, Step(200).String(), "STEP: (THIS SHOULD NEVER HAPPEN)")
+}
+
+func Test_Message_roundTripsThroughTheBinaryFormat(t *testing.T) {
+	m := Message{Step4, []*big.Int{big.NewInt(1), big.NewInt(0x0102), big.NewInt(0)}}
+
+	data, err := m.MarshalBinary()
+	assertEquals(t, err, nil)
+	assertDeepEquals(t, data, []byte{
+		0x04,
+		0x00, 0x00, 0x00, 0x03,
+		0x00, 0x00, 0x00, 0x01, 0x01,
+		0x00, 0x00, 0x00, 0x02, 0x01, 0x02,
+		0x00, 0x00, 0x00, 0x00,
+	})
+
+	var parsed Message
+	assertEquals(t, parsed.UnmarshalBinary(data), nil)
+	assertEquals(t, parsed.Step, Step4)
+	assertEquals(t, parsed.Values[1].Cmp(big.NewInt(0x0102)), 0)
+}
+
+func Test_Message_rejectsTheWrongNumberOfValues(t *testing.T) {
+	_, err := Message{Step1, []*big.Int{big.NewInt(1)}}.MarshalBinary()
+	assertEquals(t, err, errMalformedMessage)
+
+	var m Message
+	assertEquals(t, m.UnmarshalBinary([]byte{0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}), errMalformedMessage)
+}
+
+func Test_Message_rejectsTruncatedData(t *testing.T) {
+	var m Message
+	assertEquals(t, m.UnmarshalBinary([]byte{0x04, 0x00, 0x00}), errMalformedMessage)
+	assertEquals(t, m.UnmarshalBinary([]byte{0x04, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x05, 0x01}), errMalformedMessage)
+}
+
+func Test_Message_MarshalBinary_rejectsMissingValues(t *testing.T) {
+	_, err := Message{Step4, []*big.Int{big.NewInt(1), nil, big.NewInt(2)}}.MarshalBinary()
+	assertEquals(t, err, errMalformedMessage)
+}
@@ -0,0 +1,334 @@
+package smp
+
+import (
+	"errors"
+	"math/big"
+)
+
+// The functions in this file compute and check the individual messages of the exchange. They keep no state between
+// messages and take the random exponents from the caller, so that a protocol like OTR can drive the exchange with its
+// own randomness, state machine and encoding. Engine is built on top of them.
+
+// Exponents1 contains the random exponents the initiator picks for the first message
+type Exponents1 struct {
+	A2, A3 *big.Int
+	R2, R3 *big.Int
+}
+
+// Message1 contains the values of the first message, sent by the initiator
+type Message1 struct {
+	G2a, G3a *big.Int
+	C2, C3   *big.Int
+	D2, D3   *big.Int
+}
+
+// Values returns the values of the message in the order they are sent in
+func (m Message1) Values() []*big.Int {
+	return []*big.Int{m.G2a, m.C2, m.D2, m.G3a, m.C3, m.D3}
+}
+
+// Exponents2 contains the random exponents the responder picks for the second message
+type Exponents2 struct {
+	B2, B3     *big.Int
+	R2, R3, R4 *big.Int
+	R5, R6     *big.Int
+}
+
+// Message2 contains the values of the second message, sent by the responder
+type Message2 struct {
+	G2b, G3b *big.Int
+	C2, C3   *big.Int
+	D2, D3   *big.Int
+	Pb, Qb   *big.Int
+	Cp       *big.Int
+	D5, D6   *big.Int
+}
+
+// Values returns the values of the message in the order they are sent in
+func (m Message2) Values() []*big.Int {
+	return []*big.Int{m.G2b, m.C2, m.D2, m.G3b, m.C3, m.D3, m.Pb, m.Qb, m.Cp, m.D5, m.D6}
+}
+
+// ResponderState contains the values the responder needs to remember between the second and the fourth message
+type ResponderState struct {
+	G2, G3 *big.Int
+	G3a    *big.Int
+	Pb, Qb *big.Int
+}
+
+// Exponents3 contains the random exponents the initiator picks for the third message
+type Exponents3 struct {
+	R4, R5, R6, R7 *big.Int
+}
+
+// Message3 contains the values of the third message, sent by the initiator
+type Message3 struct {
+	Pa, Qa     *big.Int
+	Cp         *big.Int
+	D5, D6, D7 *big.Int
+	Ra         *big.Int
+	Cr         *big.Int
+}
+
+// Values returns the values of the message in the order they are sent in
+func (m Message3) Values() []*big.Int {
+	return []*big.Int{m.Pa, m.Qa, m.Cp, m.D5, m.D6, m.Ra, m.Cr, m.D7}
+}
+
+// InitiatorState contains the values the initiator needs to remember between the third and the fourth message
+type InitiatorState struct {
+	G3b        *big.Int
+	Qaqb, Papb *big.Int
+}
+
+// Message4 contains the values of the fourth message, sent by the responder
+type Message4 struct {
+	Rb *big.Int
+	Cr *big.Int
+	D7 *big.Int
+}
+
+// Values returns the values of the message in the order they are sent in
+func (m Message4) Values() []*big.Int {
+	return []*big.Int{m.Rb, m.Cr, m.D7}
+}
+
+// InvalidMessageError is returned by the Verify methods when a message contains an invalid or missing value or proof
+type InvalidMessageError string
+
+func (e InvalidMessageError) Error() string {
+	return "smp: " + string(e)
+}
+
+var errMissingState = errors.New("smp: missing value in the state of the exchange")
+
+func missing(values ...*big.Int) bool {
+	for _, v := range values {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateMessage1 computes the first message
+func (g *Group) GenerateMessage1(e Exponents1) (m Message1) {
+	m.G2a = g.generateSecret(e.A2)
+	m.G3a = g.generateSecret(e.A3)
+	m.C2, m.D2 = g.proveKnowledge(e.R2, e.A2, 1)
+	m.C3, m.D3 = g.proveKnowledge(e.R3, e.A3, 2)
+	return
+}
+
+// VerifyMessage1 checks the values and proofs of the first message
+func (g *Group) VerifyMessage1(m Message1) error {
+	if !g.isElement(m.G2a) {
+		return InvalidMessageError("g2a is an invalid group element")
+	}
+
+	if !g.isElement(m.G3a) {
+		return InvalidMessageError("g3a is an invalid group element")
+	}
+
+	if !g.verifyKnowledge(m.D2, m.G2a, m.C2, 1) {
+		return InvalidMessageError("c2 is not a valid zero knowledge proof")
+	}
+
+	if !g.verifyKnowledge(m.D3, m.G3a, m.C3, 2) {
+		return InvalidMessageError("c3 is not a valid zero knowledge proof")
+	}
+
+	return nil
+}
+
+// GenerateMessage2 computes the reply to a verified first message, using the hashed secret of the responder
+func (g *Group) GenerateMessage2(e Exponents2, secret *big.Int, m1 Message1) (m Message2, s ResponderState) {
+	m.G2b = g.generateSecret(e.B2)
+	m.G3b = g.generateSecret(e.B3)
+
+	m.C2, m.D2 = g.proveKnowledge(e.R2, e.B2, 3)
+	m.C3, m.D3 = g.proveKnowledge(e.R3, e.B3, 4)
+
+	s.G3a = m1.G3a
+	s.G2 = g.expSecret(m1.G2a, e.B2)
+	s.G3 = g.expSecret(m1.G3a, e.B3)
+
+	s.Pb = g.expSecret(s.G3, e.R4)
+	s.Qb = g.mul(g.generateSecret(e.R4), g.expSecret(s.G2, secret))
+
+	m.Pb = s.Pb
+	m.Qb = s.Qb
+
+	m.Cp = hashValues(5,
+		g.expSecret(s.G3, e.R5),
+		g.mul(g.generateSecret(e.R5), g.expSecret(s.G2, e.R6)))
+
+	m.D5 = g.subExponent(e.R5, e.R4, m.Cp)
+	m.D6 = g.subExponent(e.R6, secret, m.Cp)
+
+	return
+}
+
+// VerifyMessage2 checks the values and proofs of the second message, using the exponents of the first message
+func (g *Group) VerifyMessage2(e Exponents1, m Message2) error {
+	if missing(e.A2, e.A3) {
+		return errMissingState
+	}
+
+	if !g.isElement(m.G2b) {
+		return InvalidMessageError("g2b is an invalid group element")
+	}
+
+	if !g.isElement(m.G3b) {
+		return InvalidMessageError("g3b is an invalid group element")
+	}
+
+	if !g.isElement(m.Pb) {
+		return InvalidMessageError("Pb is an invalid group element")
+	}
+
+	if !g.isElement(m.Qb) {
+		return InvalidMessageError("Qb is an invalid group element")
+	}
+
+	if !g.verifyKnowledge(m.D2, m.G2b, m.C2, 3) {
+		return InvalidMessageError("c2 is not a valid zero knowledge proof")
+	}
+
+	if !g.verifyKnowledge(m.D3, m.G3b, m.C3, 4) {
+		return InvalidMessageError("c3 is not a valid zero knowledge proof")
+	}
+
+	g2 := g.expSecret(m.G2b, e.A2)
+	g3 := g.expSecret(m.G3b, e.A3)
+
+	if !g.verifyCoordinates(g2, g3, m.Cp, m.D5, m.D6, m.Pb, m.Qb, 5) {
+		return InvalidMessageError("cP is not a valid zero knowledge proof")
+	}
+
+	return nil
+}
+
+// GenerateMessage3 computes the reply to a verified second message, using the hashed secret of the initiator
+func (g *Group) GenerateMessage3(e Exponents3, secret *big.Int, e1 Exponents1, m2 Message2) (m Message3, s InitiatorState) {
+	g2 := g.expSecret(m2.G2b, e1.A2)
+	g3 := g.expSecret(m2.G3b, e1.A3)
+
+	m.Pa = g.expSecret(g3, e.R4)
+	m.Qa = g.mul(g.generateSecret(e.R4), g.expSecret(g2, secret))
+
+	s.G3b = m2.G3b
+	s.Qaqb = g.div(m.Qa, m2.Qb)
+	s.Papb = g.div(m.Pa, m2.Pb)
+
+	m.Cp = hashValues(6, g.expSecret(g3, e.R5), g.mul(g.generateSecret(e.R5), g.expSecret(g2, e.R6)))
+	m.D5 = g.subExponent(e.R5, e.R4, m.Cp)
+	m.D6 = g.subExponent(e.R6, secret, m.Cp)
+
+	m.Ra = g.expSecret(s.Qaqb, e1.A3)
+
+	m.Cr = hashValues(7, g.generateSecret(e.R7), g.expSecret(s.Qaqb, e.R7))
+	m.D7 = g.subExponent(e.R7, e1.A3, m.Cr)
+
+	return
+}
+
+// VerifyMessage3 checks the values and proofs of the third message
+func (g *Group) VerifyMessage3(s ResponderState, m Message3) error {
+	if missing(s.G2, s.G3, s.G3a, s.Qb) {
+		return errMissingState
+	}
+
+	if !g.isElement(m.Pa) {
+		return InvalidMessageError("Pa is an invalid group element")
+	}
+
+	if !g.isElement(m.Qa) {
+		return InvalidMessageError("Qa is an invalid group element")
+	}
+
+	if !g.isElement(m.Ra) {
+		return InvalidMessageError("Ra is an invalid group element")
+	}
+
+	if !g.verifyCoordinates(s.G2, s.G3, m.Cp, m.D5, m.D6, m.Pa, m.Qa, 6) {
+		return InvalidMessageError("cP is not a valid zero knowledge proof")
+	}
+
+	if !g.verifyEquality(m.Cr, s.G3a, m.D7, g.div(m.Qa, s.Qb), m.Ra, 7) {
+		return InvalidMessageError("cR is not a valid zero knowledge proof")
+	}
+
+	return nil
+}
+
+// ResponderMatches returns true if a verified third message shows that both parties have the same secret
+func (g *Group) ResponderMatches(b3 *big.Int, s ResponderState, m3 Message3) bool {
+	return g.expSecret(m3.Ra, b3).Cmp(g.div(m3.Pa, s.Pb)) == 0
+}
+
+// GenerateMessage4 computes the reply to a verified third message
+func (g *Group) GenerateMessage4(r7, b3 *big.Int, s ResponderState, m3 Message3) (m Message4) {
+	qaqb := g.div(m3.Qa, s.Qb)
+
+	m.Rb = g.expSecret(qaqb, b3)
+	m.Cr = hashValues(8, g.generateSecret(r7), g.expSecret(qaqb, r7))
+	m.D7 = g.subExponent(r7, b3, m.Cr)
+
+	return
+}
+
+// VerifyMessage4 checks the values and proofs of the fourth message
+func (g *Group) VerifyMessage4(s InitiatorState, m Message4) error {
+	if missing(s.G3b, s.Qaqb) {
+		return errMissingState
+	}
+
+	if !g.isElement(m.Rb) {
+		return InvalidMessageError("Rb is an invalid group element")
+	}
+
+	if !g.verifyEquality(m.Cr, s.G3b, m.D7, s.Qaqb, m.Rb, 8) {
+		return InvalidMessageError("cR is not a valid zero knowledge proof")
+	}
+
+	return nil
+}
+
+// InitiatorMatches returns true if a verified fourth message shows that both parties have the same secret
+func (g *Group) InitiatorMatches(a3 *big.Int, s InitiatorState, m4 Message4) bool {
+	return g.expSecret(m4.Rb, a3).Cmp(s.Papb) == 0
+}
+
+func (g *Group) proveKnowledge(r, a *big.Int, ix byte) (c, d *big.Int) {
+	c = hashValues(ix, g.generateSecret(r))
+	d = g.subExponent(r, a, c)
+	return
+}
+
+func (g *Group) verifyKnowledge(d, gen, c *big.Int, ix byte) bool {
+	if missing(d, gen, c) {
+		return false
+	}
+	return c.Cmp(hashValues(ix, g.mul(g.generate(d), g.exp(gen, c)))) == 0
+}
+
+func (g *Group) verifyCoordinates(g2, g3, cp, d5, d6, p, q *big.Int, ix byte) bool {
+	if missing(g2, g3, cp, d5, d6, p, q) {
+		return false
+	}
+
+	l := g.mul(g.exp(g3, d5), g.exp(p, cp))
+	r := g.mul(g.mul(g.generate(d5), g.exp(g2, d6)), g.exp(q, cp))
+	return cp.Cmp(hashValues(ix, l, r)) == 0
+}
+
+func (g *Group) verifyEquality(cr, g3, d7, qaqb, r *big.Int, ix byte) bool {
+	if missing(cr, g3, d7, qaqb, r) {
+		return false
+	}
+
+	l := g.mul(g.generate(d7), g.exp(g3, cr))
+	rr := g.mul(g.exp(qaqb, d7), g.exp(r, cr))
+	return cr.Cmp(hashValues(ix, l, rr)) == 0
+}
@@ -0,0 +1,51 @@
+package smp
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_OTRGroup_returnsACopy(t *testing.T) {
+	OTRGroup().P.SetInt64(23)
+	OTRGroup().G.SetInt64(5)
+
+	assertEquals(t, OTRGroup().P.Cmp(otrGroup.P), 0)
+	assertEquals(t, OTRGroup().P.BitLen(), 1536)
+	assertEquals(t, OTRGroup().G.Int64(), int64(2))
+}
+
+func Test_Group_VerifyMessages_returnAnErrorForMissingValues(t *testing.T) {
+	g := OTRGroup()
+	e1 := Exponents1{A2: big.NewInt(3), A3: big.NewInt(5), R2: big.NewInt(7), R3: big.NewInt(11)}
+	m1 := g.GenerateMessage1(e1)
+
+	m1.D3 = nil
+	assertEquals(t, g.VerifyMessage1(m1), InvalidMessageError("c3 is not a valid zero knowledge proof"))
+	assertEquals(t, g.VerifyMessage1(Message1{}), InvalidMessageError("g2a is an invalid group element"))
+	assertEquals(t, g.VerifyMessage2(e1, Message2{}), InvalidMessageError("g2b is an invalid group element"))
+	assertEquals(t, g.VerifyMessage2(Exponents1{}, Message2{}), errMissingState)
+	assertEquals(t, g.VerifyMessage3(ResponderState{}, Message3{}), errMissingState)
+	assertEquals(t, g.VerifyMessage4(InitiatorState{}, Message4{}), errMissingState)
+}
+
+func Test_Group_VerifyMessages_returnAnErrorForMissingProofsWhenAllElementsAreAccepted(t *testing.T) {
+	g := OTRGroup()
+	g.IsElement = func(*big.Int) bool { return true }
+	one := big.NewInt(1)
+
+	assertEquals(t, g.VerifyMessage1(Message1{G2a: one, G3a: one}), InvalidMessageError("c2 is not a valid zero knowledge proof"))
+	assertEquals(t, g.VerifyMessage3(ResponderState{G2: one, G3: one, G3a: one, Qb: one}, Message3{Pa: one, Qa: one, Ra: one}), InvalidMessageError("cP is not a valid zero knowledge proof"))
+	assertEquals(t, g.VerifyMessage4(InitiatorState{G3b: one, Qaqb: one}, Message4{Rb: one}), InvalidMessageError("cR is not a valid zero knowledge proof"))
+}
+
+func Test_Group_IsElement_replacesTheDefaultCheck(t *testing.T) {
+	g := OTRGroup()
+	outsideTheSubgroup := new(big.Int).Sub(g.P, big.NewInt(2))
+	assertEquals(t, g.isElement(big.NewInt(1)), false)
+	assertEquals(t, g.isElement(outsideTheSubgroup), false)
+
+	g.IsElement = func(n *big.Int) bool { return n.Sign() > 0 }
+	assertEquals(t, g.isElement(big.NewInt(1)), true)
+	assertEquals(t, g.isElement(outsideTheSubgroup), true)
+	assertEquals(t, g.isElement(nil), false)
+}
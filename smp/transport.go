@@ -0,0 +1,96 @@
+package smp
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Transport carries SMP messages between the two parties
+type Transport interface {
+	Send(m Message) error
+	Receive() (Message, error)
+}
+
+// Run executes a complete exchange over the transport. The initiator sends the first message.
+// It returns whether both sides had the same secret. If the exchange fails, an abort message is sent to the peer
+func Run(e *Engine, t Transport, initiator bool) (bool, error) {
+	if initiator {
+		m, err := e.Start()
+		if err != nil {
+			return false, err
+		}
+		if err := t.Send(m); err != nil {
+			return false, err
+		}
+	}
+
+	for !e.Done() {
+		m, err := t.Receive()
+		if err != nil {
+			return false, err
+		}
+
+		reply, err := e.Receive(m)
+		if err != nil {
+			if err != ErrAborted {
+				t.Send(e.Abort())
+			}
+			return false, err
+		}
+
+		if reply != nil {
+			if err := t.Send(*reply); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return e.Match(), nil
+}
+
+// maxMessageSize limits the size of messages read from a stream, to avoid allocating arbitrary amounts of memory
+const maxMessageSize = 1 << 16
+
+var errMessageTooLarge = errors.New("smp: message too large")
+
+type streamTransport struct {
+	rw io.ReadWriter
+}
+
+// NewStreamTransport returns a Transport that writes length prefixed messages to the stream
+func NewStreamTransport(rw io.ReadWriter) Transport {
+	return streamTransport{rw}
+}
+
+func (s streamTransport) Send(m Message) error {
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.rw.Write(append(appendWord(nil, uint32(len(data))), data...))
+	return err
+}
+
+func (s streamTransport) Receive() (Message, error) {
+	var m Message
+
+	var l [4]byte
+	if _, err := io.ReadFull(s.rw, l[:]); err != nil {
+		return m, err
+	}
+
+	size := binary.BigEndian.Uint32(l[:])
+	if size > maxMessageSize {
+		return m, errMessageTooLarge
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(s.rw, data); err != nil {
+		return m, err
+	}
+
+	err := m.UnmarshalBinary(data)
+	return m, err
+}
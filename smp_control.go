@@ -0,0 +1,141 @@
+package otr3
+
+import "time"
+
+// SMPProgress describes how far the current SMP exchange has come
+type SMPProgress int
+
+const (
+	// SMPProgressIdle means no SMP exchange is running
+	SMPProgressIdle SMPProgress = iota
+	// SMPProgressWaitingForSecret means the peer has started SMP and we need to provide the secret
+	SMPProgressWaitingForSecret
+	// SMPProgressExpectingMessage2 means we have started SMP and are waiting for the peer to answer
+	SMPProgressExpectingMessage2
+	// SMPProgressExpectingMessage3 means we have answered the peer and are waiting for the third SMP message
+	SMPProgressExpectingMessage3
+	// SMPProgressExpectingMessage4 means we are waiting for the final SMP message from the peer
+	SMPProgressExpectingMessage4
+)
+
+// String returns the string representation of the SMPProgress
+func (p SMPProgress) String() string {
+	switch p {
+	case SMPProgressIdle:
+		return "SMPProgressIdle"
+	case SMPProgressWaitingForSecret:
+		return "SMPProgressWaitingForSecret"
+	case SMPProgressExpectingMessage2:
+		return "SMPProgressExpectingMessage2"
+	case SMPProgressExpectingMessage3:
+		return "SMPProgressExpectingMessage3"
+	case SMPProgressExpectingMessage4:
+		return "SMPProgressExpectingMessage4"
+	default:
+		return "SMP PROGRESS: (THIS SHOULD NEVER HAPPEN)"
+	}
+}
+
+// Percent returns the progress as a percentage, matching the values reported with SMP events
+func (p SMPProgress) Percent() int {
+	switch p {
+	case SMPProgressExpectingMessage2:
+		return 20
+	case SMPProgressWaitingForSecret:
+		return 25
+	case SMPProgressExpectingMessage3:
+		return 40
+	case SMPProgressExpectingMessage4:
+		return 60
+	default:
+		return 0
+	}
+}
+
+// SMPProgress returns the progress of the current SMP exchange
+func (c *Conversation) SMPProgress() SMPProgress {
+	switch c.smp.state.(type) {
+	case smpStateWaitingForSecret:
+		return SMPProgressWaitingForSecret
+	case smpStateExpect2:
+		return SMPProgressExpectingMessage2
+	case smpStateExpect3:
+		return SMPProgressExpectingMessage3
+	case smpStateExpect4:
+		return SMPProgressExpectingMessage4
+	default:
+		return SMPProgressIdle
+	}
+}
+
+// AbortAuthentication aborts the current SMP exchange, if any, and returns the messages to send to notify the peer
+func (c *Conversation) AbortAuthentication() ([]ValidMessage, error) {
+	if !c.IsEncrypted() {
+		return nil, errCantAuthenticateWithoutEncryption
+	}
+
+	c.log(LogLevelInfo, "aborting SMP", LogField{"progress", c.SMPProgress().String()})
+	c.smp.wipe()
+	c.smp.ensureSMP()
+
+	msgs, _, err := c.createSerializedDataMessage(nil, messageFlagIgnoreUnreadable, []tlv{smpMessageAbort{}.tlv()})
+	return msgs, err
+}
+
+// SetSMPTimeout assigns how long an SMP exchange can stall without progress before it is aborted.
+// The timeout is checked when Send is called, when Receive gets a message that doesn't advance the exchange,
+// and when CheckSMPTimeout is called. Zero, the default, disables the timeout
+func (c *Conversation) SetSMPTimeout(timeout time.Duration) {
+	c.smpTimeout = timeout
+}
+
+// SMPDeadline returns the time at which the current SMP exchange times out unless it progresses.
+// It returns false if no exchange is in progress or no timeout has been set
+func (c *Conversation) SMPDeadline() (time.Time, bool) {
+	if c.smpTimeout <= 0 || c.SMPProgress() == SMPProgressIdle {
+		return time.Time{}, false
+	}
+	return c.smp.lastActivity.Add(c.smpTimeout), true
+}
+
+// CheckSMPTimeout aborts the current SMP exchange if it has stalled for longer than the SMP timeout.
+// It returns the messages to send to notify the peer, or nothing if the exchange hasn't timed out.
+// Call it periodically, or when the deadline from SMPDeadline passes, to expire exchanges that get no traffic
+func (c *Conversation) CheckSMPTimeout() ([]ValidMessage, error) {
+	if !c.smpHasTimedOut() {
+		return nil, nil
+	}
+	return c.timeoutSMP()
+}
+
+func (c *Conversation) updateSMPActivity() {
+	c.smp.lastActivity = c.now()
+}
+
+func (c *Conversation) smpHasTimedOut() bool {
+	return c.smpTimeout > 0 &&
+		c.SMPProgress() != SMPProgressIdle &&
+		c.now().Sub(c.smp.lastActivity) > c.smpTimeout
+}
+
+// maybeTimeoutSMP aborts a stalled SMP exchange and returns the abort message for the peer.
+// The abort message has to be sent before anything generated afterwards, or the peer will reject it as a replay
+func (c *Conversation) maybeTimeoutSMP() []ValidMessage {
+	if !c.smpHasTimedOut() {
+		return nil
+	}
+
+	msgs, _ := c.timeoutSMP()
+	return msgs
+}
+
+func (c *Conversation) timeoutSMP() ([]ValidMessage, error) {
+	c.log(LogLevelWarn, "SMP timed out", LogField{"progress", c.SMPProgress().String()})
+	msgs, err := c.AbortAuthentication()
+	if err != nil {
+		c.smp.wipe()
+	}
+
+	c.smpEvent(SMPEventTimeout, 0)
+	return msgs, err
+}
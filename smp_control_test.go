@@ -0,0 +1,184 @@
+package otr3
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_SMPProgress_hasValidStringImplementation(t *testing.T) {
+	assertEquals(t, SMPProgressIdle.String(), "SMPProgressIdle")
+	assertEquals(t, SMPProgressWaitingForSecret.String(), "SMPProgressWaitingForSecret")
+	assertEquals(t, SMPProgressExpectingMessage2.String(), "SMPProgressExpectingMessage2")
+	assertEquals(t, SMPProgressExpectingMessage3.String(), "SMPProgressExpectingMessage3")
+	assertEquals(t, SMPProgressExpectingMessage4.String(), "SMPProgressExpectingMessage4")
+	assertEquals(t, SMPProgress(20000).String(), "SMP PROGRESS: (THIS SHOULD NEVER HAPPEN)")
+}
+
+func Test_SMPProgress_followsTheSMPExchange(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	assertEquals(t, alice.SMPProgress(), SMPProgressIdle)
+
+	toSend, _ := alice.StartAuthenticate("", []byte("secret"))
+	assertEquals(t, alice.SMPProgress(), SMPProgressExpectingMessage2)
+	assertEquals(t, alice.SMPProgress().Percent(), 20)
+
+	bob.Receive(toSend[0])
+	assertEquals(t, bob.SMPProgress(), SMPProgressWaitingForSecret)
+
+	toSend, _ = bob.ProvideAuthenticationSecret([]byte("secret"))
+	assertEquals(t, bob.SMPProgress(), SMPProgressExpectingMessage3)
+
+	alice.Receive(toSend[0])
+	assertEquals(t, alice.SMPProgress(), SMPProgressExpectingMessage4)
+	assertEquals(t, alice.SMPProgress().Percent(), 60)
+}
+
+func Test_AbortAuthentication_failsIfWeAreNotEncrypted(t *testing.T) {
+	c := &Conversation{}
+	_, err := c.AbortAuthentication()
+	assertEquals(t, err, errCantAuthenticateWithoutEncryption)
+}
+
+func Test_AbortAuthentication_resetsTheStateAndNotifiesThePeer(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	bobResult := bob.lastSMPEvent()
+
+	toSend, _ := alice.StartAuthenticate("question?", []byte("secret"))
+	bob.Receive(toSend[0])
+
+	toSend, err := alice.AbortAuthentication()
+	assertNil(t, err)
+	assertEquals(t, alice.SMPProgress(), SMPProgressIdle)
+
+	bob.Receive(toSend[0])
+	assertEquals(t, *bobResult, SMPEventAbort)
+	assertEquals(t, bob.SMPProgress(), SMPProgressIdle)
+}
+
+func Test_maybeTimeoutSMP_doesNothingWithoutATimeout(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.StartAuthenticate("", []byte("secret"))
+	alice.smp.lastActivity = time.Now().Add(-time.Hour)
+
+	toSend, _ := alice.Send(ValidMessage("hello"))
+
+	assertEquals(t, len(toSend), 1)
+	assertEquals(t, alice.SMPProgress(), SMPProgressExpectingMessage2)
+}
+
+func Test_maybeTimeoutSMP_abortsAStalledExchangeOnSend(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.SetSMPTimeout(time.Minute)
+	aliceResult := alice.lastSMPEvent()
+	bobResult := bob.lastSMPEvent()
+
+	alice.StartAuthenticate("", []byte("secret"))
+	alice.smp.lastActivity = time.Now().Add(-2 * time.Minute)
+
+	toSend, _ := alice.Send(ValidMessage("hello"))
+
+	assertEquals(t, *aliceResult, SMPEventTimeout)
+	assertEquals(t, alice.SMPProgress(), SMPProgressIdle)
+	assertEquals(t, len(toSend), 2)
+
+	bob.Receive(toSend[0])
+	assertEquals(t, *bobResult, SMPEventAbort)
+	plain, _, _ := bob.Receive(toSend[1])
+	assertDeepEquals(t, plain, MessagePlaintext("hello"))
+}
+
+func Test_maybeTimeoutSMP_doesNotAbortAnExchangeThatProgresses(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.SetSMPTimeout(time.Minute)
+
+	alice.StartAuthenticate("", []byte("secret"))
+	alice.Send(ValidMessage("hello"))
+
+	assertEquals(t, alice.SMPProgress(), SMPProgressExpectingMessage2)
+}
+
+func Test_Receive_doesNotAbortAnExchangeWhenALateSMPMessageArrives(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.SetSMPTimeout(time.Minute)
+	aliceResult := alice.lastSMPEvent()
+
+	toSend, _ := alice.StartAuthenticate("", []byte("secret"))
+	bob.Receive(toSend[0])
+	toSend, _ = bob.ProvideAuthenticationSecret([]byte("secret"))
+	alice.smp.lastActivity = time.Now().Add(-2 * time.Minute)
+
+	_, toSend, _ = alice.Receive(toSend[0])
+
+	assertEquals(t, *aliceResult, SMPEventInProgress)
+	assertEquals(t, alice.SMPProgress(), SMPProgressExpectingMessage4)
+	assertEquals(t, len(toSend), 1)
+}
+
+func Test_Receive_abortsAStalledExchangeWhenTheMessageDoesNotProgressIt(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.SetSMPTimeout(time.Minute)
+	aliceResult := alice.lastSMPEvent()
+	bobResult := bob.lastSMPEvent()
+
+	alice.StartAuthenticate("", []byte("secret"))
+	alice.smp.lastActivity = time.Now().Add(-2 * time.Minute)
+	toSend, _ := bob.Send(ValidMessage("hello"))
+
+	plain, toSend, _ := alice.Receive(toSend[0])
+
+	assertDeepEquals(t, plain, MessagePlaintext("hello"))
+	assertEquals(t, *aliceResult, SMPEventTimeout)
+	assertEquals(t, alice.SMPProgress(), SMPProgressIdle)
+	assertEquals(t, len(toSend), 1)
+
+	bob.Receive(toSend[0])
+	assertEquals(t, *bobResult, SMPEventAbort)
+}
+
+func Test_CheckSMPTimeout_abortsAStalledExchangeWithoutTraffic(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.SetSMPTimeout(time.Minute)
+	aliceResult := alice.lastSMPEvent()
+	bobResult := bob.lastSMPEvent()
+
+	alice.StartAuthenticate("", []byte("secret"))
+	toSend, err := alice.CheckSMPTimeout()
+	assertNil(t, err)
+	assertEquals(t, len(toSend), 0)
+
+	alice.smp.lastActivity = time.Now().Add(-2 * time.Minute)
+	toSend, err = alice.CheckSMPTimeout()
+
+	assertNil(t, err)
+	assertEquals(t, *aliceResult, SMPEventTimeout)
+	assertEquals(t, alice.SMPProgress(), SMPProgressIdle)
+	assertEquals(t, len(toSend), 1)
+
+	bob.Receive(toSend[0])
+	assertEquals(t, *bobResult, SMPEventAbort)
+}
+
+func Test_SMPDeadline_isOnlyReportedForAnExchangeInProgressWithATimeout(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+
+	_, ok := alice.SMPDeadline()
+	assertFalse(t, ok)
+
+	alice.SetSMPTimeout(time.Minute)
+	_, ok = alice.SMPDeadline()
+	assertFalse(t, ok)
+
+	alice.StartAuthenticate("", []byte("secret"))
+	deadline, ok := alice.SMPDeadline()
+	assertTrue(t, ok)
+	assertEquals(t, deadline, alice.smp.lastActivity.Add(time.Minute))
+}
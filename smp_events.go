@@ -1,7 +1,5 @@
 package otr3
 
-import "fmt"
-
 // SMPEvent define the events used to indicate status of SMP to the UI
 type SMPEvent int
 
@@ -22,6 +20,8 @@ const (
 	SMPEventSuccess
 	// SMPEventFailure means update the auth progress dialog with progress_percent
 	SMPEventFailure
+	// SMPEventTimeout means the current auth was aborted because it didn't progress within the configured SMP timeout
+	SMPEventTimeout
 )
 
 // SMPEventHandler handles SMPEvents
@@ -39,12 +39,18 @@ func (d dynamicSMPEventHandler) HandleSMPEvent(event SMPEvent, pp int, question
 }
 
 func (c *Conversation) smpEvent(e SMPEvent, percent int) {
+	c.countSMPEvent(e)
+	c.log(LogLevelDebug, "SMP event", LogField{"event", e}, LogField{"progressPercent", percent})
+	c.publishSMPEvent(e, percent, "")
 	if c.smpEventHandler != nil {
 		c.smpEventHandler.HandleSMPEvent(e, percent, "")
 	}
 }
 
 func (c *Conversation) smpEventWithQuestion(e SMPEvent, percent int, question string) {
+	c.countSMPEvent(e)
+	c.log(LogLevelDebug, "SMP event", LogField{"event", e}, LogField{"progressPercent", percent}, LogField{"question", question})
+	c.publishSMPEvent(e, percent, question)
 	if c.smpEventHandler != nil {
 		c.smpEventHandler.HandleSMPEvent(e, percent, question)
 	}
@@ -68,6 +74,8 @@ func (s SMPEvent) String() string {
 		return "SMPEventSuccess"
 	case SMPEventFailure:
 		return "SMPEventFailure"
+	case SMPEventTimeout:
+		return "SMPEventTimeout"
 	default:
 		return "SMP EVENT: (THIS SHOULD NEVER HAPPEN)"
 	}
@@ -91,10 +99,12 @@ func CombineSMPEventHandlers(handlers ...SMPEventHandler) SMPEventHandler {
 	return combinedSMPEventHandler{handlers}
 }
 
-// DebugSMPEventHandler is an SMPEventHandler that dumps all SMPEvents to standard error
+// DebugSMPEventHandler is an SMPEventHandler that dumps all SMPEvents to standard error.
+// Every SMP event is also logged at LogLevelDebug by the Logger of the conversation, so SetLogger(DebugLogger{})
+// gives the same information together with the instance tags of the conversation
 type DebugSMPEventHandler struct{}
 
 // HandleSMPEvent dumps all SMP events
 func (DebugSMPEventHandler) HandleSMPEvent(event SMPEvent, progressPercent int, question string) {
-	fmt.Fprintf(standardErrorOutput, "%sHandleSMPEvent(%s, %d, %#v)\n", debugPrefix, event, progressPercent, question)
+	DebugLogger{}.Log(LogLevelDebug, "SMP event", []LogField{LogField{"event", event}, LogField{"progressPercent", progressPercent}, LogField{"question", question}})
 }
@@ -11,6 +11,7 @@ func Test_SMPEvent_hasValidStringImplementation(t *testing.T) {
 	assertEquals(t, SMPEventInProgress.String(), "SMPEventInProgress")
 	assertEquals(t, SMPEventSuccess.String(), "SMPEventSuccess")
 	assertEquals(t, SMPEventFailure.String(), "SMPEventFailure")
+	assertEquals(t, SMPEventTimeout.String(), "SMPEventTimeout")
 	assertEquals(t, SMPEvent(20000).String(), "SMP EVENT: (THIS SHOULD NEVER HAPPEN)")
 }
 
@@ -37,5 +38,5 @@ func Test_debugSMPEventHandler_writesTheEventToStderr(t *testing.T) {
 	ss := captureStderr(func() {
 		DebugSMPEventHandler{}.HandleSMPEvent(SMPEventInProgress, 43, "Maybe now?")
 	})
-	assertEquals(t, ss, "[DEBUG] HandleSMPEvent(SMPEventInProgress, 43, \"Maybe now?\")\n")
+	assertEquals(t, ss, "[DEBUG] SMP event event=SMPEventInProgress progressPercent=43 question=Maybe now?\n")
 }
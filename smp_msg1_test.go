@@ -3,15 +3,17 @@ package otr3
 import (
 	"math/big"
 	"testing"
+
+	"github.com/twstrike/otr3/smp"
 )
 
 func Test_generatesLongerAandRValuesForOtrV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	smp, err := otr.generateSMP1()
-	assertDeepEquals(t, smp.a2, fixtureLong1)
-	assertDeepEquals(t, smp.a3, fixtureLong2)
-	assertDeepEquals(t, smp.r2, fixtureLong3)
-	assertDeepEquals(t, smp.r3, fixtureLong4)
+	assertDeepEquals(t, smp.A2, fixtureLong1)
+	assertDeepEquals(t, smp.A3, fixtureLong2)
+	assertDeepEquals(t, smp.R2, fixtureLong3)
+	assertDeepEquals(t, smp.R3, fixtureLong4)
 	assertDeepEquals(t, err, nil)
 }
 
@@ -55,61 +57,63 @@ func Test_generateSMP1Parameters_ReturnsErrorIfThereIsntEnoughRandomnessForR3(t
 func Test_generatesShorterAandRValuesForOtrV2(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP1()
-	assertDeepEquals(t, smp.a2, fixtureShort1)
-	assertDeepEquals(t, smp.a3, fixtureShort2)
-	assertDeepEquals(t, smp.r2, fixtureShort3)
-	assertDeepEquals(t, smp.r3, fixtureShort4)
+	assertDeepEquals(t, smp.A2, fixtureShort1)
+	assertDeepEquals(t, smp.A3, fixtureShort2)
+	assertDeepEquals(t, smp.R2, fixtureShort3)
+	assertDeepEquals(t, smp.R3, fixtureShort4)
 }
 
 func Test_computesG2aAndG3aCorrectlyForOtrV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	smp, _ := otr.generateSMP1()
-	assertDeepEquals(t, smp.msg.g2a, fixtureMessage1v3().g2a)
-	assertDeepEquals(t, smp.msg.g3a, fixtureMessage1v3().g3a)
+	assertDeepEquals(t, smp.msg.G2a, fixtureMessage1v3().G2a)
+	assertDeepEquals(t, smp.msg.G3a, fixtureMessage1v3().G3a)
 }
 
 func Test_computesG2aAndG3aCorrectlyForOtrV2(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP1()
-	assertDeepEquals(t, smp.msg.g2a, fixtureMessage1().g2a)
-	assertDeepEquals(t, smp.msg.g3a, fixtureMessage1().g3a)
+	assertDeepEquals(t, smp.msg.G2a, fixtureMessage1().G2a)
+	assertDeepEquals(t, smp.msg.G3a, fixtureMessage1().G3a)
 }
 
 func Test_computesC2AndD2CorrectlyForOtrV2(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP1()
-	assertDeepEquals(t, smp.msg.c2, fixtureMessage1().c2)
-	assertDeepEquals(t, smp.msg.d2, fixtureMessage1().d2)
+	assertDeepEquals(t, smp.msg.C2, fixtureMessage1().C2)
+	assertDeepEquals(t, smp.msg.D2, fixtureMessage1().D2)
 }
 
 func Test_computesC3AndD3CorrectlyForOtrV2(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP1()
-	assertDeepEquals(t, smp.msg.c3, fixtureMessage1().c3)
-	assertDeepEquals(t, smp.msg.d3, fixtureMessage1().d3)
+	assertDeepEquals(t, smp.msg.C3, fixtureMessage1().C3)
+	assertDeepEquals(t, smp.msg.D3, fixtureMessage1().D3)
 }
 
 func Test_thatVerifySMPStartParametersCheckG2AForOtrV3(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	err := c.verifySMP1(smp1Message{g2a: new(big.Int).SetInt64(1)})
+	err := c.verifySMP1(smp1Message{Message1: smp.Message1{G2a: new(big.Int).SetInt64(1)}})
 	assertDeepEquals(t, err, newOtrError("g2a is an invalid group element"))
 }
 
 func Test_thatVerifySMPStartParametersCheckG3AForOtrV3(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	err := c.verifySMP1(smp1Message{g2a: new(big.Int).SetInt64(3), g3a: p})
+	err := c.verifySMP1(smp1Message{Message1: smp.Message1{G2a: new(big.Int).SetInt64(3), G3a: p}})
 	assertDeepEquals(t, err, newOtrError("g3a is an invalid group element"))
 }
 
 func Test_thatVerifySMPStartParametersDoesntCheckG2AForOtrV2(t *testing.T) {
 	c := newConversation(otrV2{}, fixtureRand())
 	err := c.verifySMP1(smp1Message{
-		g2a: new(big.Int).SetInt64(1),
-		g3a: new(big.Int).SetInt64(1),
-		c2:  new(big.Int).SetInt64(1),
-		c3:  new(big.Int).SetInt64(1),
-		d2:  new(big.Int).SetInt64(1),
-		d3:  new(big.Int).SetInt64(1),
+		Message1: smp.Message1{
+			G2a: new(big.Int).SetInt64(1),
+			G3a: new(big.Int).SetInt64(1),
+			C2:  new(big.Int).SetInt64(1),
+			C3:  new(big.Int).SetInt64(1),
+			D2:  new(big.Int).SetInt64(1),
+			D3:  new(big.Int).SetInt64(1),
+		},
 	})
 	assertDeepEquals(t, err, newOtrError("c2 is not a valid zero knowledge proof"))
 }
@@ -117,12 +121,14 @@ func Test_thatVerifySMPStartParametersDoesntCheckG2AForOtrV2(t *testing.T) {
 func Test_thatVerifySMPStartParametersDoesntCheckG3AForOtrV2(t *testing.T) {
 	c := newConversation(otrV2{}, fixtureRand())
 	err := c.verifySMP1(smp1Message{
-		g2a: new(big.Int).SetInt64(3),
-		g3a: new(big.Int).SetInt64(1),
-		c2:  new(big.Int).SetInt64(1),
-		c3:  new(big.Int).SetInt64(1),
-		d2:  new(big.Int).SetInt64(1),
-		d3:  new(big.Int).SetInt64(1),
+		Message1: smp.Message1{
+			G2a: new(big.Int).SetInt64(3),
+			G3a: new(big.Int).SetInt64(1),
+			C2:  new(big.Int).SetInt64(1),
+			C3:  new(big.Int).SetInt64(1),
+			D2:  new(big.Int).SetInt64(1),
+			D3:  new(big.Int).SetInt64(1),
+		},
 	})
 	assertDeepEquals(t, err, newOtrError("c2 is not a valid zero knowledge proof"))
 }
@@ -130,12 +136,14 @@ func Test_thatVerifySMPStartParametersDoesntCheckG3AForOtrV2(t *testing.T) {
 func Test_thatVerifySMPStartParametersChecksThatc2IsAValidZeroKnowledgeProof(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	err := c.verifySMP1(smp1Message{
-		g2a: new(big.Int).SetInt64(3),
-		g3a: new(big.Int).SetInt64(3),
-		c2:  new(big.Int).SetInt64(3),
-		c3:  new(big.Int).SetInt64(3),
-		d2:  new(big.Int).SetInt64(3),
-		d3:  new(big.Int).SetInt64(3),
+		Message1: smp.Message1{
+			G2a: new(big.Int).SetInt64(3),
+			G3a: new(big.Int).SetInt64(3),
+			C2:  new(big.Int).SetInt64(3),
+			C3:  new(big.Int).SetInt64(3),
+			D2:  new(big.Int).SetInt64(3),
+			D3:  new(big.Int).SetInt64(3),
+		},
 	})
 	assertDeepEquals(t, err, newOtrError("c2 is not a valid zero knowledge proof"))
 }
@@ -143,12 +151,14 @@ func Test_thatVerifySMPStartParametersChecksThatc2IsAValidZeroKnowledgeProof(t *
 func Test_thatVerifySMPStartParametersChecksThatc3IsAValidZeroKnowledgeProof(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	err := c.verifySMP1(smp1Message{
-		g2a: fixtureMessage1().g2a,
-		g3a: new(big.Int).SetInt64(3),
-		c2:  fixtureMessage1().c2,
-		c3:  new(big.Int).SetInt64(3),
-		d2:  fixtureMessage1().d2,
-		d3:  new(big.Int).SetInt64(3),
+		Message1: smp.Message1{
+			G2a: fixtureMessage1().G2a,
+			G3a: new(big.Int).SetInt64(3),
+			C2:  fixtureMessage1().C2,
+			C3:  new(big.Int).SetInt64(3),
+			D2:  fixtureMessage1().D2,
+			D3:  new(big.Int).SetInt64(3),
+		},
 	})
 	assertDeepEquals(t, err, newOtrError("c3 is not a valid zero knowledge proof"))
 }
@@ -164,12 +174,14 @@ func Test_thatVerifySMPStartParametersIsOKWithAValidParameterMessage(t *testing.
 	d3, _ := new(big.Int).SetString("7fffffffffffffffe487ed5110b4611a62633145c06e0e68948127044533e63a0105df531d89cd9128a5043cc71a026ef7ca8cd9e69d218d98158536f92f8a1ba7f09ab6b6a8e122f242dabb312f3f637a262174d31bf6b585ffae5b7a035bf6f71c35fdad44cfd2d74f9208be258ff324943328f6722d9ee1003e5c50b1df82cc6d241b0e2ae9cd348b1fd47e9267af8140bb2aa65628bcff455920bba95a1392f2fcb5c115f43a7a828b5bf0393c5c775a17a88506a7893ff509d674cd655c", 16)
 
 	err := c.verifySMP1(smp1Message{
-		g2a: g2a,
-		g3a: g3a,
-		c2:  c2,
-		c3:  c3,
-		d2:  d2,
-		d3:  d3,
+		Message1: smp.Message1{
+			G2a: g2a,
+			G3a: g3a,
+			C2:  c2,
+			C3:  c3,
+			D2:  d2,
+			D3:  d3,
+		},
 	})
 	assertDeepEquals(t, err, nil)
 }
@@ -185,12 +197,14 @@ func Test_thatVerifySMPStartParametersIsOKWithAValidParameterMessageWithProtocol
 	d3, _ := new(big.Int).SetString("7fffffffffffffffe487ed5110b4611a62633145c06e0e68948127044533e63a0105df531d89cd9128a5043cc71a026ef7ca8cd9e69d218d98158536f92f8a1ba7f09ab6b6a8e122f242dabb312f3f637a262174d31bf6b585ffae5b7a035bf6f71c35fdad44cfd2d74f9208be258ff324943328f6722d9ee1003e5c50b1df82cc6d241b0e2ae9cd348b1fd47e9267af8140bb2aa65628bcff455920bba95a1392f2fcb5c115f43a7a828b5bf0393c5c775a17a88506a7893ff509d674cd655c", 16)
 
 	err := c.verifySMP1(smp1Message{
-		g2a: g2a,
-		g3a: g3a,
-		c2:  c2,
-		c3:  c3,
-		d2:  d2,
-		d3:  d3,
+		Message1: smp.Message1{
+			G2a: g2a,
+			G3a: g3a,
+			C2:  c2,
+			C3:  c3,
+			D2:  d2,
+			D3:  d3,
+		},
 	})
 	assertDeepEquals(t, err, nil)
 }
@@ -8,98 +8,98 @@ import (
 func Test_generateSMP3_generatesLongerValuesForR4WithProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	smp, err := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.r4, fixtureLong1)
+	assertDeepEquals(t, smp.R4, fixtureLong1)
 	assertDeepEquals(t, err, nil)
 }
 
 func Test_generateSMP3_generatesLongerValuesForR5WithProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.r5, fixtureLong2)
+	assertDeepEquals(t, smp.R5, fixtureLong2)
 }
 
 func Test_generateSMP3_generatesLongerValuesForR6WithProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.r6, fixtureLong3)
+	assertDeepEquals(t, smp.R6, fixtureLong3)
 }
 
 func Test_generateSMP3_generatesLongerValuesForR7WithProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.r7, fixtureLong4)
+	assertDeepEquals(t, smp.R7, fixtureLong4)
 }
 
 func Test_generateSMP3_generatesShorterValuesForR4WithProtocolV2(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.r4, fixtureShort1)
+	assertDeepEquals(t, smp.R4, fixtureShort1)
 }
 
 func Test_generateSMP3_computesPaCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.pa, fixtureMessage3().pa)
+	assertDeepEquals(t, smp.msg.Pa, fixtureMessage3().Pa)
 }
 
 func Test_generateSMP3_computesQaCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.qa, fixtureMessage3().qa)
+	assertDeepEquals(t, smp.msg.Qa, fixtureMessage3().Qa)
 }
 
 func Test_generateSMP3_computesPaPbCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.papb, fixtureSmp3().papb)
+	assertDeepEquals(t, smp.Papb, fixtureSmp3().Papb)
 }
 
 func Test_generateSMP3_computesQaQbCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.qaqb, fixtureSmp3().qaqb)
+	assertDeepEquals(t, smp.Qaqb, fixtureSmp3().Qaqb)
 }
 
 func Test_generateSMP3_storesG3b(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.g3b, fixtureMessage2().g3b)
+	assertDeepEquals(t, smp.G3b, fixtureMessage2().G3b)
 }
 
 func Test_generateSMP3_computesCPCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.cp, fixtureMessage3().cp)
+	assertDeepEquals(t, smp.msg.Cp, fixtureMessage3().Cp)
 }
 
 func Test_generateSMP3_computesD5Correctly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.d5, fixtureMessage3().d5)
+	assertDeepEquals(t, smp.msg.D5, fixtureMessage3().D5)
 }
 
 func Test_generateSMP3_computesD6Correctly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.d6, fixtureMessage3().d6)
+	assertDeepEquals(t, smp.msg.D6, fixtureMessage3().D6)
 }
 
 func Test_generateSMP3_computesRaCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.ra, fixtureMessage3().ra)
+	assertDeepEquals(t, smp.msg.Ra, fixtureMessage3().Ra)
 }
 
 func Test_generateSMP3_computesCrCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.cr, fixtureMessage3().cr)
+	assertDeepEquals(t, smp.msg.Cr, fixtureMessage3().Cr)
 }
 
 func Test_generateSMP3_computesD7Correctly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP3(fixtureSecret(), *fixtureSmp1(), fixtureMessage2())
-	assertDeepEquals(t, smp.msg.d7, fixtureMessage3().d7)
+	assertDeepEquals(t, smp.msg.D7, fixtureMessage3().D7)
 }
 
 func Test_generateSMP3Parameters_returnsAnErrorIfThereIsntRandomnessToGenerate_r4(t *testing.T) {
@@ -158,15 +158,15 @@ func Test_generateSMP3_returnsAnErrorIfThereIsNotEnoughRandomnessForBlinding(t *
 
 func Test_verifySMP3_failsIfPaIsNotInTheGroupForProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
-	err := otr.verifySMP3(fixtureSmp2(), smp3Message{pa: big.NewInt(1)})
+	err := otr.verifySMP3(fixtureSmp2(), smp3Message{Pa: big.NewInt(1)})
 	assertDeepEquals(t, err, newOtrError("Pa is an invalid group element"))
 }
 
 func Test_verifySMP3_failsIfQaIsNotInTheGroupForProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	err := otr.verifySMP3(fixtureSmp2(), smp3Message{
-		pa: big.NewInt(2),
-		qa: big.NewInt(1),
+		Pa: big.NewInt(2),
+		Qa: big.NewInt(1),
 	})
 	assertDeepEquals(t, err, newOtrError("Qa is an invalid group element"))
 }
@@ -174,9 +174,9 @@ func Test_verifySMP3_failsIfQaIsNotInTheGroupForProtocolV3(t *testing.T) {
 func Test_verifySMP3_failsIfRaIsNotInTheGroupForProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	err := otr.verifySMP3(fixtureSmp2(), smp3Message{
-		pa: big.NewInt(2),
-		qa: big.NewInt(2),
-		ra: big.NewInt(1),
+		Pa: big.NewInt(2),
+		Qa: big.NewInt(2),
+		Ra: big.NewInt(1),
 	})
 	assertDeepEquals(t, err, newOtrError("Ra is an invalid group element"))
 }
@@ -190,7 +190,7 @@ func Test_verifySMP3_succeedsForValidZKPS(t *testing.T) {
 func Test_verifySMP3_failsIfCpIsNotAValidZKP(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	m := fixtureMessage3()
-	m.cp = sub(m.cp, big.NewInt(1))
+	m.Cp = sub(m.Cp, big.NewInt(1))
 	err := otr.verifySMP3(fixtureSmp2(), m)
 	assertDeepEquals(t, err, newOtrError("cP is not a valid zero knowledge proof"))
 }
@@ -198,7 +198,7 @@ func Test_verifySMP3_failsIfCpIsNotAValidZKP(t *testing.T) {
 func Test_verifySMP3_failsIfCrIsNotAValidZKP(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	m := fixtureMessage3()
-	m.cr = sub(m.cr, big.NewInt(1))
+	m.Cr = sub(m.Cr, big.NewInt(1))
 	err := otr.verifySMP3(fixtureSmp2(), m)
 	assertDeepEquals(t, err, newOtrError("cR is not a valid zero knowledge proof"))
 }
@@ -1,6 +1,10 @@
 package otr3
 
-import "math/big"
+import (
+	"math/big"
+
+	"github.com/twstrike/otr3/smp"
+)
 
 type smp4State struct {
 	y   *big.Int
@@ -8,14 +12,10 @@ type smp4State struct {
 	msg smp4Message
 }
 
-type smp4Message struct {
-	cr *big.Int
-	d7 *big.Int
-	rb *big.Int
-}
+type smp4Message smp.Message4
 
 func (m smp4Message) tlv() tlv {
-	return genSMPTLV(uint16(tlvTypeSMP4), m.rb, m.cr, m.d7)
+	return genSMPTLV(uint16(tlvTypeSMP4), smp.Message4(m).Values()...)
 }
 
 func (c *Conversation) generateSMP4(secret *big.Int, s2 smp2State, msg3 smp3Message) (s smp4State, err error) {
@@ -28,15 +28,7 @@ func (c *Conversation) generateSMP4(secret *big.Int, s2 smp2State, msg3 smp3Mess
 }
 
 func (c *Conversation) verifySMP4(s3 *smp3State, msg smp4Message) error {
-	if !c.version.isGroupElement(msg.rb) {
-		return newOtrError("Rb is an invalid group element")
-	}
-
-	if !verifyZKP4(msg.cr, s3.g3b, msg.d7, s3.qaqb, msg.rb, 8) {
-		return newOtrError("cR is not a valid zero knowledge proof")
-	}
-
-	return nil
+	return smpVerifyError(c.versionSMPGroup().VerifyMessage4(s3.InitiatorState, smp.Message4(msg)))
 }
 
 func (c *Conversation) generateSMP4Parameters() (s smp4State, err error) {
@@ -46,20 +38,11 @@ func (c *Conversation) generateSMP4Parameters() (s smp4State, err error) {
 }
 
 func generateSMP4Message(s smp4State, s2 smp2State, msg3 smp3Message) smp4Message {
-	var m smp4Message
-
-	qaqb := divMod(msg3.qa, s2.qb, p)
-
-	m.rb = modExp(qaqb, s2.b3)
-	m.cr = hashMPIsBN(nil, 8, modExp(g1, s.r7), modExp(qaqb, s.r7))
-	m.d7 = subMod(s.r7, mul(s2.b3, m.cr), q)
-
-	return m
+	return smp4Message(smpGroup.GenerateMessage4(s.r7, s2.B3, s2.ResponderState, smp.Message3(msg3)))
 }
 
 func (c *Conversation) verifySMP4ProtocolSuccess(s1 *smp1State, s3 *smp3State, msg smp4Message) error {
-	rab := modExp(msg.rb, s1.a3)
-	if !eq(rab, s3.papb) {
+	if !smpGroup.InitiatorMatches(s1.A3, s3.InitiatorState, smp.Message4(msg)) {
 		return newOtrError("protocol failed: x != y")
 	}
 
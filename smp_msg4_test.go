@@ -36,19 +36,19 @@ func Test_generateSMP4_generatesShorterValuesForR7WithProtocolV3(t *testing.T) {
 func Test_generateSMP4_computesRbCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP4(fixtureSecret(), *fixtureSmp2(), fixtureMessage3())
-	assertDeepEquals(t, smp.msg.rb, fixtureMessage4().rb)
+	assertDeepEquals(t, smp.msg.Rb, fixtureMessage4().Rb)
 }
 
 func Test_generateSMP4_computesCrCorrectly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP4(fixtureSecret(), *fixtureSmp2(), fixtureMessage3())
-	assertDeepEquals(t, smp.msg.cr, fixtureMessage4().cr)
+	assertDeepEquals(t, smp.msg.Cr, fixtureMessage4().Cr)
 }
 
 func Test_generateSMP4_computesD7Correctly(t *testing.T) {
 	otr := newConversation(otrV2{}, fixtureRand())
 	smp, _ := otr.generateSMP4(fixtureSecret(), *fixtureSmp2(), fixtureMessage3())
-	assertDeepEquals(t, smp.msg.d7, fixtureMessage4().d7)
+	assertDeepEquals(t, smp.msg.D7, fixtureMessage4().D7)
 }
 
 func Test_verifySMP4_succeedsForValidZKPS(t *testing.T) {
@@ -59,14 +59,14 @@ func Test_verifySMP4_succeedsForValidZKPS(t *testing.T) {
 
 func Test_verifySMP4_failsIfRbIsNotInTheGroupForProtocolV3(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
-	err := otr.verifySMP4(fixtureSmp3(), smp4Message{rb: big.NewInt(1)})
+	err := otr.verifySMP4(fixtureSmp3(), smp4Message{Rb: big.NewInt(1)})
 	assertDeepEquals(t, err, newOtrError("Rb is an invalid group element"))
 }
 
 func Test_verifySMP4_failsIfCrIsNotACorrectZKP(t *testing.T) {
 	otr := newConversation(otrV3{}, fixtureRand())
 	m := fixtureMessage4()
-	m.cr = sub(m.cr, big.NewInt(1))
+	m.Cr = sub(m.Cr, big.NewInt(1))
 	err := otr.verifySMP4(fixtureSmp3(), m)
 	assertDeepEquals(t, err, newOtrError("cR is not a valid zero knowledge proof"))
 }
@@ -0,0 +1,58 @@
+package otr3
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// AnswerNormalizer transforms an SMP answer before it is used as the shared secret.
+// Both peers have to use the same normalizers, otherwise SMP will fail even with the same answer
+type AnswerNormalizer func(answer []byte) []byte
+
+// NormalizeNFC transforms the answer to Unicode normalization form C
+func NormalizeNFC(answer []byte) []byte {
+	return norm.NFC.Bytes(answer)
+}
+
+// NormalizeTrimSpace removes leading and trailing white space from the answer
+func NormalizeTrimSpace(answer []byte) []byte {
+	return bytes.TrimSpace(answer)
+}
+
+// NormalizeFoldCase applies Unicode case folding to the answer, so that answers differing only in case are equal
+func NormalizeFoldCase(answer []byte) []byte {
+	return cases.Fold().Bytes(answer)
+}
+
+// DefaultAnswerNormalizers returns the recommended normalizers: NFC, trimming and case folding, in that order
+func DefaultAnswerNormalizers() []AnswerNormalizer {
+	return []AnswerNormalizer{NormalizeNFC, NormalizeTrimSpace, NormalizeFoldCase}
+}
+
+// SMPOptions configures how SMP questions and answers are handled in a conversation.
+// The zero value uses the answers as given and accepts any question, which is the behavior of libotr
+type SMPOptions struct {
+	// AnswerNormalizers are applied in order to the answer before it is used as the shared secret
+	AnswerNormalizers []AnswerNormalizer
+	// ValidateQuestions rejects questions that are not valid UTF-8, both when starting SMP and when receiving a question
+	ValidateQuestions bool
+}
+
+// SetSMPOptions assigns the options used for all future SMP exchanges in this conversation
+func (c *Conversation) SetSMPOptions(o SMPOptions) {
+	c.smpOptions = o
+}
+
+func (o SMPOptions) normalizeAnswer(answer []byte) []byte {
+	for _, n := range o.AnswerNormalizers {
+		answer = n(answer)
+	}
+	return answer
+}
+
+func (o SMPOptions) validQuestion(question string) bool {
+	return !o.ValidateQuestions || utf8.ValidString(question)
+}
@@ -0,0 +1,83 @@
+package otr3
+
+import "testing"
+
+func (c *Conversation) lastSMPEvent() *SMPEvent {
+	var last SMPEvent = -1
+	c.SetSMPEventHandler(dynamicSMPEventHandler{func(e SMPEvent, _ int, _ string) {
+		last = e
+	}})
+	return &last
+}
+
+func runSMP(alice, bob *Conversation, question string, aliceSecret, bobSecret []byte) (SMPEvent, SMPEvent) {
+	aliceResult, bobResult := alice.lastSMPEvent(), bob.lastSMPEvent()
+
+	toSend, _ := alice.StartAuthenticate(question, aliceSecret)
+	bob.Receive(toSend[0])
+	toSend, _ = bob.ProvideAuthenticationSecret(bobSecret)
+	_, toSend, _ = alice.Receive(toSend[0])
+	_, toSend, _ = bob.Receive(toSend[0])
+	alice.Receive(toSend[0])
+
+	return *aliceResult, *bobResult
+}
+
+func Test_normalizers_transformTheAnswer(t *testing.T) {
+	assertDeepEquals(t, NormalizeNFC([]byte("Pérez")), []byte("Pérez"))
+	assertDeepEquals(t, NormalizeTrimSpace([]byte(" \tparis \n")), []byte("paris"))
+	assertDeepEquals(t, NormalizeFoldCase([]byte("PARIS Straße")), []byte("paris strasse"))
+}
+
+func Test_SMPOptions_normalizeAnswer_appliesAllNormalizersInOrder(t *testing.T) {
+	o := SMPOptions{AnswerNormalizers: DefaultAnswerNormalizers()}
+	assertDeepEquals(t, o.normalizeAnswer([]byte(" PÉREZ ")), o.normalizeAnswer([]byte("pérez")))
+	assertDeepEquals(t, SMPOptions{}.normalizeAnswer([]byte(" Paris")), []byte(" Paris"))
+}
+
+func Test_SMP_failsForDifferentlyWrittenAnswersWithoutNormalization(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+
+	aliceResult, bobResult := runSMP(alice, bob, "", []byte("Paris"), []byte("paris "))
+
+	assertEquals(t, aliceResult, SMPEventAbort)
+	assertEquals(t, bobResult, SMPEventFailure)
+}
+
+func Test_SMP_succeedsForDifferentlyWrittenAnswersWithNormalization(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	alice.SetSMPOptions(SMPOptions{AnswerNormalizers: DefaultAnswerNormalizers()})
+	bob.SetSMPOptions(SMPOptions{AnswerNormalizers: DefaultAnswerNormalizers()})
+
+	aliceResult, bobResult := runSMP(alice, bob, "Capital of France?", []byte("PARIS"), []byte("paris "))
+
+	assertEquals(t, aliceResult, SMPEventSuccess)
+	assertEquals(t, bobResult, SMPEventSuccess)
+}
+
+func Test_StartAuthenticate_rejectsAnInvalidQuestionWhenValidating(t *testing.T) {
+	c := bobContextAfterAKE()
+	c.msgState = encrypted
+	c.SetSMPOptions(SMPOptions{ValidateQuestions: true})
+
+	_, err := c.StartAuthenticate("bad \xff question", []byte("secret"))
+
+	assertEquals(t, err, errInvalidSMPQuestion)
+}
+
+func Test_receiveMessage1_abortsForAnInvalidQuestionWhenValidating(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	bob.SetSMPOptions(SMPOptions{ValidateQuestions: true})
+	bobResult := bob.lastSMPEvent()
+
+	toSend, _ := alice.StartAuthenticate("bad \xff question", []byte("secret"))
+	_, toSend, _ = bob.Receive(toSend[0])
+
+	assertEquals(t, *bobResult, SMPEventError)
+	assertEquals(t, len(toSend), 1)
+	_, ok := bob.SMPQuestion()
+	assertEquals(t, ok, false)
+}
@@ -47,12 +47,17 @@ func (c *Conversation) abortStateMachineAndNotifyCheated() (smpState, smpMessage
 }
 
 func (c *Conversation) receiveSMP(m smpMessage) (*tlv, error) {
+	previousState := c.smp.state
 	toSend, err := m.receivedMessage(c)
 
 	if err != nil {
+		c.log(LogLevelWarn, "failed to process SMP message", LogField{"state", previousState.identityString()}, LogField{"error", err})
 		return nil, err
 	}
 
+	c.log(LogLevelDebug, "processed SMP message", LogField{"from", previousState.identityString()}, LogField{"to", c.smp.state.identityString()})
+	c.updateSMPActivity()
+
 	if toSend == nil {
 		return nil, nil
 	}
@@ -105,6 +110,10 @@ func (smpStateExpect1) receiveMessage1(c *Conversation, m smp1Message) (smpState
 		return c.abortStateMachineAndNotifyCheated()
 	}
 
+	if m.hasQuestion && !c.smpOptions.validQuestion(m.question) {
+		return abortStateMachineAndNotifyError(c)
+	}
+
 	if m.hasQuestion {
 		c.smp.question = &m.question
 		c.smpEventWithQuestion(SMPEventAskForAnswer, 25, m.question)
@@ -121,8 +130,10 @@ func (s smpStateWaitingForSecret) continueMessage1(c *Conversation, mutualSecret
 	}
 
 	// Using ssid here should always be safe - we can't be in an encrypted state without having gone through the AKE
-	c.smp.secret = generateSMPSecret(c.theirKey.DefaultFingerprint(), c.ourKey.PublicKey.DefaultFingerprint(), c.ssid[:], mutualSecret)
-	s2, err := c.generateSMP2(c.smp.secret, s.msg)
+	c.smp.setSecret(generateSMPSecret(c.theirKey.DefaultFingerprint(), c.ourPublicKey().DefaultFingerprint(), c.ssid[:], c.smpOptions.normalizeAnswer(mutualSecret)))
+	secret := c.smp.secret.bigInt()
+	s2, err := c.generateSMP2(secret, s.msg)
+	wipeBigInt(secret)
 	if err != nil {
 		return c.abortStateMachineAndNotifyCheated()
 	}
@@ -138,7 +149,9 @@ func (smpStateExpect2) receiveMessage2(c *Conversation, m smp2Message) (smpState
 		return c.abortStateMachineAndNotifyCheated()
 	}
 
-	s3, err := c.generateSMP3(c.smp.secret, *c.smp.s1, m)
+	secret := c.smp.secret.bigInt()
+	s3, err := c.generateSMP3(secret, *c.smp.s1, m)
+	wipeBigInt(secret)
 	if err != nil {
 		return c.abortStateMachineAndNotifyCheated()
 	}
@@ -163,7 +176,9 @@ func (smpStateExpect3) receiveMessage3(c *Conversation, m smp3Message) (smpState
 	}
 	c.smpEvent(SMPEventSuccess, 100)
 
-	ret, err := c.generateSMP4(c.smp.secret, *c.smp.s2, m)
+	secret := c.smp.secret.bigInt()
+	ret, err := c.generateSMP4(secret, *c.smp.s2, m)
+	wipeBigInt(secret)
 	if err != nil {
 		return c.abortStateMachineAndNotifyCheated()
 	}
@@ -237,8 +252,12 @@ func (smpStateExpect1) startAuthenticate(c *Conversation, question string, mutua
 		return nil, errCantAuthenticateWithoutEncryption
 	}
 
+	if !c.smpOptions.validQuestion(question) {
+		return nil, errInvalidSMPQuestion
+	}
+
 	// Using ssid here should always be safe - we can't be in an encrypted state without having gone through the AKE
-	c.smp.secret = generateSMPSecret(c.ourKey.PublicKey.DefaultFingerprint(), c.theirKey.DefaultFingerprint(), c.ssid[:], mutualSecret)
+	c.smp.setSecret(generateSMPSecret(c.ourPublicKey().DefaultFingerprint(), c.theirKey.DefaultFingerprint(), c.ssid[:], c.smpOptions.normalizeAnswer(mutualSecret)))
 
 	s1, err := c.generateSMP1()
 	if err != nil {
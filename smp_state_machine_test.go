@@ -3,6 +3,8 @@ package otr3
 import (
 	"math/big"
 	"testing"
+
+	"github.com/twstrike/otr3/smp"
 )
 
 func Test_smpStateExpect1_goToWaitingForSecretWhenReceivesSmpMessage1(t *testing.T) {
@@ -49,7 +51,7 @@ func Test_smpStateWaitingForSecret_goToExpectState3WhenReceivesContinueSmpMessag
 
 func Test_smpStateExpect1_receiveMessage1_setsTheSMPQuestionIfThereWasOneInTheMessage(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	msg := fixtureMessage1Q()
 
 	smpStateExpect1{}.receiveMessage1(c, msg)
@@ -145,7 +147,7 @@ func Test_smpStateExpect4_givesAnErrorNotificationIfTheWrongMessageIsSent(t *tes
 
 func Test_smpStateExpect2_goToExpectState4WhenReceivesSmpMessage2(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s1 = fixtureSmp1()
 
 	msg := fixtureMessage2()
@@ -158,7 +160,7 @@ func Test_smpStateExpect2_goToExpectState4WhenReceivesSmpMessage2(t *testing.T)
 
 func Test_smpStateExpect2_sendsAnSMPEventAboutSMPProgressHere(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s1 = fixtureSmp1()
 
 	c.expectSMPEvent(t, func() {
@@ -184,7 +186,7 @@ func Test_smpStateExpect2_returnsSmpMessageAbortIfReceivesUnexpectedMessage(t *t
 
 func Test_smpStateExpect3_goToExpectState1WhenReceivesSmpMessage3(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s2 = fixtureSmp2()
 	msg := fixtureMessage3()
 
@@ -209,7 +211,7 @@ func Test_smpStateExpect3_wipesSMPWhenReceivesSmpMessage3(t *testing.T) {
 
 func Test_smpStateExpect3_willSendAnSMPNotificationOnProtocolSuccess(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s2 = fixtureSmp2()
 
 	c.expectSMPEvent(t, func() {
@@ -288,7 +290,7 @@ func Test_smpStateExpect4_returnsSmpMessageAbortIfReceivesUnexpectedMessage(t *t
 func Test_contextTransitionsFromSmpExpect1ToSmpWaitingForSecret(t *testing.T) {
 	m := fixtureMessage1()
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	c.receiveSMP(m)
 	assertDeepEquals(t, c.smp.state, smpStateWaitingForSecret{msg: m})
@@ -299,7 +301,7 @@ func Test_contextTransitionsFromSmpExpect2ToSmpExpect4(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.state = smpStateExpect2{}
 	c.smp.s1 = fixtureSmp1()
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	c.receiveSMP(m)
 	assertEquals(t, c.smp.state, smpStateExpect4{})
@@ -310,7 +312,7 @@ func Test_contextTransitionsFromSmpExpect3ToSmpExpect1(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.state = smpStateExpect3{}
 	c.smp.s2 = fixtureSmp2()
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	c.receiveSMP(m)
 	assertEquals(t, c.smp.state, smpStateExpect1{})
@@ -342,7 +344,7 @@ func Test_contextUnexpectedMessageTransitionsToSmpExpected1(t *testing.T) {
 func Test_smpStateExpect1_receiveMessage1_abortsSMPIfVerifySMP1ReturnsError(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 
-	s, m, err := smpStateExpect1{}.receiveMessage1(c, smp1Message{g2a: big.NewInt(1)})
+	s, m, err := smpStateExpect1{}.receiveMessage1(c, smp1Message{Message1: smp.Message1{G2a: big.NewInt(1)}})
 
 	assertNil(t, err)
 	assertEquals(t, s, smpStateExpect1{})
@@ -353,14 +355,14 @@ func Test_smpStateExpect1_receiveMessage1_signalsCheatingIfVerifySMP1Fails(t *te
 	c := newConversation(otrV3{}, fixtureRand())
 
 	c.expectSMPEvent(t, func() {
-		smpStateExpect1{}.receiveMessage1(c, smp1Message{g2a: big.NewInt(1)})
+		smpStateExpect1{}.receiveMessage1(c, smp1Message{Message1: smp.Message1{G2a: big.NewInt(1)}})
 	}, SMPEventCheated, 0, "")
 }
 
 func Test_smp1Message_receivedMessage_abortsSMPIfFailsToVerifyMessage1(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.state = smpStateExpect1{}
-	m := smp1Message{g2a: big.NewInt(1)}
+	m := smp1Message{Message1: smp.Message1{G2a: big.NewInt(1)}}
 	ret, err := m.receivedMessage(c)
 
 	assertNil(t, err)
@@ -386,8 +388,8 @@ func Test_smpStateWaitingForSecret_continueMessage1_abortsSMPIfgenerateSMP2Fails
 func Test_smpStateExpect2_receiveMessage2_abortsSMPIfVerifySMPReturnsError(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.s1 = fixtureSmp1()
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
-	s, m, err := smpStateExpect2{}.receiveMessage2(c, smp2Message{g2b: big.NewInt(1)})
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
+	s, m, err := smpStateExpect2{}.receiveMessage2(c, smp2Message{G2b: big.NewInt(1)})
 
 	assertNil(t, err)
 	assertEquals(t, s, smpStateExpect1{})
@@ -398,8 +400,8 @@ func Test_smp2Message_receivedMessage_abortsSMPIfUnderlyingPrimitiveHasErrors(t
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.state = smpStateExpect2{}
 	c.smp.s1 = fixtureSmp1()
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
-	ret, err := smp2Message{g2b: big.NewInt(1)}.receivedMessage(c)
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
+	ret, err := smp2Message{G2b: big.NewInt(1)}.receivedMessage(c)
 
 	assertNil(t, err)
 	assertDeepEquals(t, ret, smpMessageAbort{})
@@ -408,7 +410,7 @@ func Test_smp2Message_receivedMessage_abortsSMPIfUnderlyingPrimitiveHasErrors(t
 func Test_smpStateExpect2_receiveMessage2_abortsSMPIfgenerateSMPFails(t *testing.T) {
 	c := newConversation(otrV3{}, fixedRand([]string{"ABCD"}))
 	c.smp.s1 = fixtureSmp1()
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	s, m, err := smpStateExpect2{}.receiveMessage2(c, fixtureMessage2())
 
 	assertNil(t, err)
@@ -418,9 +420,9 @@ func Test_smpStateExpect2_receiveMessage2_abortsSMPIfgenerateSMPFails(t *testing
 
 func Test_smpStateExpect3_receiveMessage3_abortsSMPIfVerifySMPReturnsError(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s2 = fixtureSmp2()
-	s, m, err := smpStateExpect3{}.receiveMessage3(c, smp3Message{pa: big.NewInt(1)})
+	s, m, err := smpStateExpect3{}.receiveMessage3(c, smp3Message{Pa: big.NewInt(1)})
 
 	assertNil(t, err)
 	assertEquals(t, s, smpStateExpect1{})
@@ -430,9 +432,9 @@ func Test_smpStateExpect3_receiveMessage3_abortsSMPIfVerifySMPReturnsError(t *te
 func Test_smp3Message_receivedMessage_abortsSMPIfUnderlyingPrimitiveDoes(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.state = smpStateExpect3{}
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s2 = fixtureSmp2()
-	ret, err := smp3Message{pa: big.NewInt(1)}.receivedMessage(c)
+	ret, err := smp3Message{Pa: big.NewInt(1)}.receivedMessage(c)
 
 	assertNil(t, err)
 	assertDeepEquals(t, ret, smpMessageAbort{})
@@ -440,9 +442,9 @@ func Test_smp3Message_receivedMessage_abortsSMPIfUnderlyingPrimitiveDoes(t *test
 
 func Test_smpStateExpect3_receiveMessage3_abortsSMPIfProtocolFails(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s2 = fixtureSmp2()
-	c.smp.s2.b3 = sub(c.smp.s2.b3, big.NewInt(1))
+	c.smp.s2.B3 = sub(c.smp.s2.B3, big.NewInt(1))
 	s, m, err := smpStateExpect3{}.receiveMessage3(c, fixtureMessage3())
 
 	assertNil(t, err)
@@ -452,9 +454,9 @@ func Test_smpStateExpect3_receiveMessage3_abortsSMPIfProtocolFails(t *testing.T)
 
 func Test_smpStateExpect3_receiveMessage3_willSendAnSMPNotificationOnProtocolFailure(t *testing.T) {
 	c := newConversation(otrV3{}, fixtureRand())
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s2 = fixtureSmp2()
-	c.smp.s2.b3 = sub(c.smp.s2.b3, big.NewInt(1))
+	c.smp.s2.B3 = sub(c.smp.s2.B3, big.NewInt(1))
 
 	c.expectSMPEvent(t, func() {
 		smpStateExpect3{}.receiveMessage3(c, fixtureMessage3())
@@ -464,7 +466,7 @@ func Test_smpStateExpect3_receiveMessage3_willSendAnSMPNotificationOnProtocolFai
 
 func Test_smpStateExpect3_receiveMessage3_abortsSMPIfCantGenerateFinalParameters(t *testing.T) {
 	c := newConversation(otrV3{}, fixedRand([]string{"ABCD"}))
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 	c.smp.s2 = fixtureSmp2()
 	s, m, err := smpStateExpect3{}.receiveMessage3(c, fixtureMessage3())
 
@@ -477,7 +479,7 @@ func Test_smpStateExpect4_receiveMessage4_abortsSMPIfVerifySMPReturnsError(t *te
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.s1 = fixtureSmp1()
 	c.smp.s3 = fixtureSmp3()
-	s, m, err := smpStateExpect4{}.receiveMessage4(c, smp4Message{rb: big.NewInt(1)})
+	s, m, err := smpStateExpect4{}.receiveMessage4(c, smp4Message{Rb: big.NewInt(1)})
 
 	assertNil(t, err)
 	assertEquals(t, s, smpStateExpect1{})
@@ -488,7 +490,7 @@ func Test_smpStateExpect4_receiveMessage4_abortsSMPIfProtocolFails(t *testing.T)
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.s1 = fixtureSmp1()
 	c.smp.s3 = fixtureSmp3()
-	c.smp.s3.papb = sub(c.smp.s3.papb, big.NewInt(1))
+	c.smp.s3.Papb = sub(c.smp.s3.Papb, big.NewInt(1))
 	s, m, err := smpStateExpect4{}.receiveMessage4(c, fixtureMessage4())
 
 	assertNil(t, err)
@@ -500,7 +502,7 @@ func Test_smpStateExpect4_receiveMessage4_willSendAnSMPNotificationOnProtocolFai
 	c := newConversation(otrV3{}, fixtureRand())
 	c.smp.s1 = fixtureSmp1()
 	c.smp.s3 = fixtureSmp3()
-	c.smp.s3.papb = sub(c.smp.s3.papb, big.NewInt(1))
+	c.smp.s3.Papb = sub(c.smp.s3.Papb, big.NewInt(1))
 
 	c.expectSMPEvent(t, func() {
 		smpStateExpect4{}.receiveMessage4(c, fixtureMessage4())
@@ -513,7 +515,7 @@ func Test_smp4Message_receivedMessage_abortsSMPIfTheUnderlyingPrimitiveDoes(t *t
 	c.smp.s1 = fixtureSmp1()
 	c.smp.s3 = fixtureSmp3()
 
-	ret, err := smp4Message{rb: big.NewInt(1)}.receivedMessage(c)
+	ret, err := smp4Message{Rb: big.NewInt(1)}.receivedMessage(c)
 	assertNil(t, err)
 	assertDeepEquals(t, ret, smpMessageAbort{})
 }
@@ -523,7 +525,7 @@ func Test_receive_returnsAnyErrorThatOccurs(t *testing.T) {
 	c := newConversation(otrV3{}, fixedRand([]string{"ABCD"}))
 	c.smp.s1 = fixtureSmp1()
 	c.smp.state = smpStateExpect2{}
-	c.smp.secret = bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3")
+	c.smp.secret = newSecretInt(bnFromHex("ABCDE56321F9A9F8E364607C8C82DECD8E8E6209E2CB952C7E649620F5286FE3"))
 
 	ret, err := c.receiveSMP(m)
 	assertNil(t, err)
@@ -0,0 +1,73 @@
+package otr3
+
+import (
+	"encoding/hex"
+	"time"
+)
+
+// ConversationStatus is a snapshot of the state of a Conversation. It is meant to be used
+// by user interfaces, and can be exported as JSON to be attached to bug reports.
+// It never contains any secret key material.
+type ConversationStatus struct {
+	MessageState    string `json:"msgState"`
+	ProtocolVersion uint16 `json:"protocolVersion"`
+
+	OurInstanceTag   uint32 `json:"ourInstanceTag"`
+	TheirInstanceTag uint32 `json:"theirInstanceTag"`
+
+	AKEState            string `json:"akeState"`
+	SMPState            string `json:"smpState"`
+	SMPQuestionReceived bool   `json:"smpQuestionReceived"`
+	WhitespaceOffer     string `json:"whitespaceOffer"`
+
+	OurKeyID     uint32 `json:"ourKeyID"`
+	TheirKeyID   uint32 `json:"theirKeyID"`
+	OurCounter   uint64 `json:"ourCounter"`
+	TheirCounter uint64 `json:"theirCounter"`
+
+	TheirFingerprint string `json:"theirFingerprint,omitempty"`
+
+	LastSent     time.Time `json:"lastSent"`
+	LastReceived time.Time `json:"lastReceived"`
+}
+
+// Status returns a snapshot of the current state of the conversation
+func (c *Conversation) Status() ConversationStatus {
+	s := ConversationStatus{
+		MessageState:     c.msgState.identityString(),
+		OurInstanceTag:   c.ourInstanceTag,
+		TheirInstanceTag: c.theirInstanceTag,
+		AKEState:         authStateNone{}.identityString(),
+		SMPState:         smpStateExpect1{}.identityString(),
+		WhitespaceOffer:  c.otrOffer(),
+		OurKeyID:         c.keys.ourKeyID,
+		TheirKeyID:       c.keys.theirKeyID,
+		LastSent:         c.heartbeat.lastSent,
+		LastReceived:     c.heartbeat.lastReceived,
+	}
+
+	if c.version != nil {
+		s.ProtocolVersion = c.version.protocolVersion()
+	}
+
+	if c.ake != nil && c.ake.state != nil {
+		s.AKEState = c.ake.state.identityString()
+	}
+
+	if c.smp.state != nil {
+		s.SMPState = c.smp.state.identityString()
+	}
+	s.SMPQuestionReceived = c.smp.question != nil
+
+	if c.keys.ourKeyID > 0 {
+		counter := c.keys.counterHistory.peekCounterFor(c.keys.ourKeyID-1, c.keys.theirKeyID)
+		s.OurCounter = counter.ourCounter
+		s.TheirCounter = counter.theirCounter
+	}
+
+	if c.theirKey != nil {
+		s.TheirFingerprint = hex.EncodeToString(c.theirKey.DefaultFingerprint())
+	}
+
+	return s
+}
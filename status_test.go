@@ -0,0 +1,72 @@
+package otr3
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_Status_returnsDefaultValuesForAnEmptyConversation(t *testing.T) {
+	c := &Conversation{}
+	s := c.Status()
+
+	assertEquals(t, s.MessageState, "PLAINTEXT")
+	assertEquals(t, s.ProtocolVersion, uint16(0))
+	assertEquals(t, s.AKEState, "NONE")
+	assertEquals(t, s.SMPState, "EXPECT1")
+	assertEquals(t, s.SMPQuestionReceived, false)
+	assertEquals(t, s.WhitespaceOffer, "NOT")
+	assertEquals(t, s.TheirFingerprint, "")
+	assertEquals(t, s.OurCounter, uint64(0))
+}
+
+func Test_Status_returnsTheCurrentStateOfTheConversation(t *testing.T) {
+	c := bobContextAfterAKE()
+	c.msgState = encrypted
+	c.whitespaceState = whitespaceSent
+	c.theirInstanceTag = 0x102
+	c.ake.state = authStateAwaitingSig{}
+	c.smp.state = smpStateExpect2{}
+	q := "Blarg"
+	c.smp.question = &q
+	c.theirKey = &bobPrivateKey.PublicKey
+	c.keys.counterHistory.findCounterFor(1, 1).ourCounter = 3
+	c.keys.counterHistory.findCounterFor(1, 1).theirCounter = 5
+
+	s := c.Status()
+
+	assertEquals(t, s.MessageState, "ENCRYPTED")
+	assertEquals(t, s.ProtocolVersion, uint16(3))
+	assertEquals(t, s.OurInstanceTag, uint32(0x101))
+	assertEquals(t, s.TheirInstanceTag, uint32(0x102))
+	assertEquals(t, s.AKEState, "AWAITING_SIG")
+	assertEquals(t, s.SMPState, "EXPECT2")
+	assertEquals(t, s.SMPQuestionReceived, true)
+	assertEquals(t, s.WhitespaceOffer, "ACCEPTED")
+	assertEquals(t, s.OurKeyID, uint32(2))
+	assertEquals(t, s.TheirKeyID, uint32(1))
+	assertEquals(t, s.OurCounter, uint64(3))
+	assertEquals(t, s.TheirCounter, uint64(5))
+	assertEquals(t, s.TheirFingerprint, "8798faa7735267fb8457733098482e94096d4abd")
+}
+
+func Test_Status_doesNotCreateNewCounters(t *testing.T) {
+	c := bobContextAfterAKE()
+	c.Status()
+
+	assertEquals(t, len(c.keys.counterHistory.counters), 0)
+}
+
+func Test_Status_canBeExportedAsJSON(t *testing.T) {
+	c := bobContextAfterAKE()
+	c.theirInstanceTag = 0x102
+
+	b, err := json.Marshal(c.Status())
+	assertNil(t, err)
+
+	var res map[string]interface{}
+	json.Unmarshal(b, &res)
+	assertEquals(t, res["msgState"], "PLAINTEXT")
+	assertEquals(t, res["protocolVersion"], float64(3))
+	assertEquals(t, res["theirInstanceTag"], float64(0x102))
+	assertEquals(t, res["ourKeyID"], float64(2))
+}
@@ -114,12 +114,12 @@ func toSmpMessage1(t tlv) (msg smp1Message, ok bool) {
 	if !ok || len(mpis) < 6 {
 		return msg, false
 	}
-	msg.g2a = mpis[0]
-	msg.c2 = mpis[1]
-	msg.d2 = mpis[2]
-	msg.g3a = mpis[3]
-	msg.c3 = mpis[4]
-	msg.d3 = mpis[5]
+	msg.G2a = mpis[0]
+	msg.C2 = mpis[1]
+	msg.D2 = mpis[2]
+	msg.G3a = mpis[3]
+	msg.C3 = mpis[4]
+	msg.D3 = mpis[5]
 	return msg, true
 }
 
@@ -141,17 +141,17 @@ func toSmpMessage2(t tlv) (msg smp2Message, ok bool) {
 	if !ok || len(mpis) < 11 {
 		return msg, false
 	}
-	msg.g2b = mpis[0]
-	msg.c2 = mpis[1]
-	msg.d2 = mpis[2]
-	msg.g3b = mpis[3]
-	msg.c3 = mpis[4]
-	msg.d3 = mpis[5]
-	msg.pb = mpis[6]
-	msg.qb = mpis[7]
-	msg.cp = mpis[8]
-	msg.d5 = mpis[9]
-	msg.d6 = mpis[10]
+	msg.G2b = mpis[0]
+	msg.C2 = mpis[1]
+	msg.D2 = mpis[2]
+	msg.G3b = mpis[3]
+	msg.C3 = mpis[4]
+	msg.D3 = mpis[5]
+	msg.Pb = mpis[6]
+	msg.Qb = mpis[7]
+	msg.Cp = mpis[8]
+	msg.D5 = mpis[9]
+	msg.D6 = mpis[10]
 	return msg, true
 }
 
@@ -160,14 +160,14 @@ func toSmpMessage3(t tlv) (msg smp3Message, ok bool) {
 	if !ok || len(mpis) < 8 {
 		return msg, false
 	}
-	msg.pa = mpis[0]
-	msg.qa = mpis[1]
-	msg.cp = mpis[2]
-	msg.d5 = mpis[3]
-	msg.d6 = mpis[4]
-	msg.ra = mpis[5]
-	msg.cr = mpis[6]
-	msg.d7 = mpis[7]
+	msg.Pa = mpis[0]
+	msg.Qa = mpis[1]
+	msg.Cp = mpis[2]
+	msg.D5 = mpis[3]
+	msg.D6 = mpis[4]
+	msg.Ra = mpis[5]
+	msg.Cr = mpis[6]
+	msg.D7 = mpis[7]
 	return msg, true
 }
 
@@ -176,9 +176,9 @@ func toSmpMessage4(t tlv) (msg smp4Message, ok bool) {
 	if !ok || len(mpis) < 3 {
 		return msg, false
 	}
-	msg.rb = mpis[0]
-	msg.cr = mpis[1]
-	msg.d7 = mpis[2]
+	msg.Rb = mpis[0]
+	msg.Cr = mpis[1]
+	msg.D7 = mpis[2]
 	return msg, true
 }
 
@@ -0,0 +1,320 @@
+package otr3
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// TranscriptDirection tells whether a transcript entry is for a message we sent or one we received
+type TranscriptDirection int
+
+const (
+	// TranscriptSent is used for the messages given to Send
+	TranscriptSent TranscriptDirection = iota
+	// TranscriptReceived is used for the messages given to Receive
+	TranscriptReceived
+)
+
+// String returns the string representation of the TranscriptDirection
+func (d TranscriptDirection) String() string {
+	switch d {
+	case TranscriptSent:
+		return "sent"
+	case TranscriptReceived:
+		return "received"
+	default:
+		return "TRANSCRIPT DIRECTION: (THIS SHOULD NEVER HAPPEN)"
+	}
+}
+
+// MarshalText returns the direction as text, for use in JSON
+func (d TranscriptDirection) MarshalText() ([]byte, error) {
+	switch d {
+	case TranscriptSent, TranscriptReceived:
+		return []byte(d.String()), nil
+	}
+	return nil, newOtrErrorf("invalid transcript direction %d", int(d))
+}
+
+// UnmarshalText parses the text created by MarshalText
+func (d *TranscriptDirection) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "sent":
+		*d = TranscriptSent
+	case "received":
+		*d = TranscriptReceived
+	default:
+		return newOtrErrorf("invalid transcript direction %q", text)
+	}
+	return nil
+}
+
+// TranscriptEntry is one call to Send or Receive on a Conversation
+type TranscriptEntry struct {
+	Direction TranscriptDirection `json:"direction"`
+	Time      time.Time           `json:"time"`
+	// Message is the argument to Send or Receive
+	Message []byte `json:"message"`
+	// Plain is the plaintext returned by Receive
+	Plain []byte `json:"plain,omitempty"`
+	// Output contains the messages returned to be sent to the peer
+	Output [][]byte `json:"output,omitempty"`
+	Error  string   `json:"error,omitempty"`
+	// Random contains the bytes read from the random source during the call, if randomness is recorded
+	Random []byte `json:"random,omitempty"`
+}
+
+// Transcript is a sequence of calls to Send and Receive on a Conversation.
+// Note that a transcript contains plaintext messages, and if randomness was recorded it has everything needed to decrypt the conversation
+type Transcript struct {
+	Entries []TranscriptEntry `json:"entries"`
+}
+
+// WriteTo writes the transcript as JSON
+func (t *Transcript) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// ReadTranscript reads a transcript written by WriteTo
+func ReadTranscript(r io.Reader) (*Transcript, error) {
+	t := &Transcript{}
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// TranscriptRecorder captures every call to Send and Receive of a Conversation. See SetTranscriptRecorder
+type TranscriptRecorder struct {
+	lock             sync.Mutex
+	recordRandomness bool
+	random           []byte
+	transcript       Transcript
+}
+
+// NewTranscriptRecorder creates a new recorder. If recordRandomness is true, every byte read from the
+// random source of the conversation is recorded as well, which is needed to replay the transcript
+func NewTranscriptRecorder(recordRandomness bool) *TranscriptRecorder {
+	return &TranscriptRecorder{recordRandomness: recordRandomness}
+}
+
+// Transcript returns a copy of what has been recorded so far
+func (r *TranscriptRecorder) Transcript() *Transcript {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	t := &Transcript{Entries: make([]TranscriptEntry, len(r.transcript.Entries))}
+	copy(t.Entries, r.transcript.Entries)
+	return t
+}
+
+func (r *TranscriptRecorder) recordRandom(b []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.random = append(r.random, b...)
+}
+
+func (r *TranscriptRecorder) record(e TranscriptEntry) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	e.Random, r.random = r.random, nil
+	r.transcript.Entries = append(r.transcript.Entries, e)
+}
+
+type recordingReader struct {
+	r        io.Reader
+	recorder *TranscriptRecorder
+}
+
+func (r recordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.recorder.recordRandom(p[:n])
+	return n, err
+}
+
+// SetTranscriptRecorder starts recording the calls to Send and Receive with the given recorder.
+// Randomness used by other calls, like StartAuthenticate or End, is recorded with the next entry,
+// but such calls can't be replayed
+func (c *Conversation) SetTranscriptRecorder(r *TranscriptRecorder) {
+	c.transcript = r
+}
+
+func (c *Conversation) recordTranscript(d TranscriptDirection, at time.Time, m ValidMessage, plain MessagePlaintext, toSend []ValidMessage, err error) {
+	if c.transcript == nil {
+		return
+	}
+
+	e := TranscriptEntry{
+		Direction: d,
+		Time:      at,
+		Message:   makeCopy(m),
+		Output:    copyMessages(toSend),
+	}
+	if plain != nil {
+		e.Plain = makeCopy(plain)
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	c.transcript.record(e)
+}
+
+func copyMessages(msgs []ValidMessage) [][]byte {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ret := make([][]byte, len(msgs))
+	for i, m := range msgs {
+		ret[i] = makeCopy(m)
+	}
+	return ret
+}
+
+// TranscriptMismatch is returned by Replay when the conversation doesn't behave as recorded
+type TranscriptMismatch struct {
+	// Entry is the index of the entry where the difference was found
+	Entry  int
+	Reason string
+}
+
+func (e TranscriptMismatch) Error() string {
+	return fmt.Sprintf("otr: transcript entry %d: %s", e.Entry, e.Reason)
+}
+
+type replayReader struct {
+	data []byte
+}
+
+func (r *replayReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// Replay feeds the entries of the transcript to the conversation, and checks that it returns exactly what was recorded.
+// The conversation must be set up in the same way as the recorded one (keys, policies, handlers) and not have been used.
+// The random source and the clock of the conversation are replaced by the ones recorded in the transcript.
+// Signatures always use fresh randomness, so for AKE messages carrying a signature only the kind of message is compared.
+// Fragments are compared after joining them into the messages they were split from
+func Replay(c *Conversation, t *Transcript) error {
+	random := &replayReader{}
+	c.Rand = random
+
+	var current time.Time
+	c.clock = func() time.Time { return current }
+
+	for i, e := range t.Entries {
+		current = e.Time
+		random.data = e.Random
+
+		var plain MessagePlaintext
+		var toSend []ValidMessage
+		var err error
+
+		switch e.Direction {
+		case TranscriptSent:
+			toSend, err = c.Send(e.Message)
+		case TranscriptReceived:
+			plain, toSend, err = c.Receive(e.Message)
+		default:
+			return TranscriptMismatch{i, "invalid direction"}
+		}
+
+		if reason := compareTranscriptEntry(e, plain, toSend, err); reason != "" {
+			return TranscriptMismatch{i, reason}
+		}
+
+		if len(random.data) != 0 {
+			return TranscriptMismatch{i, "recorded randomness was not used"}
+		}
+	}
+
+	return nil
+}
+
+func compareTranscriptEntry(e TranscriptEntry, plain MessagePlaintext, toSend []ValidMessage, err error) string {
+	errString := ""
+	if err != nil {
+		errString = err.Error()
+	}
+
+	if errString != e.Error {
+		return fmt.Sprintf("expected error %q, got %q", e.Error, errString)
+	}
+
+	if string(plain) != string(e.Plain) {
+		return fmt.Sprintf("expected plaintext %q, got %q", e.Plain, plain)
+	}
+
+	sent, recorded := reassembleFragments(copyMessages(toSend)), reassembleFragments(e.Output)
+	if len(sent) != len(recorded) {
+		return fmt.Sprintf("expected %d messages to send, got %d", len(recorded), len(sent))
+	}
+
+	for i, m := range sent {
+		if sameSignedMessage(m, recorded[i]) {
+			continue
+		}
+
+		if string(m) != string(recorded[i]) {
+			return fmt.Sprintf("message %d to send differs: expected %q, got %q", i, recorded[i], m)
+		}
+	}
+
+	return ""
+}
+
+// reassembleFragments joins consecutive fragments into the messages they were split from, so messages
+// that are fragmented differently can still be compared. Other messages are returned unchanged
+func reassembleFragments(msgs [][]byte) [][]byte {
+	var ret [][]byte
+	var current []byte
+
+	for _, m := range msgs {
+		info, err := Inspect(m)
+		if err != nil || info.Kind != MessageKindFragment {
+			ret = append(ret, m)
+			continue
+		}
+
+		current = append(current, info.Fragment.Data...)
+		if info.Fragment.Index == info.Fragment.Total {
+			ret = append(ret, current)
+			current = nil
+		}
+	}
+
+	if current != nil {
+		ret = append(ret, current)
+	}
+
+	return ret
+}
+
+// sameSignedMessage returns true if both messages are AKE messages of the same kind that carry a signature
+func sameSignedMessage(m, recorded []byte) bool {
+	info, err := Inspect(m)
+	if err != nil || (info.Kind != MessageKindRevealSignature && info.Kind != MessageKindSignature) {
+		return false
+	}
+
+	recordedInfo, err := Inspect(recorded)
+	return err == nil && recordedInfo.Kind == info.Kind
+}
@@ -0,0 +1,174 @@
+package otr3
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func recordConversation(alice *Conversation, bob *Conversation) {
+	performAKE(alice, bob)
+
+	toSend, _ := alice.Send(ValidMessage("hello"))
+	bob.Receive(toSend[0])
+	toSend, _ = bob.Send(ValidMessage("hi alice"))
+	alice.Receive(toSend[0])
+	toSend, _ = bob.End()
+	alice.Receive(toSend[0])
+}
+
+func Test_TranscriptRecorder_recordsSendAndReceive(t *testing.T) {
+	bob := newAKEConversation(bobPrivateKey)
+	recorder := NewTranscriptRecorder(false)
+	bob.SetTranscriptRecorder(recorder)
+
+	before := time.Now()
+	_, toSend, _ := bob.Receive(ValidMessage("hi bob"))
+	toSend, _ = bob.Send(ValidMessage("hello"))
+
+	entries := recorder.Transcript().Entries
+	assertEquals(t, len(entries), 2)
+	assertEquals(t, entries[0].Direction, TranscriptReceived)
+	assertDeepEquals(t, entries[0].Message, []byte("hi bob"))
+	assertDeepEquals(t, entries[0].Plain, []byte("hi bob"))
+	assertEquals(t, entries[0].Time.Before(before), false)
+	assertEquals(t, entries[1].Direction, TranscriptSent)
+	assertDeepEquals(t, entries[1].Message, []byte("hello"))
+	assertDeepEquals(t, entries[1].Output, [][]byte{toSend[0]})
+	assertNil(t, entries[1].Random)
+}
+
+func Test_TranscriptRecorder_recordsTheRandomnessUsed(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	recorder := NewTranscriptRecorder(true)
+	bob.SetTranscriptRecorder(recorder)
+
+	bob.Receive(alice.QueryMessage())
+
+	entries := recorder.Transcript().Entries
+	assertEquals(t, len(entries), 1)
+	assertEquals(t, len(entries[0].Random) > 0, true)
+}
+
+func Test_TranscriptRecorder_recordsErrors(t *testing.T) {
+	bob := newAKEConversation(bobPrivateKey)
+	bob.Policies.add(requireEncryption)
+	recorder := NewTranscriptRecorder(false)
+	bob.SetTranscriptRecorder(recorder)
+
+	bob.Receive(ValidMessage("?OTR:AAMD,"))
+
+	assertEquals(t, recorder.Transcript().Entries[0].Error != "", true)
+}
+
+func Test_Transcript_canBeWrittenAndRead(t *testing.T) {
+	original := &Transcript{Entries: []TranscriptEntry{
+		{Direction: TranscriptSent, Time: time.Date(2015, 11, 9, 12, 0, 0, 0, time.UTC), Message: []byte("hello"), Output: [][]byte{[]byte("?OTR:AAMD")}, Random: []byte{0x01, 0x02}},
+		{Direction: TranscriptReceived, Time: time.Date(2015, 11, 9, 12, 0, 1, 0, time.UTC), Message: []byte("hi"), Plain: []byte("hi"), Error: "otr: failure"},
+	}}
+
+	var buf bytes.Buffer
+	_, err := original.WriteTo(&buf)
+	assertNil(t, err)
+
+	read, err := ReadTranscript(&buf)
+	assertNil(t, err)
+	assertDeepEquals(t, read, original)
+}
+
+func Test_ReadTranscript_rejectsAnInvalidDirection(t *testing.T) {
+	_, err := ReadTranscript(bytes.NewBufferString(`{"entries":[{"direction":"sideways"}]}`))
+
+	assertDeepEquals(t, err, newOtrError(`invalid transcript direction "sideways"`))
+}
+
+func Test_Replay_reproducesARecordedConversation(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	recorder := NewTranscriptRecorder(true)
+	alice.SetTranscriptRecorder(recorder)
+	recordConversation(alice, bob)
+
+	var buf bytes.Buffer
+	recorder.Transcript().WriteTo(&buf)
+	transcript, _ := ReadTranscript(&buf)
+
+	replayed := newAKEConversation(alicePrivateKey)
+	err := Replay(replayed, transcript)
+
+	assertNil(t, err)
+	assertEquals(t, replayed.IsEncrypted(), false)
+	assertEquals(t, replayed.msgState, finished)
+}
+
+func Test_Replay_reportsWhereTheConversationDiffers(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	recorder := NewTranscriptRecorder(true)
+	alice.SetTranscriptRecorder(recorder)
+	recordConversation(alice, bob)
+
+	transcript := recorder.Transcript()
+	transcript.Entries[2].Output[0][10] ^= 0x01
+
+	err := Replay(newAKEConversation(alicePrivateKey), transcript)
+
+	assertEquals(t, err.(TranscriptMismatch).Entry, 2)
+}
+
+func Test_Replay_failsWithoutRecordedRandomness(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	recorder := NewTranscriptRecorder(false)
+	alice.SetTranscriptRecorder(recorder)
+	recordConversation(alice, bob)
+
+	err := Replay(newAKEConversation(alicePrivateKey), recorder.Transcript())
+
+	assertEquals(t, err.(TranscriptMismatch).Entry, 0)
+}
+
+func deliverAll(to *Conversation, msgs []ValidMessage) []ValidMessage {
+	var ret []ValidMessage
+	for _, m := range msgs {
+		_, toSend, _ := to.Receive(m)
+		ret = append(ret, toSend...)
+	}
+	return ret
+}
+
+func Test_Replay_comparesFragmentedSignedMessagesAfterJoiningThem(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	alice.SetFragmentSize(200)
+	bob.SetFragmentSize(200)
+	recorder := NewTranscriptRecorder(true)
+	alice.SetTranscriptRecorder(recorder)
+
+	toSend := deliverAll(bob, []ValidMessage{alice.QueryMessage()})
+	toSend = deliverAll(alice, toSend)
+	toSend = deliverAll(bob, toSend)
+	toSend = deliverAll(alice, toSend)
+	deliverAll(bob, toSend)
+	assertEquals(t, len(toSend) > 1, true)
+	assertEquals(t, bob.IsEncrypted(), true)
+
+	replayed := newAKEConversation(alicePrivateKey)
+	replayed.SetFragmentSize(200)
+	err := Replay(replayed, recorder.Transcript())
+
+	assertNil(t, err)
+	assertEquals(t, replayed.IsEncrypted(), true)
+}
+
+func Test_reassembleFragments_joinsFragmentsAndKeepsOtherMessages(t *testing.T) {
+	msgs := [][]byte{
+		[]byte("?OTR,1,2,?OTR:AAMK,"),
+		[]byte("?OTR,2,2,AAAA.,"),
+		[]byte("hello"),
+	}
+
+	assertDeepEquals(t, reassembleFragments(msgs), [][]byte{[]byte("?OTR:AAMKAAAA."), []byte("hello")})
+}
+
+func Test_TranscriptDirection_String(t *testing.T) {
+	assertEquals(t, TranscriptSent.String(), "sent")
+	assertEquals(t, TranscriptReceived.String(), "received")
+	assertEquals(t, TranscriptDirection(42).String(), "TRANSCRIPT DIRECTION: (THIS SHOULD NEVER HAPPEN)")
+}
@@ -0,0 +1,47 @@
+package otr3
+
+// KnownKeySource gives information about the long-term keys that have been seen before for the peer of a conversation
+type KnownKeySource interface {
+	// KnownFingerprint returns whether the given fingerprint has been seen before for the peer, and whether the user has verified it
+	KnownFingerprint(fingerprint []byte) (known, verified bool)
+	// HasKnownFingerprints returns whether any fingerprint has been seen before for the peer
+	HasKnownFingerprints() bool
+}
+
+type dynamicKnownKeySource struct {
+	known    func(fingerprint []byte) (bool, bool)
+	hasKnown func() bool
+}
+
+func (d dynamicKnownKeySource) KnownFingerprint(fingerprint []byte) (bool, bool) {
+	return d.known(fingerprint)
+}
+
+func (d dynamicKnownKeySource) HasKnownFingerprints() bool {
+	return d.hasKnown()
+}
+
+// SetKnownKeySource assigns the source used to decide the trust level of the peer's key.
+// Without a KnownKeySource no trust related security events will be signaled
+func (c *Conversation) SetKnownKeySource(s KnownKeySource) {
+	c.knownKeySource = s
+}
+
+func (c *Conversation) signalTrustLevel() {
+	if c.knownKeySource == nil || c.theirKey == nil {
+		return
+	}
+
+	known, verified := c.knownKeySource.KnownFingerprint(c.theirKey.DefaultFingerprint())
+	switch {
+	case known && verified:
+		c.securityEvent(SecureVerified)
+	case known:
+		c.securityEvent(SecureUnverified)
+	default:
+		if c.knownKeySource.HasKnownFingerprints() {
+			c.securityEvent(FingerprintChanged)
+		}
+		c.securityEvent(SecureUnverified)
+	}
+}
@@ -0,0 +1,99 @@
+package otr3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func newAKEConversation(key *PrivateKey) *Conversation {
+	c := &Conversation{Rand: rand.Reader}
+	c.ourKey = key
+	c.Policies = policies(allowV2 | allowV3)
+	return c
+}
+
+func performAKE(alice, bob *Conversation) {
+	_, toSend, _ := bob.Receive(alice.QueryMessage())
+	_, toSend, _ = alice.Receive(toSend[0])
+	_, toSend, _ = bob.Receive(toSend[0])
+	_, toSend, _ = alice.Receive(toSend[0])
+	bob.Receive(toSend[0])
+}
+
+func (c *Conversation) collectSecurityEvents() *[]SecurityEvent {
+	events := []SecurityEvent{}
+	c.SetSecurityEventHandler(dynamicSecurityEventHandler{func(e SecurityEvent) {
+		events = append(events, e)
+	}})
+	return &events
+}
+
+func knownKeys(verified bool, fingerprints ...[]byte) KnownKeySource {
+	return dynamicKnownKeySource{
+		func(fp []byte) (bool, bool) {
+			for _, f := range fingerprints {
+				if bytes.Equal(f, fp) {
+					return true, verified
+				}
+			}
+			return false, false
+		},
+		func() bool { return len(fingerprints) > 0 },
+	}
+}
+
+func Test_akeHasFinished_doesNotSignalTrustLevelsWithoutAKnownKeySource(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	events := alice.collectSecurityEvents()
+
+	performAKE(alice, bob)
+
+	assertDeepEquals(t, *events, []SecurityEvent{GoneSecure})
+}
+
+func Test_akeHasFinished_signalsSecureUnverifiedForAnUnknownPeer(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	alice.SetKnownKeySource(knownKeys(false))
+	events := alice.collectSecurityEvents()
+
+	performAKE(alice, bob)
+
+	assertDeepEquals(t, *events, []SecurityEvent{GoneSecure, SecureUnverified})
+}
+
+func Test_akeHasFinished_signalsSecureVerifiedForAVerifiedKey(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	alice.SetKnownKeySource(knownKeys(true, bobPrivateKey.PublicKey.DefaultFingerprint()))
+	events := alice.collectSecurityEvents()
+
+	performAKE(alice, bob)
+
+	assertDeepEquals(t, *events, []SecurityEvent{GoneSecure, SecureVerified})
+}
+
+func Test_akeHasFinished_signalsFingerprintChangedWhenThePeerUsesANewKey(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	alice.SetKnownKeySource(knownKeys(true, alicePrivateKey.PublicKey.DefaultFingerprint()))
+	events := alice.collectSecurityEvents()
+
+	performAKE(alice, bob)
+
+	assertDeepEquals(t, *events, []SecurityEvent{GoneSecure, FingerprintChanged, SecureUnverified})
+}
+
+func Test_akeHasFinished_signalsKeyChangedMidSessionWhenARefreshUsesAnotherKey(t *testing.T) {
+	alice, bob := newAKEConversation(alicePrivateKey), newAKEConversation(bobPrivateKey)
+	performAKE(alice, bob)
+	events := alice.collectSecurityEvents()
+
+	performAKE(alice, bob)
+	assertDeepEquals(t, *events, []SecurityEvent{StillSecure})
+
+	otherKey := &PrivateKey{}
+	otherKey.Generate(rand.Reader)
+	bob.ourKey = otherKey
+	performAKE(alice, bob)
+
+	assertDeepEquals(t, *events, []SecurityEvent{StillSecure, StillSecure, KeyChangedMidSession})
+}
@@ -8,7 +8,7 @@ func (p *dhKeyPair) wipe() {
 	}
 
 	wipeBigInt(p.pub)
-	wipeBigInt(p.priv)
+	p.priv.destroy()
 	p.pub = nil
 	p.priv = nil
 }
@@ -23,12 +23,24 @@ func (k *akeKeys) wipe() {
 	wipeBytes(k.m2[:])
 }
 
+func (k *sessionKeys) wipe() {
+	if k == nil {
+		return
+	}
+
+	wipeBytes(k.sendingAESKey[:])
+	wipeBytes(k.receivingAESKey[:])
+	k.sendingMACKey.wipe()
+	k.receivingMACKey.wipe()
+	wipeBytes(k.extraKey[:])
+}
+
 func (a *ake) wipe(wipeKeys bool) {
 	if a == nil {
 		return
 	}
 
-	wipeBigInt(a.secretExponent)
+	a.secretExponent.destroy()
 	a.secretExponent = nil
 
 	wipeBigInt(a.ourPublicValue)
@@ -92,6 +104,7 @@ func (c *keyManagementContext) wipe() {
 
 	c.counterHistory.wipe()
 	c.macKeyHistory.wipe()
+	c.sessionKeysCache.wipe()
 }
 
 func (c *keyManagementContext) wipeAndKeepRevealKeys() keyManagementContext {
@@ -149,6 +162,28 @@ func (u *macKeyUsage) wipe() {
 	u.receivingKey = macKey{}
 }
 
+func (h *sessionKeysCache) wipe() {
+	if h == nil {
+		return
+	}
+
+	for i := range h.items {
+		h.items[i].wipe()
+		h.items[i] = sessionKeysUsage{}
+	}
+	h.items = nil
+}
+
+func (u *sessionKeysUsage) wipe() {
+	if u == nil {
+		return
+	}
+
+	wipeBigInt(u.ourPubKey)
+	wipeBigInt(u.theirPubKey)
+	u.keys.Destroy()
+}
+
 func (k *macKey) wipe() {
 	if k == nil {
 		return
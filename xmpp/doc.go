@@ -0,0 +1,5 @@
+// Package xmpp maps OTR conversations to XMPP message stanzas. It converts stanza bodies to and from
+// otr3.Conversation Send and Receive, and marks OTR messages with the XEP-0380 explicit message encryption
+// element and the XEP-0334 no-store and no-copy hints, so that servers don't archive them and other
+// resources don't receive copies they can't decrypt.
+package xmpp
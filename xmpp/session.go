@@ -0,0 +1,98 @@
+package xmpp
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/twstrike/otr3"
+)
+
+// ErrErrorStanza is returned when receiving a message of type error
+var ErrErrorStanza = errors.New("xmpp: received an error stanza")
+
+// Session sends and receives the messages of an OTR conversation with one XMPP peer
+type Session struct {
+	Conversation *otr3.Conversation
+	// Local and Remote are the JIDs used as the from and to addresses of the stanzas
+	Local, Remote string
+	// Type is the type of the stanzas sent, "chat" by default
+	Type string
+}
+
+// NewSession returns a session for the conversation between the given JIDs
+func NewSession(c *otr3.Conversation, local, remote string) *Session {
+	return &Session{Conversation: c, Local: local, Remote: remote, Type: "chat"}
+}
+
+// Send returns the stanzas to send for the message typed by the user
+func (s *Session) Send(text string) ([]*Message, error) {
+	toSend, err := s.Conversation.Send(otr3.ValidMessage(text))
+	return s.stanzas(toSend), err
+}
+
+// Receive processes a received stanza. It returns the text to show to the user, if any,
+// and the stanzas to send back to the peer. Stanzas without a body, like chat state notifications, and stanzas
+// that don't come from the bare JID of Remote are ignored
+func (s *Session) Receive(m *Message) (text string, replies []*Message, err error) {
+	if !strings.EqualFold(bareJID(m.From), bareJID(s.Remote)) {
+		return "", nil, nil
+	}
+
+	if m.Type == "error" {
+		return "", nil, ErrErrorStanza
+	}
+
+	if m.Body == nil {
+		return "", nil, nil
+	}
+
+	plain, toSend, err := s.Conversation.Receive(otr3.ValidMessage(*m.Body))
+	return string(plain), s.stanzas(toSend), err
+}
+
+// bareJID removes the resource from a JID
+func bareJID(jid string) string {
+	if i := strings.IndexByte(jid, '/'); i != -1 {
+		return jid[:i]
+	}
+	return jid
+}
+
+// Stanzas returns the stanzas to send for messages created outside of Send, for example by
+// StartAuthenticate or End
+func (s *Session) Stanzas(msgs []otr3.ValidMessage) []*Message {
+	return s.stanzas(msgs)
+}
+
+func (s *Session) stanzas(msgs []otr3.ValidMessage) []*Message {
+	var ret []*Message
+	for _, m := range msgs {
+		ret = append(ret, s.stanza(m))
+	}
+	return ret
+}
+
+func (s *Session) stanza(msg otr3.ValidMessage) *Message {
+	body := string(msg)
+	m := &Message{From: s.Local, To: s.Remote, Type: s.Type, Body: &body}
+	AddHints(m)
+	return m
+}
+
+// AddHints adds the XEP-0380 encryption element and the XEP-0334 no-store and no-copy hints
+// to a message whose body is an OTR protocol message. Plaintext, query and error messages are left alone
+func AddHints(m *Message) {
+	if m.Body == nil {
+		return
+	}
+
+	info, _ := otr3.Inspect([]byte(*m.Body))
+	switch info.Kind {
+	case otr3.MessageKindPlaintext, otr3.MessageKindTaggedPlaintext, otr3.MessageKindQuery, otr3.MessageKindError, otr3.MessageKindUnknown:
+		return
+	}
+
+	m.Encryption = &Encryption{Namespace: NamespaceOTR, Name: "OTR"}
+	m.NoStore = &Hint{}
+	m.NoCopy = &Hint{}
+}
@@ -0,0 +1,156 @@
+package xmpp
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/twstrike/otr3"
+)
+
+func newTestSession(local, remote string) *Session {
+	k := &otr3.PrivateKey{}
+	k.Generate(rand.Reader)
+
+	c := &otr3.Conversation{}
+	c.Policies.AllowV2()
+	c.Policies.AllowV3()
+	c.SetKeys(k, nil)
+	return NewSession(c, local, remote)
+}
+
+// deliver sends the stanzas through their XML encoding, like a server would, and returns the replies
+func deliver(t *testing.T, to *Session, msgs []*Message) (texts []string, replies []*Message) {
+	for _, m := range msgs {
+		data, err := Encode(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received, err := Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, r, err := to.Receive(received)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text != "" {
+			texts = append(texts, text)
+		}
+		replies = append(replies, r...)
+	}
+	return
+}
+
+func exchange(t *testing.T, from, to *Session, msgs []*Message) []string {
+	var texts []string
+	for len(msgs) > 0 {
+		var tx []string
+		tx, msgs = deliver(t, to, msgs)
+		texts = append(texts, tx...)
+		from, to = to, from
+	}
+	return texts
+}
+
+func Test_Session_establishesAnEncryptedConversationAndExchangesMessages(t *testing.T) {
+	alice := newTestSession("alice@example.com/laptop", "bob@example.com/phone")
+	bob := newTestSession("bob@example.com/phone", "alice@example.com/laptop")
+
+	query := alice.Stanzas([]otr3.ValidMessage{alice.Conversation.QueryMessage()})
+	exchange(t, alice, bob, query)
+
+	if !alice.Conversation.IsEncrypted() || !bob.Conversation.IsEncrypted() {
+		t.Fatalf("expected both sides to be encrypted")
+	}
+
+	msgs, err := alice.Send("hello bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("expected one stanza, got %d", len(msgs))
+	}
+
+	m := msgs[0]
+	if m.From != "alice@example.com/laptop" || m.To != "bob@example.com/phone" || m.Type != "chat" {
+		t.Errorf("unexpected addressing: %#v", m)
+	}
+	if !m.IsOTR() || m.NoStore == nil || m.NoCopy == nil {
+		t.Errorf("expected an encrypted message to have the hints")
+	}
+
+	texts := exchange(t, alice, bob, msgs)
+	if len(texts) != 1 || texts[0] != "hello bob" {
+		t.Errorf("unexpected received texts: %v", texts)
+	}
+}
+
+func Test_Session_Send_doesntAddHintsToPlaintext(t *testing.T) {
+	alice := newTestSession("alice@example.com", "bob@example.com")
+
+	msgs, err := alice.Send("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(msgs) != 1 || *msgs[0].Body != "hello" || msgs[0].IsOTR() || msgs[0].NoStore != nil {
+		t.Errorf("unexpected stanzas: %#v", msgs)
+	}
+}
+
+func Test_Session_Receive_ignoresStanzasWithoutBody(t *testing.T) {
+	alice := newTestSession("alice@example.com/laptop", "bob@example.com/phone")
+	m, _ := Decode([]byte(cannedChatStateStanza))
+
+	text, replies, err := alice.Receive(m)
+	if text != "" || replies != nil || err != nil {
+		t.Errorf("expected the stanza to be ignored, got %q %v %v", text, replies, err)
+	}
+}
+
+func Test_Session_Receive_returnsErrorForErrorStanzas(t *testing.T) {
+	alice := newTestSession("alice@example.com/laptop", "bob@example.com/phone")
+	body := "?OTR:AAMD"
+	m := &Message{From: "bob@example.com/phone", Type: "error", Body: &body}
+
+	if _, _, err := alice.Receive(m); err != ErrErrorStanza {
+		t.Errorf("expected ErrErrorStanza, got %v", err)
+	}
+}
+
+func Test_Session_Receive_returnsPlaintext(t *testing.T) {
+	alice := newTestSession("alice@example.com/laptop", "bob@example.com/phone")
+	body := "hi there"
+
+	text, _, err := alice.Receive(&Message{From: "bob@example.com/phone", Type: "chat", Body: &body})
+	if err != nil || text != "hi there" {
+		t.Errorf("unexpected result %q %v", text, err)
+	}
+}
+
+func Test_Session_Receive_acceptsStanzasFromOtherResourcesOfTheRemoteJID(t *testing.T) {
+	alice := newTestSession("alice@example.com/laptop", "bob@example.com/phone")
+	body := "hi there"
+
+	text, _, err := alice.Receive(&Message{From: "Bob@example.com/desktop", Type: "chat", Body: &body})
+	if err != nil || text != "hi there" {
+		t.Errorf("unexpected result %q %v", text, err)
+	}
+}
+
+func Test_Session_Receive_ignoresStanzasFromOtherJIDs(t *testing.T) {
+	alice := newTestSession("alice@example.com/laptop", "bob@example.com/phone")
+	m, _ := Decode([]byte(strings.Replace(cannedOTRStanza, "bob@example.com/phone", "mallory@example.com/phone", 1)))
+
+	text, replies, err := alice.Receive(m)
+	if text != "" || replies != nil || err != nil {
+		t.Errorf("expected the stanza to be ignored, got %q %v %v", text, replies, err)
+	}
+
+	body := "?OTRv3?"
+	text, replies, err = alice.Receive(&Message{From: "mallory@example.com/phone", Type: "chat", Body: &body})
+	if text != "" || replies != nil || err != nil {
+		t.Errorf("expected the query from a third party to be ignored, got %q %v %v", text, replies, err)
+	}
+}
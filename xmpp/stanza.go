@@ -0,0 +1,59 @@
+package xmpp
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+const (
+	// NamespaceClient is the namespace of message stanzas
+	NamespaceClient = "jabber:client"
+	// NamespaceEME is the namespace of XEP-0380 explicit message encryption
+	NamespaceEME = "urn:xmpp:eme:0"
+	// NamespaceHints is the namespace of XEP-0334 message processing hints
+	NamespaceHints = "urn:xmpp:hints"
+	// NamespaceOTR identifies OTR in XEP-0380 encryption elements
+	NamespaceOTR = "urn:xmpp:otr:0"
+)
+
+// Message is an XMPP message stanza, with the elements needed for OTR
+type Message struct {
+	XMLName xml.Name `xml:"jabber:client message"`
+	From    string   `xml:"from,attr,omitempty"`
+	To      string   `xml:"to,attr,omitempty"`
+	ID      string   `xml:"id,attr,omitempty"`
+	Type    string   `xml:"type,attr,omitempty"`
+	Body    *string  `xml:"body"`
+
+	Encryption *Encryption `xml:"urn:xmpp:eme:0 encryption"`
+	NoStore    *Hint       `xml:"urn:xmpp:hints no-store"`
+	NoCopy     *Hint       `xml:"urn:xmpp:hints no-copy"`
+}
+
+// Encryption is the XEP-0380 element that tells which encryption protocol a message uses
+type Encryption struct {
+	Namespace string `xml:"namespace,attr"`
+	Name      string `xml:"name,attr,omitempty"`
+}
+
+// Hint is an empty XEP-0334 hint element
+type Hint struct{}
+
+// IsOTR returns true if the message declares that it is encrypted with OTR
+func (m *Message) IsOTR() bool {
+	return m.Encryption != nil && m.Encryption.Namespace == NamespaceOTR
+}
+
+// Encode returns the XML representation of the message
+func Encode(m *Message) ([]byte, error) {
+	return xml.Marshal(m)
+}
+
+// Decode parses a message stanza
+func Decode(data []byte) (*Message, error) {
+	m := &Message{}
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,99 @@
+package xmpp
+
+import (
+	"strings"
+	"testing"
+)
+
+const cannedOTRStanza = `<message xmlns="jabber:client" from="bob@example.com/phone" to="alice@example.com/laptop" type="chat" id="m1">` +
+	`<body>?OTR:AAMDAAAAAQAAAAIAAAAFAAAAAA==.</body>` +
+	`<encryption xmlns="urn:xmpp:eme:0" namespace="urn:xmpp:otr:0" name="OTR"/>` +
+	`<no-store xmlns="urn:xmpp:hints"/>` +
+	`<no-copy xmlns="urn:xmpp:hints"/>` +
+	`</message>`
+
+const cannedChatStateStanza = `<message xmlns="jabber:client" from="bob@example.com/phone" to="alice@example.com/laptop" type="chat">` +
+	`<composing xmlns="http://jabber.org/protocol/chatstates"/>` +
+	`</message>`
+
+func Test_Decode_parsesAnOTRStanza(t *testing.T) {
+	m, err := Decode([]byte(cannedOTRStanza))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.From != "bob@example.com/phone" || m.To != "alice@example.com/laptop" || m.Type != "chat" || m.ID != "m1" {
+		t.Errorf("unexpected addressing: %#v", m)
+	}
+	if m.Body == nil || *m.Body != "?OTR:AAMDAAAAAQAAAAIAAAAFAAAAAA==." {
+		t.Errorf("unexpected body: %v", m.Body)
+	}
+	if !m.IsOTR() {
+		t.Errorf("expected the message to be marked as OTR")
+	}
+	if m.NoStore == nil || m.NoCopy == nil {
+		t.Errorf("expected the hints to be parsed")
+	}
+}
+
+func Test_Decode_parsesAStanzaWithoutBody(t *testing.T) {
+	m, err := Decode([]byte(cannedChatStateStanza))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Body != nil {
+		t.Errorf("expected no body, got %q", *m.Body)
+	}
+	if m.IsOTR() {
+		t.Errorf("expected the message not to be marked as OTR")
+	}
+}
+
+func Test_Decode_returnsErrorForInvalidXML(t *testing.T) {
+	if _, err := Decode([]byte("<message><body>")); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func Test_Encode_roundTripsThroughDecode(t *testing.T) {
+	m, _ := Decode([]byte(cannedOTRStanza))
+	data, err := Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{`xmlns="urn:xmpp:eme:0"`, `namespace="urn:xmpp:otr:0"`, `no-store xmlns="urn:xmpp:hints"`, `no-copy xmlns="urn:xmpp:hints"`} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("expected %s in %s", s, data)
+		}
+	}
+
+	m2, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *m2.Body != *m.Body || !m2.IsOTR() || m2.From != m.From {
+		t.Errorf("round trip changed the message: %#v", m2)
+	}
+}
+
+func Test_AddHints_leavesPlaintextAlone(t *testing.T) {
+	for _, body := range []string{"hello", "?OTRv3?", "?OTR Error: bad"} {
+		b := body
+		m := &Message{Body: &b}
+		AddHints(m)
+		if m.Encryption != nil || m.NoStore != nil || m.NoCopy != nil {
+			t.Errorf("expected no hints for %q", body)
+		}
+	}
+}
+
+func Test_AddHints_marksFragments(t *testing.T) {
+	b := "?OTR|5a73a599|27e31597,00001,00002,?OTR:AAMDJ+MVmSfjF,"
+	m := &Message{Body: &b}
+	AddHints(m)
+	if !m.IsOTR() || m.NoStore == nil || m.NoCopy == nil {
+		t.Errorf("expected hints for a fragment")
+	}
+}